@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/spf13/cobra"
+)
+
+// accuracyCmd represents the accuracy command
+var accuracyCmd = &cobra.Command{
+	Use:   "accuracy",
+	Short: "Recalculate accuracy statistics",
+	Long:  `Recalculate accuracy statistics for all X01, Around the Clock, Bermuda Triangle and JDC Practice legs`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := data.RecalculateAccuracyStatistics(legID, since, dryRun)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	recalculateStatisticsCmd.AddCommand(accuracyCmd)
+}
@@ -10,7 +10,11 @@ var recalculateGlobalBadgeCmd = &cobra.Command{
 	Use:   "global",
 	Short: "Recalculate Global Badges",
 	Run: func(cmd *cobra.Command, args []string) {
-		err := data.RecalculateGlobalBadges()
+		ids, err := cmd.Flags().GetIntSlice("id")
+		if err != nil {
+			panic(err)
+		}
+		err = data.RecalculateGlobalBadges(ids...)
 		if err != nil {
 			panic(err)
 		}
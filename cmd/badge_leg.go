@@ -10,7 +10,11 @@ var recalculateLegBadgesCmd = &cobra.Command{
 	Use:   "leg",
 	Short: "Recalculate Leg Badges",
 	Run: func(cmd *cobra.Command, args []string) {
-		err := data.RecalculateLegBadges()
+		ids, err := cmd.Flags().GetIntSlice("id")
+		if err != nil {
+			panic(err)
+		}
+		err = data.RecalculateLegBadges(ids...)
 		if err != nil {
 			panic(err)
 		}
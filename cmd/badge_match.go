@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/spf13/cobra"
+)
+
+// recalculateMatchBadgesCmd represents the match command
+var recalculateMatchBadgesCmd = &cobra.Command{
+	Use:   "match",
+	Short: "Recalculate Match Badges",
+	Run: func(cmd *cobra.Command, args []string) {
+		ids, err := cmd.Flags().GetIntSlice("id")
+		if err != nil {
+			panic(err)
+		}
+		err = data.RecalculateMatchBadges(ids...)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	recalculateBadgeCmd.AddCommand(recalculateMatchBadgesCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// decayEloCmd represents the decay command
+var decayEloCmd = &cobra.Command{
+	Use:   "decay",
+	Short: "Decay elo of inactive players",
+	Long: `Decay elo of inactive players toward the mean elo of their office.
+
+	Players who have not played a rated match for 'decay_after_days' days will have
+	their elo moved toward the office mean by 'decay_rate', at most once per period.
+	Players with a provisional rating are not decayed.
+	Intended to be run as a scheduled job, e.g. daily from cron`,
+	Run: func(cmd *cobra.Command, args []string) {
+		configFileParam, err := cmd.Flags().GetString("config")
+		if err != nil {
+			panic(err)
+		}
+		config, err := models.GetConfig(configFileParam)
+		if err != nil {
+			panic(err)
+		}
+		models.InitDB(config.GetMysqlConnectionString())
+		models.EloSettings = &config.EloConfig
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		err = data.DecayElo(dryRun)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	eloCmd.AddCommand(decayEloCmd)
+	decayEloCmd.Flags().Bool("dry-run", true, "Print changes instead of executing")
+}
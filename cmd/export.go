@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <table>",
+	Short: "Export data as CSV or JSON Lines",
+	Long: `Export all rows of the given table as CSV or JSON Lines, optionally filtered by office, player, match type and date range.
+
+Run without a table to print the schema of all tables which can be exported.
+Dates are given as yyyy-MM-dd, from is inclusive and to is exclusive, as for statistics.`,
+	Args: cobra.MaximumNArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			return
+		}
+		configFileParam, err := cmd.Flags().GetString("config")
+		if err != nil {
+			panic(err)
+		}
+		config, err := models.GetConfig(configFileParam)
+		if err != nil {
+			panic(err)
+		}
+		models.InitDB(config.GetMysqlConnectionString())
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			for _, table := range models.ExportTables {
+				fmt.Printf("%s - %s\n", table.Name, table.Description)
+				for _, col := range table.Columns {
+					fmt.Printf("\t%-24s %-10s %s\n", col.Name, col.Type, col.Description)
+				}
+			}
+			return
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		filter := new(models.ExportFilter)
+		if officeID, _ := cmd.Flags().GetInt64("office"); officeID != 0 {
+			filter.OfficeID = null.IntFrom(officeID)
+		}
+		if playerID, _ := cmd.Flags().GetInt64("player"); playerID != 0 {
+			filter.PlayerID = null.IntFrom(playerID)
+		}
+		if matchType, _ := cmd.Flags().GetInt64("match-type"); matchType != 0 {
+			filter.MatchTypeID = null.IntFrom(matchType)
+		}
+		if from, _ := cmd.Flags().GetString("from"); from != "" {
+			filter.From = null.StringFrom(from)
+		}
+		if to, _ := cmd.Flags().GetString("to"); to != "" {
+			filter.To = null.StringFrom(to)
+		}
+
+		out := os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				panic(err)
+			}
+			defer file.Close()
+			out = file
+		}
+		err := data.Export(out, args[0], strings.ToLower(format), filter)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringP("format", "f", models.ExportFormatCSV, "Output format, either csv or jsonl")
+	exportCmd.Flags().StringP("output", "o", "", "File to write to, defaults to stdout")
+	exportCmd.Flags().Int64("office", 0, "Only export rows for the given office id")
+	exportCmd.Flags().Int64("player", 0, "Only export rows for the given player id")
+	exportCmd.Flags().Int64("match-type", 0, "Only export rows for the given match type id")
+	exportCmd.Flags().String("from", "", "Only export rows on or after the given date")
+	exportCmd.Flags().String("to", "", "Only export rows before the given date")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/kcapp/api/data"
 	"github.com/kcapp/api/models"
 	"github.com/spf13/cobra"
 )
@@ -9,7 +10,10 @@ import (
 var recalculateBadgeCmd = &cobra.Command{
 	Use:   "recalculate",
 	Short: "Recalculate badge",
-	Long:  `Recalculate badges earned by each player`,
+	Long: `Recalculate badges earned by each player
+
+Evaluates all badge definitions retroactively against existing legs, matches, players and tournaments.
+Use --id to only evaluate the given badges, for example after adding a new definition`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		configFileParam, err := cmd.Flags().GetString("config")
 		if err != nil {
@@ -20,9 +24,32 @@ var recalculateBadgeCmd = &cobra.Command{
 			panic(err)
 		}
 		models.InitDB(config.GetMysqlConnectionString())
+		models.BadgeDefinitions, err = data.LoadBadgeDefinitions(config.BadgesFile)
+		if err != nil {
+			panic(err)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ids, err := cmd.Flags().GetIntSlice("id")
+		if err != nil {
+			panic(err)
+		}
+		err = data.RecalculateLegBadges(ids...)
+		if err != nil {
+			panic(err)
+		}
+		err = data.RecalculateMatchBadges(ids...)
+		if err != nil {
+			panic(err)
+		}
+		err = data.RecalculateGlobalBadges(ids...)
+		if err != nil {
+			panic(err)
+		}
 	},
 }
 
 func init() {
 	badgeCmd.AddCommand(recalculateBadgeCmd)
+	recalculateBadgeCmd.PersistentFlags().IntSlice("id", []int{}, "Only recalculate the badges with the given IDs")
 }
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/kcapp/api/data"
 	"github.com/kcapp/api/models"
 	"github.com/spf13/cobra"
@@ -13,7 +15,11 @@ var recalculateEloCmd = &cobra.Command{
 	Long: `Recalculate elo for all matches played.
 
 	This will reset the elo for all players, and regenerate the elo changelog
-	Elo will be recalculated based on 'updated_at' timestamp of each match`,
+	Elo will be recalculated based on 'updated_at' timestamp of each match
+	Use '--system glicko' to recalculate Glicko-2 ratings instead, or '--system match_type'
+	to recalculate the Elo for each match type
+	Use '--since <date|match_id>' to restore elo as of the given date (YYYY-MM-DD) or match
+	from the elo changelog, and only replay matches after that point. This is only supported for '--system elo'`,
 	Run: func(cmd *cobra.Command, args []string) {
 		configFileParam, err := cmd.Flags().GetString("config")
 		if err != nil {
@@ -24,14 +30,35 @@ var recalculateEloCmd = &cobra.Command{
 			panic(err)
 		}
 		models.InitDB(config.GetMysqlConnectionString())
+		models.EloSettings = &config.EloConfig
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		tournament, _ := cmd.Flags().GetInt("tournament")
+		system, _ := cmd.Flags().GetString("system")
+		since, _ := cmd.Flags().GetString("since")
+		if since != "" && system != models.RatingSystemElo {
+			panic(fmt.Errorf("--since can not be used with --system %s", system))
+		}
 		if tournament != 0 {
 			err = data.CalculateEloForTournament(tournament)
 			if err != nil {
 				panic(err)
 			}
+		} else if since != "" {
+			err = data.RecalculateEloSince(since, dryRun)
+			if err != nil {
+				panic(err)
+			}
+		} else if system == models.RatingSystemGlicko {
+			err = data.RecalculateGlicko(dryRun)
+			if err != nil {
+				panic(err)
+			}
+		} else if system == "match_type" {
+			err = data.RecalculateMatchTypeElo(dryRun)
+			if err != nil {
+				panic(err)
+			}
 		} else {
 			err = data.RecalculateElo(dryRun)
 			if err != nil {
@@ -45,4 +72,6 @@ func init() {
 	eloCmd.AddCommand(recalculateEloCmd)
 	recalculateEloCmd.Flags().Bool("dry-run", true, "Print queries instead of executing")
 	recalculateEloCmd.Flags().IntP("tournament", "t", 0, "Calculate elo for the given tournament")
+	recalculateEloCmd.Flags().String("since", "", "Only recalculate elo for matches since the given date (YYYY-MM-DD) or match ID")
+	recalculateEloCmd.Flags().StringP("system", "s", models.RatingSystemElo, "Rating system to recalculate (elo, glicko or match_type)")
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/controllers"
 	controllers_v2 "github.com/kcapp/api/controllers/v2"
+	"github.com/kcapp/api/data"
 	"github.com/kcapp/api/models"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +24,11 @@ var serveCmd = &cobra.Command{
 			panic(err)
 		}
 		models.InitDB(config.GetMysqlConnectionString())
+		models.EloSettings = &config.EloConfig
+		models.BadgeDefinitions, err = data.LoadBadgeDefinitions(config.BadgesFile)
+		if err != nil {
+			panic(err)
+		}
 
 		router := mux.NewRouter()
 		router.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -46,14 +52,19 @@ var serveCmd = &cobra.Command{
 		router.HandleFunc("/match/{id}/metadata", controllers.GetMatchMetadata).Methods("GET")
 		router.HandleFunc("/match/{id}/rematch", controllers.ReMatch).Methods("POST")
 		router.HandleFunc("/match/{id}/statistics", controllers.GetStatisticsForMatch).Methods("GET")
+		router.HandleFunc("/match/{id}/statistics/distribution", controllers.GetVisitDistributionForMatch).Methods("GET")
 		router.HandleFunc("/match/{id}/legs", controllers.GetLegsForMatch).Methods("GET")
+		router.HandleFunc("/match/{id}/probabilities/live", controllers.GetMatchLiveProbability).Methods("GET")
 		router.HandleFunc("/match/{start}/{limit}", controllers.GetMatchesLimit).Methods("GET")
 
 		router.HandleFunc("/leg/active", controllers.GetActiveLegs).Methods("GET")
 		router.HandleFunc("/leg/{id}", controllers.GetLeg).Methods("GET")
 		router.HandleFunc("/leg/{id}", controllers.DeleteLeg).Methods("DELETE")
 		router.HandleFunc("/leg/{id}/statistics", controllers.GetStatisticsForLeg).Methods("GET")
+		router.HandleFunc("/leg/{id}/statistics/distribution", controllers.GetVisitDistributionForLeg).Methods("GET")
+		router.HandleFunc("/leg/{id}/statistics/accuracy", controllers.GetAccuracyStatisticsForLeg).Methods("GET")
 		router.HandleFunc("/leg/{id}/players", controllers.GetLegPlayers).Methods("GET")
+		router.HandleFunc("/leg/{id}/probabilities", controllers.GetLegLiveProbabilities).Methods("GET")
 		router.HandleFunc("/leg/{id}/order", controllers.ChangePlayerOrder).Methods("PUT")
 		router.HandleFunc("/leg/{id}/warmup", controllers.StartWarmup).Methods("PUT")
 		router.HandleFunc("/leg/{id}/undo", controllers.UndoFinishLeg).Methods("PUT")
@@ -65,17 +76,26 @@ var serveCmd = &cobra.Command{
 
 		router.HandleFunc("/player", controllers.GetPlayers).Methods("GET")
 		router.HandleFunc("/player/active", controllers.GetActivePlayers).Methods("GET")
-		router.HandleFunc("/player/compare", controllers.GetPlayersX01Statistics).Methods("GET")
+		router.HandleFunc("/player/compare", controllers.ComparePlayers).Methods("GET")
+		router.HandleFunc("/player/matchmaking", controllers.GetMatchmaking).Methods("POST")
+		router.HandleFunc("/player/simulate", controllers.SimulateRatings).Methods("PUT")
 		router.HandleFunc("/player/{id}", controllers.GetPlayer).Methods("GET")
 		router.HandleFunc("/player/{id}", controllers.UpdatePlayer).Methods("PUT")
 		router.HandleFunc("/player/{id}/statistics", controllers.GetPlayerStatistics).Methods("GET")
 		router.HandleFunc("/player/{id}/hits", controllers.GetPlayerHits).Methods("PUT")
 		router.HandleFunc("/player/{id}/statistics/previous", controllers.GetPlayerX01PreviousStatistics).Methods("GET")
+		router.HandleFunc("/player/{id}/statistics/clutch", controllers.GetPlayerClutchStatistics).Methods("GET")
+		router.HandleFunc("/player/{id}/statistics/accuracy", controllers.GetPlayerAccuracyStatistics).Methods("GET")
+		router.HandleFunc("/player/{id}/statistics/distribution/{match_type}", controllers.GetPlayerVisitDistribution).Methods("GET")
 		router.HandleFunc("/player/{id}/progression", controllers.GetPlayerProgression).Methods("GET")
 		router.HandleFunc("/player/{id}/checkouts", controllers.GetPlayerCheckouts).Methods("GET")
 		router.HandleFunc("/player/{id}/tournament", controllers.GetPlayerTournamentStandings).Methods("GET")
+		router.HandleFunc("/player/{id}/divisions", controllers.GetPlayerDivisionHistory).Methods("GET")
 		router.HandleFunc("/player/{id}/badges", controllers.GetPlayerBadges).Methods("GET")
+		router.HandleFunc("/player/{id}/elo", controllers.GetPlayerEloBreakdown).Methods("GET")
 		router.HandleFunc("/player/{id}/elo/{start}/{limit}", controllers.GetPlayerEloChangelog).Methods("GET")
+		router.HandleFunc("/player/{id}/elo/type/{match_type}/{start}/{limit}", controllers.GetPlayerMatchTypeEloChangelog).Methods("GET")
+		router.HandleFunc("/player/{id}/glicko/{start}/{limit}", controllers.GetPlayerGlickoChangelog).Methods("GET")
 		router.HandleFunc("/player/{player_1}/vs/{player_2}", controllers.GetPlayerHeadToHead).Methods("GET")
 		router.HandleFunc("/player/{player_1}/vs/{player_2}/simulate", controllers.SimulateMatch).Methods("PUT")
 		router.HandleFunc("/player", controllers.AddPlayer).Methods("POST")
@@ -95,10 +115,37 @@ var serveCmd = &cobra.Command{
 
 		router.HandleFunc("/statistics/global", controllers.GetGlobalStatistics).Methods("GET")
 		router.HandleFunc("/statistics/global/fnc", controllers.GetGlobalStatisticsFnc).Methods("GET")
+		router.HandleFunc("/statistics/global/{from}/{to}", controllers.GetGlobalStatisticsForPeriod).Methods("GET")
 		router.HandleFunc("/statistics/office/{from}/{to}", controllers.GetOfficeStatistics).Methods("GET")
 		router.HandleFunc("/statistics/office/{office_id}/{from}/{to}", controllers.GetOfficeStatistics).Methods("GET")
 		router.HandleFunc("/statistics/{dart}/hits", controllers.GetDartStatistics).Methods("GET")
 		router.HandleFunc("/statistics/{match_type}/{from}/{to}", controllers.GetStatistics).Methods("GET")
+		router.HandleFunc("/statistics/{match_type}/{from}/{to}/distribution", controllers.GetVisitDistribution).Methods("GET")
+
+		router.HandleFunc("/export", controllers.GetExportTables).Methods("GET")
+		router.HandleFunc("/export/{table}", controllers.Export).Methods("GET")
+
+		router.HandleFunc("/season", controllers.NewSeason).Methods("POST")
+		router.HandleFunc("/season", controllers.GetSeasons).Methods("GET")
+		router.HandleFunc("/season/{id}", controllers.GetSeason).Methods("GET")
+		router.HandleFunc("/season/{id}/finish", controllers.FinishSeason).Methods("POST")
+
+		router.HandleFunc("/teamleague", controllers.NewTeamLeague).Methods("POST")
+		router.HandleFunc("/teamleague", controllers.GetTeamLeagues).Methods("GET")
+		router.HandleFunc("/teamleague/fixture/{id}", controllers.GetTeamFixture).Methods("GET")
+		router.HandleFunc("/teamleague/fixture/{id}/lineup", controllers.SubmitTeamLineup).Methods("POST")
+		router.HandleFunc("/teamleague/{id}", controllers.GetTeamLeague).Methods("GET")
+		router.HandleFunc("/teamleague/{id}/fixtures", controllers.GetTeamFixtures).Methods("GET")
+		router.HandleFunc("/teamleague/{id}/table", controllers.GetTeamLeagueStandings).Methods("GET")
+		router.HandleFunc("/teamleague/{id}/appearances", controllers.GetTeamLeagueAppearances).Methods("GET")
+
+		router.HandleFunc("/registration", controllers.NewTournamentRegistration).Methods("POST")
+		router.HandleFunc("/registration", controllers.GetTournamentRegistrations).Methods("GET")
+		router.HandleFunc("/registration/{id}", controllers.GetTournamentRegistration).Methods("GET")
+		router.HandleFunc("/registration/{id}/player", controllers.RegisterPlayer).Methods("POST")
+		router.HandleFunc("/registration/{id}/player/{player_id}", controllers.WithdrawRegisteredPlayer).Methods("DELETE")
+		router.HandleFunc("/registration/{id}/player/{player_id}/checkin", controllers.CheckInPlayer).Methods("POST")
+		router.HandleFunc("/registration/{id}/start", controllers.StartRegisteredTournament).Methods("POST")
 
 		router.HandleFunc("/owe", controllers.GetOwes).Methods("GET")
 		router.HandleFunc("/owe/payback", controllers.RegisterPayback).Methods("PUT")
@@ -116,11 +163,16 @@ var serveCmd = &cobra.Command{
 		router.HandleFunc("/venue/{id}/config", controllers.GetVenueConfiguration).Methods("GET")
 		router.HandleFunc("/venue/{id}/spectate", controllers.SpectateVenue).Methods("GET")
 		router.HandleFunc("/venue/{id}/players", controllers.GetRecentPlayers).Methods("GET")
+		router.HandleFunc("/venue/{id}/matchmaking", controllers.GetVenueMatchmaking).Methods("GET")
 		router.HandleFunc("/venue/{id}/matches", controllers.GetActiveVenueMatches).Methods("GET")
+		router.HandleFunc("/venue/{id}/schedule", controllers.GetVenueSchedule).Methods("GET")
+		router.HandleFunc("/venue/{id}/calendar", controllers.GetVenueCalendar).Methods("GET")
 
 		router.HandleFunc("/tournament", controllers.NewTournament).Methods("POST")
 		router.HandleFunc("/tournament/generate", controllers.GenerateTournament).Methods("POST")
 		router.HandleFunc("/tournament/generate/playoffs/{id}", controllers.GeneratePlayoffsTournament).Methods("POST")
+		router.HandleFunc("/tournament/generate/bracket/{id}", controllers.GenerateBracketTournament).Methods("POST")
+		router.HandleFunc("/tournament/generate/swiss", controllers.GenerateSwissTournament).Methods("POST")
 		router.HandleFunc("/tournament", controllers.GetTournaments).Methods("GET")
 		router.HandleFunc("/tournament/current", controllers.GetCurrentTournament).Methods("GET")
 		router.HandleFunc("/tournament/current/{office_id}", controllers.GetCurrentTournamentForOffice).Methods("GET")
@@ -128,18 +180,30 @@ var serveCmd = &cobra.Command{
 		router.HandleFunc("/tournament/groups", controllers.AddTournamentGroup).Methods("POST")
 		router.HandleFunc("/tournament/groups", controllers.GetTournamentGroups).Methods("GET")
 		router.HandleFunc("/tournament/standings", controllers.GetTournamentStandings).Methods("GET")
+		router.HandleFunc("/tournament/preset", controllers.AddTournamentPreset).Methods("POST")
 		router.HandleFunc("/tournament/preset", controllers.GetTournamentPresets).Methods("GET")
 		router.HandleFunc("/tournament/preset/{id}", controllers.GetTournamentPreset).Methods("GET")
+		router.HandleFunc("/tournament/preset/{id}", controllers.UpdateTournamentPreset).Methods("PUT")
+		router.HandleFunc("/tournament/preset/{id}", controllers.DeleteTournamentPreset).Methods("DELETE")
+		router.HandleFunc("/tournament/preset/{id}/clone", controllers.CloneTournamentPreset).Methods("POST")
 		router.HandleFunc("/tournament/{id}", controllers.GetTournament).Methods("GET")
 		router.HandleFunc("/tournament/{id}/player", controllers.AddPlayerToTournament).Methods("POST")
 		router.HandleFunc("/tournament/{id}/player/{player_id}", controllers.GetTournamentPlayerMatches).Methods("GET")
+		router.HandleFunc("/tournament/{id}/player/{player_id}/withdraw", controllers.WithdrawTournamentPlayer).Methods("POST")
 		router.HandleFunc("/tournament/{id}/matches", controllers.GetTournamentMatches).Methods("GET")
 		router.HandleFunc("/tournament/{id}/matches/result", controllers.GetTournamentMatchResults).Methods("GET")
 		router.HandleFunc("/tournament/{id}/metadata", controllers.GetMatchMetadataForTournament).Methods("GET")
 		router.HandleFunc("/tournament/{id}/overview", controllers.GetTournamentOverview).Methods("GET")
 		router.HandleFunc("/tournament/{id}/statistics", controllers.GetTournamentStatistics).Methods("GET")
+		router.HandleFunc("/tournament/{id}/swiss", controllers.GetSwissTournament).Methods("GET")
+		router.HandleFunc("/tournament/{id}/swiss/next", controllers.GenerateNextSwissRound).Methods("POST")
+		router.HandleFunc("/tournament/{id}/schedule", controllers.GenerateTournamentSchedule).Methods("POST")
+		router.HandleFunc("/tournament/{id}/schedule", controllers.GetTournamentSchedule).Methods("GET")
+		router.HandleFunc("/tournament/{id}/schedule/{match_id}", controllers.RescheduleTournamentMatch).Methods("PUT")
+		router.HandleFunc("/tournament/{id}/calendar", controllers.GetTournamentCalendar).Methods("GET")
 		router.HandleFunc("/tournament/match/{id}/next", controllers.GetNextTournamentMatch).Methods("GET")
 		router.HandleFunc("/tournament/{id}/probabilities", controllers.GetTournamentProbabilities).Methods("GET")
+		router.HandleFunc("/tournament/{id}/simulation", controllers.SimulateTournament).Methods("GET")
 		router.HandleFunc("/tournament/match/{id}/probabilities", controllers.GetMatchProbabilities).Methods("GET")
 
 		router.HandleFunc("/badge", controllers.GetBadges).Methods("GET")
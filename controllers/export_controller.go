@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/guregu/null"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// GetExportTables will return the schema of all tables which can be exported
+func GetExportTables(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	json.NewEncoder(w).Encode(models.ExportTables)
+}
+
+// Export will stream all rows of the given table, filtered by the given query parameters
+func Export(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	table := models.GetExportTable(params["table"])
+	if table == nil {
+		log.Println("Invalid table parameter")
+		http.Error(w, "Unknown export table: "+params["table"], http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := new(models.ExportFilter)
+	for param, value := range map[string]*null.Int{"office_id": &filter.OfficeID, "player_id": &filter.PlayerID, "match_type": &filter.MatchTypeID} {
+		if query.Get(param) == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(query.Get(param), 10, 64)
+		if err != nil {
+			log.Printf("Invalid %s parameter", param)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*value = null.IntFrom(id)
+	}
+	if query.Get("from") != "" {
+		filter.From = null.StringFrom(query.Get("from"))
+	}
+	if query.Get("to") != "" {
+		filter.To = null.StringFrom(query.Get("to"))
+	}
+	err := filter.Validate()
+	if err != nil {
+		log.Println("Invalid filter", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = models.ExportFormatCSV
+	}
+	switch format {
+	case models.ExportFormatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+	case models.ExportFormatJSONL:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	default:
+		log.Println("Invalid format parameter")
+		http.Error(w, "Unsupported export format: "+format, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+table.Name+"."+format+"\"")
+
+	err = data.Export(w, table.Name, format, filter)
+	if err != nil {
+		// Headers and possibly some rows are already written, so we can only log the error here
+		log.Println("Unable to export", table.Name, err)
+		return
+	}
+}
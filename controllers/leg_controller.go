@@ -235,6 +235,44 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetVisitDistributionForLeg will return visit score distribution for all players in the given leg
+func GetVisitDistributionForLeg(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	legID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stats, err := data.GetVisitDistributionForLeg(legID)
+	if err != nil {
+		log.Println("Unable to get visit distribution for leg", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetAccuracyStatisticsForLeg will return accuracy statistics for all players in the given leg
+func GetAccuracyStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	legID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stats, err := data.CalculateAccuracyStatistics(legID)
+	if err != nil {
+		log.Println("Unable to get accuracy statistics for leg", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
 // ChangePlayerOrder will modify the order of players for the given leg
 func ChangePlayerOrder(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
@@ -332,3 +370,22 @@ func UndoFinishLeg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// GetLegLiveProbabilities will return the live win probabilities after each visit of the given leg
+func GetLegLiveProbabilities(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	probabilities, err := data.GetLegLiveProbabilities(id)
+	if err != nil {
+		log.Println("Unable to get live leg probabilities", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(probabilities)
+}
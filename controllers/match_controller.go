@@ -107,6 +107,7 @@ func GetActiveMatches(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(matches)
 }
 
+// GetMatchProbabilities will return winning probabilities for the given match, using the rating system given by the 'rating' parameter
 func GetMatchProbabilities(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
 	params := mux.Vars(r)
@@ -116,7 +117,16 @@ func GetMatchProbabilities(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	prob, err := data.GetMatchProbabilities(id)
+	system := r.URL.Query().Get("rating")
+	if system == "" {
+		system = models.RatingSystemElo
+	}
+	if !models.IsValidRatingSystem(system) {
+		log.Println("Invalid rating parameter")
+		http.Error(w, "Unknown rating system "+system, http.StatusBadRequest)
+		return
+	}
+	prob, err := data.GetMatchProbabilities(id, system)
 	if err != nil {
 		log.Println("Unable to get match probabilities", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -125,6 +135,25 @@ func GetMatchProbabilities(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(prob)
 }
 
+// GetMatchLiveProbability will return the live win probabilities for the current leg of the given match
+func GetMatchLiveProbability(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prob, err := data.GetMatchLiveProbability(id)
+	if err != nil {
+		log.Println("Unable to get live match probability", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(prob)
+}
+
 // GetMatchesLimit will return N matches from the given starting point
 func GetMatchesLimit(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
@@ -383,6 +412,25 @@ func GetStatisticsForMatch(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetVisitDistributionForMatch will return visit score distribution for all players in the given match
+func GetVisitDistributionForMatch(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	matchID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stats, err := data.GetVisitDistributionForMatch(matchID)
+	if err != nil {
+		log.Printf("Unable to get visit distribution for match %d: %s", matchID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
 // GetMatchesModes will return all match modes
 func GetMatchesModes(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
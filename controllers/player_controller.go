@@ -90,6 +90,93 @@ func GetPlayerEloChangelog(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(changelog)
 }
 
+// GetPlayerEloBreakdown will return all ratings for the given player, including the Elo for each match type
+func GetPlayerEloBreakdown(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	breakdown, err := data.GetPlayerEloBreakdown(id)
+	if err != nil {
+		log.Println("Unable to get player elo breakdown", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+// GetPlayerMatchTypeEloChangelog will return the elo changelog for the given player and match type
+func GetPlayerMatchTypeEloChangelog(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matchType, err := strconv.Atoi(params["match_type"])
+	if err != nil {
+		log.Println("Invalid match_type parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start, err := strconv.Atoi(params["start"])
+	if err != nil {
+		log.Println("Invalid start parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(params["limit"])
+	if err != nil {
+		log.Println("Invalid limit parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	changelog, err := data.GetPlayerMatchTypeEloChangelog(id, matchType, start, limit)
+	if err != nil {
+		log.Println("Unable to get player match type elo changelog", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(changelog)
+}
+
+// GetPlayerGlickoChangelog will return the Glicko-2 changelog for the given player
+func GetPlayerGlickoChangelog(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start, err := strconv.Atoi(params["start"])
+	if err != nil {
+		log.Println("Invalid start parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(params["limit"])
+	if err != nil {
+		log.Println("Invalid limit parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	changelog, err := data.GetPlayerGlickoChangelog(id, start, limit)
+	if err != nil {
+		log.Println("Unable to get player glicko changelog", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(changelog)
+}
+
 // GetPlayerX01Statistics will return statistics for the given player
 func GetPlayerX01Statistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
@@ -144,6 +231,88 @@ func GetPlayerStatistics(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(statistics)
 }
 
+// GetPlayerClutchStatistics will return clutch statistics for the given player
+func GetPlayerClutchStatistics(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stats, err := data.GetPlayerClutchStatistics(id)
+	if err != nil {
+		log.Println("Unable to get player clutch statistics", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetPlayerAccuracyStatistics will return accuracy statistics for the given player
+func GetPlayerAccuracyStatistics(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stats, err := data.GetPlayerAccuracyStatistics(id)
+	if err != nil {
+		log.Println("Unable to get player accuracy statistics", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetPlayerDivisionHistory will return the division the given player played in for each season
+func GetPlayerDivisionHistory(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	history, err := data.GetPlayerDivisionHistory(id)
+	if err != nil {
+		log.Println("Unable to get player division history", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetPlayerVisitDistribution will return visit score distribution for the given player and match type
+func GetPlayerVisitDistribution(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matchType, err := strconv.Atoi(params["match_type"])
+	if err != nil || (matchType != models.X01 && matchType != models.SHOOTOUT) {
+		log.Println("Invalid match type parameter")
+		http.Error(w, "Visit distribution is only available for X01 and Shootout", http.StatusBadRequest)
+		return
+	}
+	stats, err := data.GetVisitDistributionForPlayer(id, matchType)
+	if err != nil {
+		log.Println("Unable to get player visit distribution", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
 // GetPlayerHits will return dart hits for the given player
 func GetPlayerHits(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
@@ -193,7 +362,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 
 	switch matchType {
 	case models.X01:
-		stats, err := data.GetX01StatisticsForPlayer(id, models.X01)
+		stats, err := data.GetX01StatisticsForPlayer(id, models.X01, nil)
 		if err != nil {
 			log.Println("Unable to get X01 statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -203,7 +372,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.SHOOTOUT:
-		stats, err := data.GetShootoutStatisticsForPlayer(id)
+		stats, err := data.GetShootoutStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Cricket statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -213,7 +382,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.X01HANDICAP:
-		stats, err := data.GetX01StatisticsForPlayer(id, models.X01HANDICAP)
+		stats, err := data.GetX01StatisticsForPlayer(id, models.X01HANDICAP, nil)
 		if err != nil {
 			log.Println("Unable to get X01 handicap statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -223,7 +392,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.CRICKET:
-		stats, err := data.GetCricketStatisticsForPlayer(id)
+		stats, err := data.GetCricketStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Cricket statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -233,7 +402,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.DARTSATX:
-		stats, err := data.GetDartsAtXStatisticsForPlayer(id)
+		stats, err := data.GetDartsAtXStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Darts at X statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -243,7 +412,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.AROUNDTHEWORLD:
-		stats, err := data.GetAroundTheWorldStatisticsForPlayer(id)
+		stats, err := data.GetAroundTheWorldStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Around The World Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -253,7 +422,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.SHANGHAI:
-		stats, err := data.GetShanghaiStatisticsForPlayer(id)
+		stats, err := data.GetShanghaiStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Shanghai Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -263,7 +432,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.AROUNDTHECLOCK:
-		stats, err := data.GetAroundTheClockStatisticsForPlayer(id)
+		stats, err := data.GetAroundTheClockStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Around the Clock Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -273,7 +442,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.TICTACTOE:
-		stats, err := data.GetTicTacToeStatisticsForPlayer(id)
+		stats, err := data.GetTicTacToeStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Tic Tac Toe Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -283,7 +452,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.BERMUDATRIANGLE:
-		stats, err := data.GetBermudaTriangleStatisticsForPlayer(id)
+		stats, err := data.GetBermudaTriangleStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Bermuda Triangle Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -293,7 +462,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.FOURTWENTY:
-		stats, err := data.Get420StatisticsForPlayer(id)
+		stats, err := data.Get420StatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get 420 Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -303,7 +472,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.KILLBULL:
-		stats, err := data.GetKillBullStatisticsForPlayer(id)
+		stats, err := data.GetKillBullStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Kill Bull Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -313,7 +482,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.GOTCHA:
-		stats, err := data.GetGotchaStatisticsForPlayer(id)
+		stats, err := data.GetGotchaStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Gotcha Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -323,7 +492,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.JDCPRACTICE:
-		stats, err := data.GetJDCPracticeStatisticsForPlayer(id)
+		stats, err := data.GetJDCPracticeStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get JDC Practice Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -333,7 +502,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.KNOCKOUT:
-		stats, err := data.GetKnockoutStatisticsForPlayer(id)
+		stats, err := data.GetKnockoutStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Knockout Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -343,7 +512,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case models.SCAM:
-		stats, err := data.GetScamStatisticsForPlayer(id)
+		stats, err := data.GetScamStatisticsForPlayer(id, nil)
 		if err != nil {
 			log.Println("Unable to get Scam Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -570,8 +739,8 @@ func GetPlayerX01PreviousStatistics(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
-// GetPlayersX01Statistics will return statistics for the given players
-func GetPlayersX01Statistics(w http.ResponseWriter, r *http.Request) {
+// ComparePlayers will return X01 statistics for the given players, or compare them across all match types if all is set
+func ComparePlayers(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
 	params := r.URL.Query()["id"]
 	if params == nil {
@@ -585,13 +754,24 @@ func GetPlayersX01Statistics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := data.GetPlayersX01Statistics(ids)
+	if r.URL.Query().Get("all") != "true" {
+		stats, err := data.GetPlayersX01Statistics(ids)
+		if err != nil {
+			log.Println("Unable to get players statistics")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+	}
+	versus := r.URL.Query().Get("versus") == "true"
+	comparison, err := data.GetPlayersComparison(ids, versus)
 	if err != nil {
-		log.Println("Unable to get players statistics")
+		log.Println("Unable to compare players", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	json.NewEncoder(w).Encode(stats)
+	json.NewEncoder(w).Encode(comparison)
 }
 
 // AddPlayer will create a new player
@@ -612,6 +792,31 @@ func AddPlayer(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetMatchmaking will return suggested pairings and opponents for the given players
+func GetMatchmaking(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	var input models.MatchmakingInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = input.Validate()
+	if err != nil {
+		log.Println("Invalid matchmaking input", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matchmaking, err := data.GetMatchmaking(&input)
+	if err != nil {
+		log.Println("Unable to get matchmaking suggestions", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(matchmaking)
+}
+
 // UpdatePlayer will update the given player
 func UpdatePlayer(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
@@ -796,6 +1001,31 @@ func SimulateMatch(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(output)
 }
 
+// SimulateRatings will return projected ratings and rankings after a batch of hypothetical results
+func SimulateRatings(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	var input models.RatingSimulationInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = input.Validate()
+	if err != nil {
+		log.Println("Invalid rating simulation", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	simulation, err := data.SimulateRatings(&input)
+	if err != nil {
+		log.Println("Unable to simulate ratings", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(simulation)
+}
+
 // GetPlayerCalendar will return a calendar feed for all official matches for the given player
 func GetPlayerCalendar(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
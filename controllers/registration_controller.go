@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/guregu/null"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// NewTournamentRegistration will open registration for a new tournament
+func NewTournamentRegistration(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	var registration models.TournamentRegistration
+	err := json.NewDecoder(r.Body).Decode(&registration)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = registration.Validate()
+	if err != nil {
+		log.Println("Invalid registration", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := data.NewTournamentRegistration(registration)
+	if err != nil {
+		log.Println("Unable to create registration", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetTournamentRegistrations will return all tournament registrations
+func GetTournamentRegistrations(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	registrations, err := data.GetTournamentRegistrations()
+	if err != nil {
+		log.Println("Unable to get registrations", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(registrations)
+}
+
+// GetTournamentRegistration will return the registration with the given ID
+func GetTournamentRegistration(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	registration, err := data.GetTournamentRegistration(id)
+	if err != nil {
+		log.Println("Unable to get registration", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(registration)
+}
+
+// RegisterPlayer will register a player for the given registration
+func RegisterPlayer(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		PlayerID          int      `json:"player_id"`
+		TournamentGroupID null.Int `json:"tournament_group_id"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	registration, err := data.RegisterPlayer(id, body.PlayerID, body.TournamentGroupID)
+	if err != nil {
+		log.Println("Unable to register player", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(registration)
+}
+
+// CheckInPlayer will check in a registered player
+func CheckInPlayer(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	playerID, err := strconv.Atoi(params["player_id"])
+	if err != nil {
+		log.Println("Invalid player_id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	registration, err := data.CheckInPlayer(id, playerID)
+	if err != nil {
+		log.Println("Unable to check in player", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(registration)
+}
+
+// WithdrawRegisteredPlayer will withdraw a player before the tournament starts
+func WithdrawRegisteredPlayer(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	playerID, err := strconv.Atoi(params["player_id"])
+	if err != nil {
+		log.Println("Invalid player_id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	registration, err := data.WithdrawRegisteredPlayer(id, playerID)
+	if err != nil {
+		log.Println("Unable to withdraw player", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(registration)
+}
+
+// StartRegisteredTournament will close registration and generate the tournament with all checked in players
+func StartRegisteredTournament(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tournament, err := data.StartRegisteredTournament(id)
+	if err != nil {
+		log.Println("Unable to start tournament", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(tournament)
+}
+
+// WithdrawTournamentPlayer will withdraw a player from a tournament which has started
+func WithdrawTournamentPlayer(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	playerID, err := strconv.Atoi(params["player_id"])
+	if err != nil {
+		log.Println("Invalid player_id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := data.WithdrawPlayer(id, playerID)
+	if err != nil {
+		log.Println("Unable to withdraw player", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jordic/goics"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// GenerateTournamentSchedule will assign all unplayed matches of the given tournament to venues and time slots
+func GenerateTournamentSchedule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var options models.ScheduleOptions
+	err = json.NewDecoder(r.Body).Decode(&options)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = options.Validate()
+	if err != nil {
+		log.Println("Invalid schedule options", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule, err := data.GenerateTournamentSchedule(id, &options)
+	if err != nil {
+		log.Println("Unable to generate tournament schedule", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// GetTournamentSchedule will return the schedule for the given tournament
+func GetTournamentSchedule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule, err := data.GetTournamentSchedule(id)
+	if err != nil {
+		log.Println("Unable to get tournament schedule", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// RescheduleTournamentMatch will manually move a match to a new venue and time slot
+func RescheduleTournamentMatch(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matchID, err := strconv.Atoi(params["match_id"])
+	if err != nil {
+		log.Println("Invalid match_id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var input models.ScheduledMatch
+	err = json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule, err := data.RescheduleMatch(id, matchID, input)
+	if err != nil {
+		log.Println("Unable to reschedule match", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// GetTournamentCalendar will return a calendar feed for all scheduled matches in the given tournament
+func GetTournamentCalendar(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule, err := data.GetTournamentSchedule(id)
+	if err != nil {
+		log.Println("Unable to get tournament schedule", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeScheduleCalendar(w, schedule)
+}
+
+// GetVenueSchedule will return all scheduled matches for the given venue
+func GetVenueSchedule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule, err := data.GetVenueSchedule(id)
+	if err != nil {
+		log.Println("Unable to get venue schedule", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// GetVenueCalendar will return a calendar feed for all scheduled matches at the given venue
+func GetVenueCalendar(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schedule, err := data.GetVenueSchedule(id)
+	if err != nil {
+		log.Println("Unable to get venue schedule", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeScheduleCalendar(w, schedule)
+}
+
+// writeScheduleCalendar will write the given schedule as an iCal feed
+func writeScheduleCalendar(w http.ResponseWriter, schedule []*models.ScheduledMatch) {
+	SetHeaders(w)
+	w.Header().Set("Content-type", "text/calendar")
+	w.Header().Set("charset", "utf-8")
+	w.Header().Set("Content-Disposition", "inline")
+	w.Header().Set("filename", "kcapp-calendar.ics")
+
+	players, err := data.GetPlayers()
+	if err != nil {
+		log.Println("Unable to get players", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result := models.Entries{}
+	for _, match := range schedule {
+		names := make([]string, 0)
+		for _, playerID := range match.Players {
+			if player, ok := players[playerID]; ok {
+				names = append(names, player.FirstName)
+			}
+		}
+		entry := new(models.Entry)
+		entry.DateStart = match.StartTime
+		entry.DateEnd = match.EndTime
+		entry.Summary = strings.Join(names, " vs. ")
+		if match.MatchDisplayname.Valid && match.MatchDisplayname.String != "" {
+			entry.Summary = match.MatchDisplayname.String + ": " + entry.Summary
+		}
+		location := "Dart Board"
+		if match.VenueName.Valid {
+			location = match.VenueName.String
+		}
+		entry.Location = location
+		entry.Description = match.TournamentName.String + " (" + strconv.Itoa(match.MatchID) + ") - " + strings.Join(names, " vs. ") + " at " + location
+		result = append(result, entry)
+	}
+
+	b := bytes.Buffer{}
+	goics.NewICalEncode(&b).Encode(result)
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.Bytes())
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// NewSeason will create a new season
+func NewSeason(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	var season models.Season
+	err := json.NewDecoder(r.Body).Decode(&season)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := data.NewSeason(season)
+	if err != nil {
+		log.Println("Unable to create season", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetSeasons will return all seasons
+func GetSeasons(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	seasons, err := data.GetSeasons()
+	if err != nil {
+		log.Println("Unable to get seasons", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(seasons)
+}
+
+// GetSeason will return the season with the given ID
+func GetSeason(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	season, err := data.GetSeason(id)
+	if err != nil {
+		log.Println("Unable to get season", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(season)
+}
+
+// FinishSeason will finish the given season, setting promotions and relegations, and create the next season
+func FinishSeason(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var next models.Season
+	err = json.NewDecoder(r.Body).Decode(&next)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	season, err := data.FinishSeason(id, next)
+	if err != nil {
+		log.Println("Unable to finish season", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(season)
+}
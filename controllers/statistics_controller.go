@@ -190,6 +190,25 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetVisitDistribution will return visit score distribution for all players for the given match type and period
+func GetVisitDistribution(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	matchType, err := strconv.Atoi(params["match_type"])
+	if err != nil || (matchType != models.X01 && matchType != models.SHOOTOUT) {
+		log.Println("Invalid match type parameter")
+		http.Error(w, "Visit distribution is only available for X01 and Shootout", http.StatusBadRequest)
+		return
+	}
+	stats, err := data.GetVisitDistribution(params["from"], params["to"], matchType)
+	if err != nil {
+		log.Println("Unable to get visit distribution", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
 // GetGlobalStatistics will return some global statistics for all matches
 func GetGlobalStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
@@ -203,6 +222,49 @@ func GetGlobalStatistics(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(global)
 }
 
+// GetGlobalStatisticsForPeriod will return global statistics for the given period, optionally filtered by match type and office
+// and split into day, week or month buckets
+func GetGlobalStatisticsForPeriod(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	query := r.URL.Query()
+
+	matchType := 0
+	if query.Get("match_type") != "" {
+		var err error
+		matchType, err = strconv.Atoi(query.Get("match_type"))
+		if err != nil {
+			log.Println("Invalid match type parameter")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	officeID := 0
+	if query.Get("office_id") != "" {
+		var err error
+		officeID, err = strconv.Atoi(query.Get("office_id"))
+		if err != nil {
+			log.Println("Invalid office id parameter")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	bucket := query.Get("bucket")
+	if !models.IsValidGlobalStatisticsBucket(bucket) {
+		log.Println("Invalid bucket parameter")
+		http.Error(w, "Bucket must be one of day, week or month", http.StatusBadRequest)
+		return
+	}
+
+	global, err := data.GetGlobalStatisticsForPeriod(params["from"], params["to"], matchType, officeID, bucket)
+	if err != nil {
+		log.Println("Unable to get global statistics for period", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(global)
+}
+
 // GetGlobalStatisticsFnc will return global fish and chips counter
 func GetGlobalStatisticsFnc(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
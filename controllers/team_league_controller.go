@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// NewTeamLeague will create a new team league between offices
+func NewTeamLeague(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	var league models.TeamLeague
+	err := json.NewDecoder(r.Body).Decode(&league)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = league.Validate()
+	if err != nil {
+		log.Println("Invalid team league", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := data.NewTeamLeague(league)
+	if err != nil {
+		log.Println("Unable to create team league", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetTeamLeagues will return all team leagues
+func GetTeamLeagues(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	leagues, err := data.GetTeamLeagues()
+	if err != nil {
+		log.Println("Unable to get team leagues", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(leagues)
+}
+
+// GetTeamLeague will return the team league with the given ID
+func GetTeamLeague(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	league, err := data.GetTeamLeague(id)
+	if err != nil {
+		log.Println("Unable to get team league", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(league)
+}
+
+// GetTeamFixtures will return all fixtures of the given team league
+func GetTeamFixtures(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fixtures, err := data.GetTeamFixtures(id)
+	if err != nil {
+		log.Println("Unable to get team fixtures", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(fixtures)
+}
+
+// GetTeamLeagueStandings will return the league table of the given team league
+func GetTeamLeagueStandings(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	standings, err := data.GetTeamLeagueStandings(id)
+	if err != nil {
+		log.Println("Unable to get team league standings", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(standings)
+}
+
+// GetTeamLeagueAppearances will return the appearance record of all players in the given team league
+func GetTeamLeagueAppearances(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	appearances, err := data.GetTeamLeagueAppearances(id)
+	if err != nil {
+		log.Println("Unable to get team league appearances", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(appearances)
+}
+
+// GetTeamFixture will return the team fixture with the given ID
+func GetTeamFixture(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fixture, err := data.GetTeamFixture(id)
+	if err != nil {
+		log.Println("Unable to get team fixture", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(fixture)
+}
+
+// SubmitTeamLineup will submit the lineup of an office for the given fixture
+func SubmitTeamLineup(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var lineup models.TeamLineup
+	err = json.NewDecoder(r.Body).Decode(&lineup)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fixture, err := data.SubmitTeamLineup(id, lineup)
+	if err != nil {
+		log.Println("Unable to submit lineup", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(fixture)
+}
@@ -137,6 +137,8 @@ func GetTournamentsForOffice(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(tournament)
 }
 
+// GetTournamentProbabilities will return winning probabilities for all matches in the given tournament, using the rating system
+// given by the 'rating' parameter
 func GetTournamentProbabilities(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
 	params := mux.Vars(r)
@@ -146,7 +148,16 @@ func GetTournamentProbabilities(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	prob, err := data.GetTournamentProbabilities(id)
+	system := r.URL.Query().Get("rating")
+	if system == "" {
+		system = models.RatingSystemElo
+	}
+	if !models.IsValidRatingSystem(system) {
+		log.Println("Invalid rating parameter")
+		http.Error(w, "Unknown rating system "+system, http.StatusBadRequest)
+		return
+	}
+	prob, err := data.GetTournamentProbabilities(id, system)
 	if err != nil {
 		log.Println("Unable to get tournament probabilities", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -155,6 +166,45 @@ func GetTournamentProbabilities(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(prob)
 }
 
+// SimulateTournament will simulate the remaining matches of the given tournament, and return finishing position distributions for each player
+func SimulateTournament(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+
+	iterations := 10000
+	if query.Get("iterations") != "" {
+		iterations, err = strconv.Atoi(query.Get("iterations"))
+		if err != nil || iterations < 1 || iterations > 100000 {
+			log.Println("Invalid iterations parameter")
+			http.Error(w, "iterations must be between 1 and 100000", http.StatusBadRequest)
+			return
+		}
+	}
+	playoffsSize := 0
+	if query.Get("playoffs_size") != "" {
+		playoffsSize, err = strconv.Atoi(query.Get("playoffs_size"))
+		if err != nil {
+			log.Println("Invalid playoffs size parameter")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	simulation, err := data.SimulateTournament(id, iterations, playoffsSize)
+	if err != nil {
+		log.Println("Unable to simulate tournament", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(simulation)
+}
+
 // GetTournamentMatches will return all matches for the given tournament
 func GetTournamentMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
@@ -402,6 +452,96 @@ func GeneratePlayoffsTournament(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(tournament)
 }
 
+// GenerateBracketTournament will generate a new single or double elimination playoffs tournament
+func GenerateBracketTournament(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var options models.BracketOptions
+	err = json.NewDecoder(r.Body).Decode(&options)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tournament, err := data.GenerateBracketTournament(id, &options)
+	if err != nil {
+		log.Println("Unable to generate bracket tournament", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(tournament)
+}
+
+// GenerateSwissTournament will generate a new Swiss-system tournament, including the first round
+func GenerateSwissTournament(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	var input models.SwissTournamentInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		log.Println("Unable to deserialize body", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if input.Rounds < 1 {
+		log.Println("Invalid number of rounds")
+		http.Error(w, "swiss_rounds must be at least 1", http.StatusBadRequest)
+		return
+	}
+
+	swiss, err := data.GenerateSwissTournament(input)
+	if err != nil {
+		log.Println("Unable to generate Swiss tournament", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(swiss)
+}
+
+// GetSwissTournament will return standings and pairings for the given Swiss-system tournament
+func GetSwissTournament(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	swiss, err := data.GetSwissTournament(id)
+	if err != nil {
+		log.Println("Unable to get Swiss tournament", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(swiss)
+}
+
+// GenerateNextSwissRound will generate the next round for the given Swiss-system tournament
+func GenerateNextSwissRound(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	swiss, err := data.GenerateNextSwissRound(id)
+	if err != nil {
+		log.Println("Unable to generate next Swiss round", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(swiss)
+}
+
 // GetTournamentPlayerMatches will return all matches for the given tournament and player
 func GetTournamentPlayerMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
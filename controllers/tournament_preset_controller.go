@@ -8,11 +8,12 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
 )
 
-/*
 // AddTournamentPreset will create a new tournament preset
 func AddTournamentPreset(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
 	var preset models.TournamentPreset
 	err := json.NewDecoder(r.Body).Decode(&preset)
 	if err != nil {
@@ -20,14 +21,20 @@ func AddTournamentPreset(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	err = data.AddTournamentPreset(preset)
+	err = data.ValidateTournamentPreset(&preset)
+	if err != nil {
+		log.Println("Invalid preset", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := data.AddTournamentPreset(preset)
 	if err != nil {
 		log.Println("Unable to add preset", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-}*/
+	json.NewEncoder(w).Encode(created)
+}
 
 // GetTournamentPresets will return a list of all presets
 func GetTournamentPresets(w http.ResponseWriter, r *http.Request) {
@@ -60,9 +67,9 @@ func GetTournamentPreset(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(preset)
 }
 
-/*
 // UpdateTournamentPreset will update the given preset
 func UpdateTournamentPreset(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
@@ -78,12 +85,71 @@ func UpdateTournamentPreset(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	err = data.UpdateTournamentPreset(id, preset)
+	err = data.ValidateTournamentPreset(&preset)
+	if err != nil {
+		log.Println("Invalid preset", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	updated, err := data.UpdateTournamentPreset(id, preset)
 	if err != nil {
 		log.Println("Unable to update preset", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	json.NewEncoder(w).Encode(updated)
+}
+
+// CloneTournamentPreset will create a copy of the given preset, optionally with a new name
+func CloneTournamentPreset(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Name string `json:"name"`
+	}
+	if r.ContentLength != 0 {
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Println("Unable to deserialize body", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	preset, err := data.CloneTournamentPreset(id, body.Name)
+	if err != nil {
+		log.Println("Unable to clone preset", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(preset)
+}
+
+// DeleteTournamentPreset will delete the given preset
+func DeleteTournamentPreset(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = data.CheckTournamentPresetUnused(id)
+	if err != nil {
+		log.Println("Unable to delete preset", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = data.DeleteTournamentPreset(id)
+	if err != nil {
+		log.Println("Unable to delete preset", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 }
-*/
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/guregu/null"
 	"github.com/kcapp/api/data"
 	"github.com/kcapp/api/models"
 )
@@ -143,6 +144,34 @@ func GetRecentPlayers(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(players)
 }
 
+// GetVenueMatchmaking will return suggested pairings for players recently at the given venue
+func GetVenueMatchmaking(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		log.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	input := &models.MatchmakingInput{VenueID: null.IntFrom(int64(id)), MatchTypeID: models.X01}
+	if matchType := r.URL.Query().Get("match_type"); matchType != "" {
+		input.MatchTypeID, err = strconv.Atoi(matchType)
+		if err != nil {
+			log.Println("Invalid match_type parameter")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	matchmaking, err := data.GetMatchmaking(input)
+	if err != nil {
+		log.Println("Unable to get matchmaking suggestions for venue", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(matchmaking)
+}
+
 // GetActiveVenueMatches will return a list of active matches
 func GetActiveVenueMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/guregu/null"
 	"github.com/kcapp/api/models"
 )
 
@@ -38,8 +39,35 @@ func GetBadges() ([]*models.Badge, error) {
 	return badges, nil
 }
 
-func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.BadgeStatistics) error {
-	tx, err := models.DB.Begin()
+// LoadBadgeDefinitions will load and validate badge definitions from the given file. Definitions of badges
+// which do not exist in the badge table are skipped, so new badges are only awarded once they have been added
+func LoadBadgeDefinitions(path string) ([]*models.BadgeDefinition, error) {
+	definitions, err := models.LoadBadgeDefinitions(path)
+	if err != nil {
+		return nil, err
+	}
+	badges, err := GetBadges()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool)
+	for _, badge := range badges {
+		ids[badge.ID] = true
+	}
+	enabled := make([]*models.BadgeDefinition, 0)
+	for _, definition := range definitions {
+		if !ids[definition.ID] {
+			log.Printf("Skipping definition of badge %d (%s), since it does not exist", definition.ID, definition.Name)
+			continue
+		}
+		enabled = append(enabled, definition)
+	}
+	return enabled, nil
+}
+
+// CheckLegForBadges will award all leg and visit badges earned in the given leg, optionally limited to the given badge IDs
+func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.BadgeStatistics, badgeIDs ...int) error {
+	match, err := GetMatch(leg.MatchID)
 	if err != nil {
 		return err
 	}
@@ -51,42 +79,70 @@ func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.BadgeStatisti
 	for _, value := range playersMap {
 		players = append(players, value)
 	}
+	legType := models.GetBadgeLegType(leg, match)
+	legStatistics, err := getLegBadgeStatistics(leg.ID, legType)
+	if err != nil {
+		return err
+	}
 
-	for _, badge := range models.LegBadges {
-		valid, playerID := badge.Validate(leg)
-		if valid {
-			if playerID != nil {
-				err = addLegBadge(tx, *playerID, leg.ID, badge, leg.UpdatedAt)
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, playerID := range leg.Players {
+		facts := models.GetLegBadgeFacts(leg, match, players, legStatistics, playerID)
+		for _, badge := range models.GetBadgeDefinitions(models.BadgeScopeLeg, badgeIDs...) {
+			if badge.Evaluate(facts) {
+				err = addLegBadge(tx, playerID, leg.ID, badge.ID, leg.UpdatedAt)
 				if err != nil {
 					return err
 				}
-			} else {
-				for _, playerID := range leg.Players {
-					err = addLegBadge(tx, playerID, leg.ID, badge, leg.UpdatedAt)
-					if err != nil {
-						return err
-					}
-				}
 			}
 		}
 	}
 
-	for _, badge := range models.LegPlayerBadges {
-		valid, playerID := badge.Validate(leg, players)
-		if valid {
-			err = addLegPlayerBadge(tx, *playerID, leg.ID, badge, leg.UpdatedAt)
-			if err != nil {
-				return err
+	for _, badge := range models.GetBadgeDefinitions(models.BadgeScopeVisit, badgeIDs...) {
+		for _, playerID := range leg.Players {
+			count := 0
+			for _, visit := range leg.Visits {
+				if visit.PlayerID == playerID && badge.Evaluate(models.GetVisitBadgeFacts(visit, legType)) {
+					count++
+				}
+			}
+			if count > 0 {
+				level := badge.GetLevel(statistics[playerID], count)
+				err = addVisitBadge(tx, playerID, level, leg.ID, badge, leg.UpdatedAt)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
+	tx.Commit()
 
-	for _, badge := range models.VisitBadges {
-		for _, playerID := range leg.Players {
-			stats := statistics[playerID]
-			valid, level := badge.Validate(stats, leg.Visits)
-			if valid {
-				err = addVisitBadge(tx, playerID, *level, leg.ID, badge, leg.UpdatedAt)
+	return nil
+}
+
+// CheckMatchForBadges will award all match badges earned in the given finished match, optionally limited to the given badge IDs
+func CheckMatchForBadges(matchID int, badgeIDs ...int) error {
+	match, err := GetMatch(matchID)
+	if err != nil {
+		return err
+	}
+	darts, err := getMatchBadgeDarts(matchID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, playerID := range match.Players {
+		facts := models.GetMatchBadgeFacts(match, darts, playerID)
+		for _, badge := range models.GetBadgeDefinitions(models.BadgeScopeMatch, badgeIDs...) {
+			if badge.Evaluate(facts) {
+				err = addMatchBadge(tx, playerID, match, badge.ID)
 				if err != nil {
 					return err
 				}
@@ -98,56 +154,231 @@ func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.BadgeStatisti
 	return nil
 }
 
-func AddBadge(playerID int, badge models.GlobalBadge) error {
+// getMatchBadgeDarts will return the number of darts thrown by each player in each leg of the given match
+func getMatchBadgeDarts(matchID int) (map[int]map[int]int, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			s.leg_id, s.player_id,
+			SUM(1 + (s.second_dart IS NOT NULL) + (s.third_dart IS NOT NULL)) AS 'darts'
+		FROM score s
+			JOIN leg l ON l.id = s.leg_id
+		WHERE l.match_id = ?
+		GROUP BY s.leg_id, s.player_id`, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	darts := make(map[int]map[int]int)
+	for rows.Next() {
+		var legID, playerID, count int
+		err := rows.Scan(&legID, &playerID, &count)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := darts[legID]; !ok {
+			darts[legID] = make(map[int]int)
+		}
+		darts[legID][playerID] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return darts, nil
+}
+
+// legBadgeStatisticsLoaders contains the function loading the finished leg statistics for each leg type with leg badges
+var legBadgeStatisticsLoaders = map[int]func(legID int) ([]models.LegBadgeStatistics, error){
+	models.CRICKET: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetCricketStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.AROUNDTHECLOCK: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetAroundTheClockStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.AROUNDTHEWORLD: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetAroundTheWorldStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.SHANGHAI: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetShanghaiStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.TICTACTOE: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetTicTacToeStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.BERMUDATRIANGLE: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetBermudaTriangleStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.FOURTWENTY: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := Get420StatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.DARTSATX: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetDartsAtXStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.KILLBULL: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetKillBullStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.GOTCHA: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetGotchaStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.JDCPRACTICE: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetJDCPracticeStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.KNOCKOUT: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetKnockoutStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.SCAM: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetScamStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+	models.SHOOTOUT: func(legID int) ([]models.LegBadgeStatistics, error) {
+		stats, err := GetShootoutStatisticsForLeg(legID)
+		statistics := make([]models.LegBadgeStatistics, len(stats))
+		for i, s := range stats {
+			statistics[i] = s
+		}
+		return statistics, err
+	},
+}
+
+// getLegBadgeStatistics will return the badge facts of the finished leg statistics of each player in the given leg
+func getLegBadgeStatistics(legID int, legType int) (map[int]models.BadgeFacts, error) {
+	statistics := make(map[int]models.BadgeFacts)
+	if loader, ok := legBadgeStatisticsLoaders[legType]; ok {
+		stats, err := loader(legID)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range stats {
+			statistics[s.GetPlayerID()] = s.GetBadgeFacts()
+		}
+	}
+	models.SetOpponentBadgeFacts(statistics)
+	return statistics, nil
+}
+
+// AddBadge will award the given badge to the given player
+func AddBadge(playerID int, badgeID int) error {
 	_, err := models.DB.Exec("INSERT IGNORE INTO player2badge (player_id, badge_id, created_at) VALUES (?, ?, ?)",
-		playerID, badge.GetID(), time.Now())
+		playerID, badgeID, time.Now())
 	if err != nil {
 		return err
 	}
-	log.Printf("Added global badge %d to player %d", badge.GetID(), playerID)
+	log.Printf("Added global badge %d to player %d", badgeID, playerID)
 	return nil
 }
 
-func AddTournamentBadge(playerID int, tournamentID int, badge models.GlobalBadge, when time.Time) error {
+// AddTournamentBadge will award the given badge to the given player for the given tournament
+func AddTournamentBadge(playerID int, tournamentID int, badgeID int, when time.Time) error {
 	_, err := models.DB.Exec("INSERT IGNORE INTO player2badge (player_id, badge_id, tournament_id, created_at) VALUES (?, ?, ?, ?)",
-		playerID, badge.GetID(), tournamentID, when)
+		playerID, badgeID, tournamentID, when)
 	if err != nil {
 		return err
 	}
-	log.Printf("Added tournament badge %d to player %d", badge.GetID(), playerID)
+	log.Printf("Added tournament badge %d to player %d", badgeID, playerID)
 	return nil
 }
 
-func addLegBadge(tx *sql.Tx, playerID int, legID int, badge models.LegBadge, when time.Time) error {
+func addLegBadge(tx *sql.Tx, playerID int, legID int, badgeID int, when time.Time) error {
 	_, err := tx.Exec("INSERT IGNORE INTO player2badge (player_id, badge_id, leg_id, created_at) VALUES (?, ?, ?, ?)",
-		playerID, badge.GetID(), legID, when)
+		playerID, badgeID, legID, when)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
-	log.Printf("Added leg badge %d to player %d on leg %d", badge.GetID(), playerID, legID)
+	log.Printf("Added leg badge %d to player %d on leg %d", badgeID, playerID, legID)
 	return nil
 }
 
-func addLegPlayerBadge(tx *sql.Tx, playerID int, legID int, badge models.LegPlayerBadge, when time.Time) error {
-	_, err := tx.Exec("INSERT IGNORE INTO player2badge (player_id, badge_id, leg_id, created_at) VALUES (?, ?, ?, ?)",
-		playerID, badge.GetID(), legID, when)
+func addMatchBadge(tx *sql.Tx, playerID int, match *models.Match, badgeID int) error {
+	// Link the opponent if there is only one
+	var opponentID null.Int
+	if len(match.Players) == 2 {
+		opponentID = null.IntFrom(int64(match.Players[0]))
+		if match.Players[0] == playerID {
+			opponentID = null.IntFrom(int64(match.Players[1]))
+		}
+	}
+	_, err := tx.Exec("INSERT IGNORE INTO player2badge (player_id, badge_id, match_id, opponent_player_id, created_at) VALUES (?, ?, ?, ?, ?)",
+		playerID, badgeID, match.ID, opponentID, match.EndTime)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
-	log.Printf("Added leg player badge %d to player %d on leg %d", badge.GetID(), playerID, legID)
+	log.Printf("Added match badge %d to player %d on match %d", badgeID, playerID, match.ID)
 	return nil
 }
 
-func addVisitBadge(tx *sql.Tx, playerID int, level int, legID int, badge models.VisitBadge, when time.Time) error {
+func addVisitBadge(tx *sql.Tx, playerID int, level int, legID int, badge *models.BadgeDefinition, when time.Time) error {
 	_, err := tx.Exec(`INSERT INTO player2badge (player_id, badge_id, level, value, leg_id, created_at) VALUES (?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE leg_id=IF(?>level,?,leg_id), created_at=IF(?>level,?,created_at), value=IF(?>level,?,value),level=?`,
-		playerID, badge.GetID(), level, badge.Levels()[level-1], legID, when, level, legID, level, when, level, badge.Levels()[level-1], level)
+		playerID, badge.ID, level, badge.Levels[level-1], legID, when, level, legID, level, when, level, badge.Levels[level-1], level)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
-	log.Printf("Added visit badge %d (level %d) to player %d on leg %d", badge.GetID(), level, playerID, legID)
+	log.Printf("Added visit badge %d (level %d) to player %d on leg %d", badge.ID, level, playerID, legID)
 	return nil
 }
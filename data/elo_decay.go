@@ -0,0 +1,129 @@
+package data
+
+import (
+	"log"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// GetEloDecay will return the Elo decay for all active players who have been inactive longer than the configured period.
+// Players are decayed toward the mean Elo of non-provisional players in their office, and at most once per inactivity period
+func GetEloDecay() ([]*models.PlayerEloDecay, error) {
+	means, err := getOfficeEloMeans()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT
+			p.id, CONCAT(p.first_name, ' ', IFNULL(p.last_name, '')), p.office_id, pe.current_elo, pe.current_elo_matches,
+			DATEDIFF(NOW(), MAX(m.updated_at)) AS 'inactive_days',
+			DATEDIFF(NOW(), GREATEST(MAX(m.updated_at), IFNULL((SELECT MAX(d.created_at) FROM player_elo_decay d WHERE d.player_id = p.id),
+				MAX(m.updated_at)))) AS 'days_since_activity'
+		FROM player p
+			JOIN player_elo pe ON pe.player_id = p.id
+			JOIN player_elo_changelog pec ON pec.player_id = p.id
+			JOIN matches m ON m.id = pec.match_id
+		WHERE p.active = 1 AND p.is_bot = 0 AND p.is_placeholder = 0
+		GROUP BY p.id, p.first_name, p.last_name, p.office_id, pe.current_elo, pe.current_elo_matches
+		ORDER BY p.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decays := make([]*models.PlayerEloDecay, 0)
+	for rows.Next() {
+		decay := new(models.PlayerEloDecay)
+		var matches, daysSinceActivity int
+		err := rows.Scan(&decay.PlayerID, &decay.PlayerName, &decay.OfficeID, &decay.Elo, &matches, &decay.InactiveDays, &daysSinceActivity)
+		if err != nil {
+			return nil, err
+		}
+		if models.EloSettings.IsProvisional(matches) || !models.EloSettings.IsInactive(daysSinceActivity) {
+			continue
+		}
+		mean, ok := means[decay.OfficeID]
+		if !ok {
+			mean = means[null.Int{}]
+		}
+		decay.OfficeMean = mean
+		decay.EloNew = models.EloSettings.CalculateEloDecay(decay.Elo, mean)
+		if decay.EloNew == decay.Elo {
+			continue
+		}
+		decays = append(decays, decay)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return decays, nil
+}
+
+// DecayElo will decay the Elo of all inactive players toward their office mean
+func DecayElo(dryRun bool) error {
+	decays, err := GetEloDecay()
+	if err != nil {
+		return err
+	}
+	for _, decay := range decays {
+		log.Printf("Player %d (%s): %d -> %d (office mean %d, inactive for %d days)", decay.PlayerID, decay.PlayerName,
+			decay.Elo, decay.EloNew, decay.OfficeMean, decay.InactiveDays)
+	}
+	if dryRun {
+		log.Printf("Elo not decayed for %d players because dry-run is enabled", len(decays))
+		return nil
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, decay := range decays {
+		_, err = tx.Exec("UPDATE player_elo SET current_elo = ? WHERE player_id = ?", decay.EloNew, decay.PlayerID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO player_elo_decay (player_id, old_elo, new_elo, office_mean, inactive_days, created_at)
+			VALUES (?, ?, ?, ?, ?, NOW())`, decay.PlayerID, decay.Elo, decay.EloNew, decay.OfficeMean, decay.InactiveDays)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	tx.Commit()
+	log.Printf("Decayed Elo for %d players", len(decays))
+	return nil
+}
+
+// getOfficeEloMeans will return the mean Elo of active, non-provisional players per office. The mean across all offices
+// is returned for the empty office ID, and is used for players without an office
+func getOfficeEloMeans() (map[null.Int]int, error) {
+	rows, err := models.DB.Query(`
+		SELECT p.office_id, ROUND(AVG(pe.current_elo))
+		FROM player_elo pe
+			JOIN player p ON p.id = pe.player_id
+		WHERE p.active = 1 AND p.is_bot = 0 AND p.is_placeholder = 0 AND pe.current_elo_matches >= ?
+		GROUP BY p.office_id WITH ROLLUP`, models.EloSettings.ProvisionalMatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	means := map[null.Int]int{{}: models.EloDefault}
+	for rows.Next() {
+		var officeID null.Int
+		var mean int
+		err := rows.Scan(&officeID, &mean)
+		if err != nil {
+			return nil, err
+		}
+		means[officeID] = mean
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return means, nil
+}
@@ -0,0 +1,241 @@
+package data
+
+import (
+	"log"
+
+	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// UpdateMatchTypeEloForMatch will update the Elo for the match type of the given match for each player.
+// Matches with more than two players are decomposed into pairwise results, and the average change against each opponent is used
+func UpdateMatchTypeEloForMatch(matchID int) error {
+	match, err := GetMatch(matchID)
+	if err != nil {
+		return err
+	}
+	if len(match.Players) < 2 || match.IsWalkover || match.IsAbandoned || match.IsPractice || !match.IsFinished {
+		return nil
+	}
+
+	elos, err := GetPlayersMatchTypeElo(match.MatchType.ID, match.Players...)
+	if err != nil {
+		return err
+	}
+	wins, err := GetWinsPerPlayer(matchID)
+	if err != nil {
+		return err
+	}
+	changes := make(map[int]int)
+	for i, player1 := range match.Players {
+		for _, player2 := range match.Players[i+1:] {
+			p1 := elos[player1]
+			p2 := elos[player2]
+			new1, new2 := CalculateElo(p1.Elo, p1.Matches, wins[player1], p2.Elo, p2.Matches, wins[player2])
+			changes[player1] += new1 - p1.Elo
+			changes[player2] += new2 - p2.Elo
+		}
+	}
+	opponents := len(match.Players) - 1
+	for _, elo := range elos {
+		elo.EloNew = elo.Elo + changes[elo.PlayerID]/opponents
+		elo.Matches++
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, elo := range elos {
+		_, err = tx.Exec(`
+			INSERT INTO player_elo_match_type (player_id, match_type_id, elo, matches) VALUES (?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE elo = VALUES(elo), matches = VALUES(matches)`, elo.PlayerID, elo.MatchTypeID, elo.EloNew, elo.Matches)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO player_elo_match_type_changelog (match_id, player_id, match_type_id, old_elo, new_elo) VALUES (?, ?, ?, ?, ?)`,
+			matchID, elo.PlayerID, elo.MatchTypeID, elo.Elo, elo.EloNew)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+// GetPlayersMatchTypeElo will get the Elo for the given match type for the given player IDs, using initial values for players without a rating
+func GetPlayersMatchTypeElo(matchTypeID int, playerIDs ...int) (map[int]*models.PlayerMatchTypeElo, error) {
+	q, args, err := sqlx.In(`
+		SELECT player_id, match_type_id, elo, matches
+		FROM player_elo_match_type
+		WHERE match_type_id = ? AND player_id IN (?)`, matchTypeID, playerIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	elos := make(map[int]*models.PlayerMatchTypeElo)
+	for _, playerID := range playerIDs {
+		elos[playerID] = &models.PlayerMatchTypeElo{PlayerID: playerID, MatchTypeID: matchTypeID, Elo: models.EloDefault}
+	}
+	for rows.Next() {
+		elo := new(models.PlayerMatchTypeElo)
+		err := rows.Scan(&elo.PlayerID, &elo.MatchTypeID, &elo.Elo, &elo.Matches)
+		if err != nil {
+			return nil, err
+		}
+		elos[elo.PlayerID] = elo
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return elos, nil
+}
+
+// GetPlayerEloBreakdown will return the Elo, Glicko-2 rating and the Elo for each match type the given player has played
+func GetPlayerEloBreakdown(playerID int) (*models.PlayerEloBreakdown, error) {
+	breakdown := &models.PlayerEloBreakdown{PlayerID: playerID}
+	elos, err := GetPlayersElo(playerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(elos) > 0 {
+		breakdown.Elo = elos[0]
+	}
+	glicko, err := GetPlayersGlicko(playerID)
+	if err != nil {
+		return nil, err
+	}
+	breakdown.Glicko = glicko[playerID]
+
+	rows, err := models.DB.Query(`
+		SELECT pe.player_id, pe.match_type_id, mt.name, pe.elo, pe.matches
+		FROM player_elo_match_type pe
+			JOIN match_type mt ON mt.id = pe.match_type_id
+		WHERE pe.player_id = ?
+		ORDER BY pe.match_type_id`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdown.MatchTypes = make([]*models.PlayerMatchTypeElo, 0)
+	for rows.Next() {
+		elo := new(models.PlayerMatchTypeElo)
+		err := rows.Scan(&elo.PlayerID, &elo.MatchTypeID, &elo.MatchType, &elo.Elo, &elo.Matches)
+		if err != nil {
+			return nil, err
+		}
+		breakdown.MatchTypes = append(breakdown.MatchTypes, elo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+// GetPlayerMatchTypeEloChangelog returns the Elo changelog of the given match type for the given player
+func GetPlayerMatchTypeEloChangelog(id int, matchTypeID int, start int, limit int) (*models.PlayerMatchTypeEloChangelogs, error) {
+	var total int
+	err := models.DB.QueryRow(`SELECT COUNT(id) FROM player_elo_match_type_changelog WHERE player_id = ? AND match_type_id = ?`,
+		id, matchTypeID).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT
+			c.id, c.match_id, m.updated_at,
+			IF(m.tournament_id IS NULL, FALSE, TRUE) AS 'is_official',
+			mm.short_name AS 'match_mode', m.winner_id,
+			c.player_id, c.match_type_id, mt.name, c.old_elo, c.new_elo,
+			(SELECT GROUP_CONCAT(o.player_id) FROM player_elo_match_type_changelog o WHERE o.match_id = c.match_id AND o.player_id <> c.player_id) AS 'opponents'
+		FROM player_elo_match_type_changelog c
+			JOIN matches m ON m.id = c.match_id
+			JOIN match_type mt ON mt.id = c.match_type_id
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+		WHERE c.player_id = ? AND c.match_type_id = ?
+		ORDER BY c.id DESC
+		LIMIT ?, ?`, id, matchTypeID, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changelogs := new(models.PlayerMatchTypeEloChangelogs)
+	changelogs.Total = total
+	changelogs.Changelog = make([]*models.PlayerMatchTypeEloChangelog, 0)
+	for rows.Next() {
+		change := new(models.PlayerMatchTypeEloChangelog)
+		player := new(models.PlayerMatchTypeElo)
+		var opponents null.String
+		err := rows.Scan(&change.ID, &change.MatchID, &change.FinishedAt, &change.IsOfficial, &change.MatchMode, &change.WinnerID,
+			&player.PlayerID, &player.MatchTypeID, &player.MatchType, &player.Elo, &player.EloNew, &opponents)
+		if err != nil {
+			return nil, err
+		}
+		change.Player = player
+		change.Opponents = make([]int, 0)
+		if opponents.Valid {
+			change.Opponents = util.StringToIntArray(opponents.String)
+		}
+		changelogs.Changelog = append(changelogs.Changelog, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changelogs, nil
+}
+
+// RecalculateMatchTypeElo will recalculate the Elo for each match type for all players
+func RecalculateMatchTypeElo(dryRun bool) error {
+	rows, err := models.DB.Query(`
+		SELECT id FROM matches
+		WHERE is_finished = 1 AND is_practice = 0 AND is_abandoned = 0 AND is_walkover = 0
+		ORDER BY updated_at`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	matches := make([]int, 0)
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return err
+		}
+		matches = append(matches, id)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if dryRun {
+		log.Print("Match type Elo not reset because dry-run is enabled")
+		return nil
+	}
+	log.Printf("Recalculating match type Elo for %d matches", len(matches))
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	tx.Exec(`DELETE FROM player_elo_match_type;`)
+	tx.Exec(`DELETE FROM player_elo_match_type_changelog;`)
+	tx.Commit()
+
+	for _, id := range matches {
+		err = UpdateMatchTypeEloForMatch(id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
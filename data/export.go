@@ -0,0 +1,181 @@
+package data
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/kcapp/api/models"
+)
+
+// exportSource describes where the rows of an export table are loaded from
+type exportSource struct {
+	// from is the FROM clause, which must always join matches as m, and leg as l (if available)
+	from string
+	// alias is the alias of the main table, used for columns without an explicit expression
+	alias string
+	// expressions are explicit SQL expressions for columns not read directly from the main table
+	expressions map[string]string
+	// playerFilter is the condition used when filtering on a player
+	playerFilter string
+	// dateColumn is the column used for filtering on date
+	dateColumn string
+	// orderBy is the ordering of rows, which must be stable between exports
+	orderBy string
+}
+
+const exportLegFrom = `
+		JOIN leg l ON l.id = %s.leg_id
+		JOIN matches m ON m.id = l.match_id`
+
+var exportSources = map[string]*exportSource{
+	"matches": {
+		from:  "FROM matches m",
+		alias: "m",
+		expressions: map[string]string{
+			"players": "(SELECT GROUP_CONCAT(DISTINCT p2l.player_id ORDER BY p2l.order) FROM player2leg p2l WHERE p2l.leg_id = m.current_leg_id)",
+		},
+		playerFilter: "EXISTS (SELECT 1 FROM player2leg p2l WHERE p2l.match_id = m.id AND p2l.player_id = ?)",
+		dateColumn:   "m.created_at",
+		orderBy:      "m.id",
+	},
+	"legs": {
+		from:  "FROM leg l JOIN matches m ON m.id = l.match_id",
+		alias: "l",
+		expressions: map[string]string{
+			"match_type_id": "IFNULL(l.leg_type_id, m.match_type_id)",
+			"players":       "(SELECT GROUP_CONCAT(p2l.player_id ORDER BY p2l.order) FROM player2leg p2l WHERE p2l.leg_id = l.id)",
+		},
+		playerFilter: "EXISTS (SELECT 1 FROM player2leg p2l WHERE p2l.leg_id = l.id AND p2l.player_id = ?)",
+		dateColumn:   "l.created_at",
+		orderBy:      "l.id",
+	},
+	"visits": {
+		from:         "FROM score s" + fmt.Sprintf(exportLegFrom, "s"),
+		alias:        "s",
+		playerFilter: "s.player_id = ?",
+		dateColumn:   "s.created_at",
+		orderBy:      "s.id",
+	},
+	"elo_changelog": {
+		from:  "FROM player_elo_changelog c JOIN matches m ON m.id = c.match_id",
+		alias: "c",
+		expressions: map[string]string{
+			"created_at": "m.updated_at",
+		},
+		playerFilter: "c.player_id = ?",
+		dateColumn:   "m.updated_at",
+		orderBy:      "c.id",
+	},
+	"badges": {
+		from:  "FROM player2badge p2b LEFT JOIN leg l ON l.id = p2b.leg_id LEFT JOIN matches m ON m.id = COALESCE(p2b.match_id, l.match_id)",
+		alias: "p2b",
+		expressions: map[string]string{
+			"match_id": "m.id",
+		},
+		playerFilter: "p2b.player_id = ?",
+		dateColumn:   "p2b.created_at",
+		orderBy:      "p2b.created_at, p2b.player_id, p2b.badge_id",
+	},
+}
+
+// getExportSource will return the source for the given table. All statistics tables share the same structure
+func getExportSource(table string) *exportSource {
+	if source, ok := exportSources[table]; ok {
+		return source
+	}
+	return &exportSource{
+		from:         "FROM " + table + " s" + fmt.Sprintf(exportLegFrom, "s"),
+		alias:        "s",
+		playerFilter: "s.player_id = ?",
+		dateColumn:   "l.created_at",
+		orderBy:      "s.leg_id, s.player_id",
+	}
+}
+
+// buildExportQuery will build the query for the given table and filter
+func buildExportQuery(table *models.ExportTable, filter *models.ExportFilter) (string, []interface{}) {
+	source := getExportSource(table.Name)
+
+	selects := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		if expr, ok := source.expressions[col.Name]; ok {
+			selects[i] = expr
+		} else {
+			selects[i] = fmt.Sprintf("%s.`%s`", source.alias, col.Name)
+		}
+	}
+
+	where := []string{"1 = 1"}
+	args := make([]interface{}, 0)
+	if filter.OfficeID.Valid {
+		where = append(where, "m.office_id = ?")
+		args = append(args, filter.OfficeID.Int64)
+	}
+	if filter.PlayerID.Valid {
+		where = append(where, source.playerFilter)
+		args = append(args, filter.PlayerID.Int64)
+	}
+	if filter.MatchTypeID.Valid {
+		where = append(where, "IFNULL(l.leg_type_id, m.match_type_id) = ?")
+		if table.Name == "matches" || table.Name == "elo_changelog" {
+			where[len(where)-1] = "m.match_type_id = ?"
+		}
+		args = append(args, filter.MatchTypeID.Int64)
+	}
+	if filter.From.Valid {
+		where = append(where, source.dateColumn+" >= ?")
+		args = append(args, filter.From.String)
+	}
+	if filter.To.Valid {
+		where = append(where, source.dateColumn+" < ?")
+		args = append(args, filter.To.String)
+	}
+
+	query := fmt.Sprintf("SELECT %s %s WHERE %s ORDER BY %s", strings.Join(selects, ", "), source.from,
+		strings.Join(where, " AND "), source.orderBy)
+	return query, args
+}
+
+// Export will stream all rows of the given table matching the filter to the given writer, in the given format
+func Export(w io.Writer, tableName string, format string, filter *models.ExportFilter) error {
+	table := models.GetExportTable(tableName)
+	if table == nil {
+		return fmt.Errorf("unknown export table: %s", tableName)
+	}
+	err := filter.Validate()
+	if err != nil {
+		return err
+	}
+	writer, err := models.NewExportWriter(w, format, table)
+	if err != nil {
+		return err
+	}
+
+	query, args := buildExportQuery(table, filter)
+	rows, err := models.DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		values := table.NewScanDestinations()
+		err := rows.Scan(values...)
+		if err != nil {
+			return err
+		}
+		err = writer.Write(values)
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	log.Printf("Exported %d rows from %s", count, table.Name)
+	return writer.Flush()
+}
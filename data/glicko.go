@@ -0,0 +1,254 @@
+package data
+
+import (
+	"log"
+	"sort"
+
+	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// UpdateGlickoForMatch will update the Glicko-2 rating for each player in a match.
+// Matches with more than two players are decomposed into pairwise results between all players
+func UpdateGlickoForMatch(matchID int) error {
+	match, scores, err := getGlickoScores(matchID)
+	if err != nil || match == nil {
+		return err
+	}
+	ratings, err := GetPlayersGlicko(match.Players...)
+	if err != nil {
+		return err
+	}
+	calculateGlickoForMatch(match, scores, ratings)
+	return updateGlicko(matchID, ratings)
+}
+
+// GetPlayersGlicko will get the Glicko-2 rating for the given player IDs, using initial values for players without a rating
+func GetPlayersGlicko(playerIDs ...int) (map[int]*models.PlayerGlicko, error) {
+	q, args, err := sqlx.In(`
+		SELECT pg.player_id, pg.rating, pg.deviation, pg.volatility, pg.matches,
+			(SELECT MAX(m.updated_at) FROM player_glicko_changelog gc JOIN matches m ON m.id = gc.match_id WHERE gc.player_id = pg.player_id)
+		FROM player_glicko pg
+		WHERE pg.player_id IN (?)`, playerIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make(map[int]*models.PlayerGlicko)
+	for _, playerID := range playerIDs {
+		ratings[playerID] = models.NewPlayerGlicko(playerID)
+	}
+	for rows.Next() {
+		p := new(models.PlayerGlicko)
+		err := rows.Scan(&p.PlayerID, &p.Rating, &p.Deviation, &p.Volatility, &p.Matches, &p.LastMatchAt)
+		if err != nil {
+			return nil, err
+		}
+		ratings[p.PlayerID] = p
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// GetPlayerGlickoChangelog returns the Glicko-2 changelog for the given player
+func GetPlayerGlickoChangelog(id int, start int, limit int) (*models.PlayerGlickoChangelogs, error) {
+	var total int
+	err := models.DB.QueryRow(`SELECT COUNT(id) FROM player_glicko_changelog WHERE player_id = ?`, id).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT
+			gc.id, gc.match_id, m.updated_at,
+			IF(m.tournament_id IS NULL, FALSE, TRUE) AS 'is_official',
+			mm.short_name AS 'match_mode',
+			mt.name AS 'match_type', m.winner_id,
+			gc.player_id, gc.old_rating, gc.new_rating, gc.old_deviation, gc.new_deviation, gc.old_volatility, gc.new_volatility,
+			(SELECT GROUP_CONCAT(o.player_id) FROM player_glicko_changelog o WHERE o.match_id = gc.match_id AND o.player_id <> gc.player_id) AS 'opponents'
+		FROM player_glicko_changelog gc
+			JOIN matches m ON m.id = gc.match_id
+			JOIN match_type mt ON m.match_type_id = mt.id
+			JOIN match_mode mm ON m.match_mode_id = mm.id
+		WHERE gc.player_id = ?
+		ORDER BY gc.id DESC
+		LIMIT ?, ?`, id, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changelogs := new(models.PlayerGlickoChangelogs)
+	changelogs.Total = total
+	changelogs.Changelog = make([]*models.PlayerGlickoChangelog, 0)
+	for rows.Next() {
+		change := new(models.PlayerGlickoChangelog)
+		player := new(models.PlayerGlicko)
+		var opponents null.String
+		err := rows.Scan(&change.ID, &change.MatchID, &change.FinishedAt, &change.IsOfficial, &change.MatchMode, &change.MatchType,
+			&change.WinnerID, &player.PlayerID, &player.Rating, &player.RatingNew, &player.Deviation, &player.DeviationNew,
+			&player.Volatility, &player.VolatilityNew, &opponents)
+		if err != nil {
+			return nil, err
+		}
+		change.Player = player
+		change.Opponents = make([]int, 0)
+		if opponents.Valid {
+			change.Opponents = util.StringToIntArray(opponents.String)
+		}
+		changelogs.Changelog = append(changelogs.Changelog, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changelogs, nil
+}
+
+// RecalculateGlicko will recalculate Glicko-2 ratings for all players. With dry-run enabled, the ratings are only calculated
+// in memory, and the current and recalculated rating and deviation of each player is printed
+func RecalculateGlicko(dryRun bool) error {
+	matches, err := getEloMatches()
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		log.Print("Glicko-2 not reset because dry-run is enabled")
+		return printGlickoRecalculation(matches)
+	}
+	log.Printf("Recalculating Glicko-2 for %d matches", len(matches))
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	// Reset all players back to initial values
+	tx.Exec(`DELETE FROM player_glicko;`)
+	tx.Exec(`DELETE FROM player_glicko_changelog;`)
+	tx.Commit()
+
+	for _, id := range matches {
+		err = UpdateGlickoForMatch(id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getGlickoScores will return the given match with the legs won by each player, or nil if the match does not affect ratings
+func getGlickoScores(matchID int) (*models.Match, map[int]int, error) {
+	match, err := GetMatch(matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if match.MatchType.ID != models.X01 || len(match.Players) < 2 || match.IsWalkover || match.IsAbandoned ||
+		match.IsPractice || !match.IsFinished {
+		return nil, nil, nil
+	}
+	wins, err := GetWinsPerPlayer(matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	scores := make(map[int]int)
+	for _, playerID := range match.Players {
+		scores[playerID] = wins[playerID]
+	}
+	return match, scores, nil
+}
+
+// calculateGlickoForMatch will set the new ratings of the players in the given match. The deviation of each player is first
+// increased for the rating periods since their last rated match
+func calculateGlickoForMatch(match *models.Match, scores map[int]int, ratings map[int]*models.PlayerGlicko) {
+	for _, player := range ratings {
+		if player.LastMatchAt.Valid {
+			player.InflateDeviation(models.GetGlickoRatingPeriods(player.LastMatchAt.Time, match.UpdatedAt))
+		}
+	}
+	results := models.GetGlickoResults(scores, ratings)
+	for _, player := range ratings {
+		player.RatingNew, player.DeviationNew, player.VolatilityNew = models.CalculateGlicko(player, results[player.PlayerID])
+	}
+}
+
+// printGlickoRecalculation will replay the given matches in memory, and print the current and recalculated rating of each player
+func printGlickoRecalculation(matches []int) error {
+	ratings := make(map[int]*models.PlayerGlicko)
+	for _, id := range matches {
+		match, scores, err := getGlickoScores(id)
+		if err != nil {
+			return err
+		}
+		if match == nil {
+			continue
+		}
+		players := make(map[int]*models.PlayerGlicko)
+		for _, playerID := range match.Players {
+			player, ok := ratings[playerID]
+			if !ok {
+				player = models.NewPlayerGlicko(playerID)
+			}
+			current := *player
+			players[playerID] = &current
+		}
+		calculateGlickoForMatch(match, scores, players)
+		for playerID, player := range players {
+			ratings[playerID] = &models.PlayerGlicko{PlayerID: playerID, Rating: player.RatingNew, Deviation: player.DeviationNew,
+				Volatility: player.VolatilityNew, Matches: player.Matches + 1, LastMatchAt: null.TimeFrom(match.UpdatedAt)}
+		}
+	}
+	if len(ratings) == 0 {
+		log.Print("No matches to recalculate Glicko-2 for")
+		return nil
+	}
+
+	playerIDs := make([]int, 0)
+	for playerID := range ratings {
+		playerIDs = append(playerIDs, playerID)
+	}
+	sort.Ints(playerIDs)
+	current, err := GetPlayersGlicko(playerIDs...)
+	if err != nil {
+		return err
+	}
+	for _, playerID := range playerIDs {
+		old, recalculated := current[playerID], ratings[playerID]
+		log.Printf("Player %d: rating %.2f -> %.2f, deviation %.2f -> %.2f (%d matches)", playerID, old.Rating, recalculated.Rating,
+			old.Deviation, recalculated.Deviation, recalculated.Matches)
+	}
+	return nil
+}
+
+func updateGlicko(matchID int, ratings map[int]*models.PlayerGlicko) error {
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, player := range ratings {
+		_, err = tx.Exec(`
+			INSERT INTO player_glicko (player_id, rating, deviation, volatility, matches) VALUES (?, ?, ?, ?, 1)
+			ON DUPLICATE KEY UPDATE rating = VALUES(rating), deviation = VALUES(deviation), volatility = VALUES(volatility), matches = matches + 1`,
+			player.PlayerID, player.RatingNew, player.DeviationNew, player.VolatilityNew)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO player_glicko_changelog (match_id, player_id, old_rating, new_rating, old_deviation, new_deviation, old_volatility, new_volatility)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, matchID, player.PlayerID, player.Rating, player.RatingNew, player.Deviation, player.DeviationNew,
+			player.Volatility, player.VolatilityNew)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	tx.Commit()
+	return nil
+}
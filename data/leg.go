@@ -447,6 +447,13 @@ func FinishLeg(visit models.Visit) error {
 		}
 	}
 
+	// Calculate accuracy statistics for game types where the intended target of each dart is known
+	err = insertAccuracyStatistics(tx, leg)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Check if match is finished or not
 	winsMap, err := GetWinsPerPlayer(match.ID)
 	if err != nil {
@@ -507,11 +514,20 @@ func FinishLeg(visit models.Visit) error {
 	tx.Commit()
 
 	if isFinished {
-		// Update Elo for players if match is finished
+		// Update Elo and Glicko-2 ratings for players if match is finished
 		err = UpdateEloForMatch(match.ID)
 		if err != nil {
 			return err
 		}
+		// Glicko-2 and match type Elo are secondary ratings, so a failure is logged instead of stopping tournament advancement
+		err = UpdateGlickoForMatch(match.ID)
+		if err != nil {
+			log.Printf("Unable to update Glicko-2 ratings for match %d: %s", match.ID, err)
+		}
+		err = UpdateMatchTypeEloForMatch(match.ID)
+		if err != nil {
+			log.Printf("Unable to update match type Elo for match %d: %s", match.ID, err)
+		}
 
 		if match.TournamentID.Valid {
 			metadata, err := GetMatchMetadata(match.ID)
@@ -562,7 +578,11 @@ func FinishLeg(visit models.Visit) error {
 		}
 	}
 
-	// Calculate badges earned in this leg
+	// Calculate badges earned in this leg, reloading it to include the winner and end time
+	leg, err = GetLeg(leg.ID)
+	if err != nil {
+		return err
+	}
 	statistics, err := GetPlayerBadgeStatistics(leg.Players, nil)
 	if err != nil {
 		return err
@@ -571,6 +591,13 @@ func FinishLeg(visit models.Visit) error {
 	if err != nil {
 		return err
 	}
+	if isFinished {
+		// Calculate badges earned in the match
+		err = CheckMatchForBadges(match.ID)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -588,6 +615,12 @@ func UndoLegFinish(legID int) error {
 		tx.Rollback()
 		return err
 	}
+	// Remove badges earned in the match, since it is no longer finished
+	_, err = tx.Exec("DELETE FROM player2badge WHERE match_id = (SELECT match_id FROM leg WHERE id = ?)", legID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 	// Undo the finalized leg
 	_, err = tx.Exec("UPDATE leg SET is_finished = 0, winner_id = NULL WHERE id = ?", legID)
 	if err != nil {
@@ -660,6 +693,11 @@ func UndoLegFinish(legID int) error {
 		tx.Rollback()
 		return err
 	}
+	_, err = tx.Exec("DELETE FROM statistics_accuracy WHERE leg_id = ?", legID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	// Remove the last score
 	_, err = tx.Exec("DELETE FROM score WHERE leg_id = ? ORDER BY id DESC LIMIT 1", legID)
@@ -827,13 +865,44 @@ func GetLegsToRecalculate(matchType int, since string) ([]int, error) {
 	return legs, nil
 }
 
+// GetBadgeMatchesToRecalculate returns all finished matches which can generate badges which can be recalculated, skipping matches
+// where only the score was set
+func GetBadgeMatchesToRecalculate() ([]int, error) {
+	rows, err := models.DB.Query(`
+		SELECT m.id
+		FROM matches m
+		WHERE m.is_finished = 1
+			AND m.is_abandoned = 0 AND m.is_bye = 0 AND m.is_walkover = 0
+			AND NOT EXISTS (SELECT 1 FROM leg l WHERE l.match_id = m.id AND l.has_scores = 0)
+		ORDER BY m.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]int, 0)
+	for rows.Next() {
+		var matchID int
+		err := rows.Scan(&matchID)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, matchID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
 // GetBadgeLegsToRecalculate returns all legs which can generate badges which can be recalculated
 func GetBadgeLegsToRecalculate() ([]int, error) {
 	rows, err := models.DB.Query(`
 		SELECT l.id
 		FROM leg l
 			JOIN matches m on m.id = l.match_id
-		WHERE l.has_scores = 1 AND COALESCE(l.leg_type_id, m.match_type_id) = 1 -- X01
+		WHERE l.has_scores = 1
 			AND m.is_abandoned = 0 AND m.is_bye = 0 AND m.is_walkover = 0
 			AND l.is_finished = 1
 		GROUP BY l.id
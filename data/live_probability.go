@@ -0,0 +1,200 @@
+package data
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// liveProbabilityCache holds calculated live probabilities, so polling a leg only simulates new visits
+var liveProbabilityCache = models.NewLiveProbabilityCache(models.LiveProbabilityCacheSize)
+
+// GetLegLiveProbabilities will return the live leg and match win probabilities after each visit of the given X01 leg
+func GetLegLiveProbabilities(legID int) (*models.LiveProbabilities, error) {
+	return getLiveProbabilities(legID, true)
+}
+
+// GetMatchLiveProbability will return the current live win probabilities for the current leg of the given match
+func GetMatchLiveProbability(matchID int) (*models.LiveProbability, error) {
+	match, err := GetMatch(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if !match.CurrentLegID.Valid {
+		return nil, fmt.Errorf("match %d does not have a current leg", matchID)
+	}
+	probabilities, err := getLiveProbabilities(int(match.CurrentLegID.Int64), false)
+	if err != nil {
+		return nil, err
+	}
+	return probabilities.Current, nil
+}
+
+// getLiveProbabilities will calculate live win probabilities for the given leg, optionally after each visit of the leg
+func getLiveProbabilities(legID int, history bool) (*models.LiveProbabilities, error) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		return nil, err
+	}
+	match, err := GetMatch(leg.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	matchType := match.MatchType.ID
+	if leg.LegType != nil {
+		matchType = leg.LegType.ID
+	}
+	if matchType != models.X01 && matchType != models.X01HANDICAP {
+		return nil, fmt.Errorf("live win probability is only available for X01 legs")
+	}
+
+	players, err := GetPlayersScore(legID)
+	if err != nil {
+		return nil, err
+	}
+	wins, err := GetWinsPerPlayer(match.ID)
+	if err != nil {
+		return nil, err
+	}
+	if leg.IsFinished && leg.WinnerPlayerID.Valid {
+		// Legs won should not include the given leg
+		wins[int(leg.WinnerPlayerID.Int64)]--
+	}
+	// Work out the starting score of each player from the current score and all visits which were not busts
+	scores := make(map[int]int)
+	for _, playerID := range leg.Players {
+		scores[playerID] = players[playerID].CurrentScore
+	}
+	for _, visit := range leg.Visits {
+		if !visit.IsBust {
+			scores[visit.PlayerID] += visit.GetScore()
+		}
+	}
+	state := &models.LiveState{Players: leg.Players, Scores: scores, CurrentPlayerID: leg.Players[0], StartingScores: make(map[int]int),
+		LegsWon: wins, WinsRequired: match.MatchMode.WinsRequired, LegsRequired: match.MatchMode.LegsRequired}
+	for playerID, score := range scores {
+		state.StartingScores[playerID] = score
+	}
+
+	// Player models are only loaded if any probability is not already cached
+	var playerModels map[int]*models.LivePlayerModel
+	calculate := func(visits int, visitID int) (*models.LiveProbability, error) {
+		if probability, ok := liveProbabilityCache.Get(legID, visits, state); ok {
+			return probability, nil
+		}
+		if playerModels == nil {
+			playerModels, err = getLivePlayerModels(leg.Players...)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rng := rand.New(rand.NewSource(models.GetLiveProbabilitySeed(legID, visits)))
+		probability := models.CalculateLiveProbability(state, playerModels, models.LiveProbabilityIterations, rng)
+		probability.LegID = legID
+		probability.VisitID = visitID
+		liveProbabilityCache.Set(legID, visits, probability)
+		return probability, nil
+	}
+
+	probabilities := &models.LiveProbabilities{LegID: legID, MatchID: match.ID, Visits: make([]*models.LiveProbability, 0)}
+	if history || len(leg.Visits) == 0 {
+		probability, err := calculate(0, 0)
+		if err != nil {
+			return nil, err
+		}
+		probabilities.Visits = append(probabilities.Visits, probability)
+	}
+	for i, visit := range leg.Visits {
+		if !visit.IsBust {
+			state.Scores[visit.PlayerID] -= visit.GetScore()
+		}
+		state.CurrentPlayerID = getNextLivePlayer(leg.Players, visit.PlayerID)
+		if !history && i < len(leg.Visits)-1 {
+			continue
+		}
+		probability, err := calculate(i+1, visit.ID)
+		if err != nil {
+			return nil, err
+		}
+		probabilities.Visits = append(probabilities.Visits, probability)
+	}
+	probabilities.Current = probabilities.Visits[len(probabilities.Visits)-1]
+	if !history {
+		probabilities.Visits = nil
+	}
+	return probabilities, nil
+}
+
+// getLivePlayerModels will return the scoring and checkout distributions of the given players, based on X01 legs from the last year
+func getLivePlayerModels(playerIDs ...int) (map[int]*models.LivePlayerModel, error) {
+	q, args, err := sqlx.In(`
+		SELECT
+			s.leg_id, s.player_id,
+			l.starting_score + IF(m.match_type_id = 3, IFNULL(p2l.handicap, 0), 0) AS 'starting_score',
+			s.first_dart, s.first_dart_multiplier,
+			s.second_dart, s.second_dart_multiplier,
+			s.third_dart, s.third_dart_multiplier,
+			s.is_bust
+		FROM score s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+			JOIN player2leg p2l ON p2l.leg_id = s.leg_id AND p2l.player_id = s.player_id
+		WHERE s.player_id IN (?) AND l.is_finished = 1 AND m.is_abandoned = 0
+			AND IFNULL(l.leg_type_id, m.match_type_id) IN (1, 3) -- X01 and X01 Handicap
+			AND l.end_time >= DATE_SUB(NOW(), INTERVAL 1 YEAR)
+		ORDER BY s.leg_id, s.id`, playerIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	playerModels := make(map[int]*models.LivePlayerModel)
+	for _, playerID := range playerIDs {
+		playerModels[playerID] = models.NewLivePlayerModel(playerID)
+	}
+	remaining := make(map[[2]int]int)
+	for rows.Next() {
+		var legID, startingScore int
+		v := new(models.Visit)
+		v.FirstDart = new(models.Dart)
+		v.SecondDart = new(models.Dart)
+		v.ThirdDart = new(models.Dart)
+		err := rows.Scan(&legID, &v.PlayerID, &startingScore,
+			&v.FirstDart.Value, &v.FirstDart.Multiplier,
+			&v.SecondDart.Value, &v.SecondDart.Multiplier,
+			&v.ThirdDart.Value, &v.ThirdDart.Multiplier,
+			&v.IsBust)
+		if err != nil {
+			return nil, err
+		}
+		key := [2]int{legID, v.PlayerID}
+		if _, ok := remaining[key]; !ok {
+			remaining[key] = startingScore
+		}
+		score := v.GetScore()
+		playerModels[v.PlayerID].AddVisit(remaining[key], score, v.IsBust)
+		if !v.IsBust {
+			remaining[key] -= score
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return playerModels, nil
+}
+
+// getNextLivePlayer will return the player throwing after the given player
+func getNextLivePlayer(players []int, playerID int) int {
+	for i, id := range players {
+		if id == playerID {
+			return players[(i+1)%len(players)]
+		}
+	}
+	return players[0]
+}
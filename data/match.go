@@ -18,6 +18,18 @@ func NewMatch(match models.Match) (*models.Match, error) {
 	if err != nil {
 		return nil, err
 	}
+	matchID, err := insertMatch(tx, match)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	log.Printf("Started new match %d", matchID)
+	return GetMatch(int(matchID))
+}
+
+// insertMatch will insert a new match with the first leg and players using the given transaction
+func insertMatch(tx *sql.Tx, match models.Match) (int64, error) {
 	if match.CreatedAt.IsZero() {
 		match.CreatedAt = time.Now().UTC()
 	}
@@ -25,25 +37,21 @@ func NewMatch(match models.Match) (*models.Match, error) {
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 		match.MatchType.ID, match.MatchMode.ID, match.OweTypeID, match.VenueID, match.OfficeID, match.IsPractice, match.TournamentID, match.CreatedAt)
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return 0, err
 	}
 	matchID, err := res.LastInsertId()
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return 0, err
 	}
 	startingScore := match.Legs[0].StartingScore
 	res, err = tx.Exec("INSERT INTO leg (starting_score, current_player_id, match_id, num_players, created_at) VALUES (?, ?, ?, ?, ?)",
 		match.Legs[0].StartingScore, match.Players[0], matchID, len(match.Players), match.CreatedAt)
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return 0, err
 	}
 	legID, err := res.LastInsertId()
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return 0, err
 	}
 	if match.MatchType.ID == models.X01 || match.MatchType.ID == models.X01HANDICAP {
 		params := match.Legs[0].Parameters
@@ -53,8 +61,7 @@ func NewMatch(match models.Match) (*models.Match, error) {
 		}
 		_, err = tx.Exec("INSERT INTO leg_parameters (leg_id, outshot_type_id) VALUES (?, ?)", legID, outshotType)
 		if err != nil {
-			tx.Rollback()
-			return nil, err
+			return 0, err
 		}
 	} else if match.MatchType.ID == models.TICTACTOE {
 		params := match.Legs[0].Parameters
@@ -62,15 +69,13 @@ func NewMatch(match models.Match) (*models.Match, error) {
 		_, err = tx.Exec("INSERT INTO leg_parameters (leg_id, outshot_type_id, number_1, number_2, number_3, number_4, number_5, number_6, number_7, number_8, number_9) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 			legID, params.OutshotType.ID, params.Numbers[0], params.Numbers[1], params.Numbers[2], params.Numbers[3], params.Numbers[4], params.Numbers[5], params.Numbers[6], params.Numbers[7], params.Numbers[8])
 		if err != nil {
-			tx.Rollback()
-			return nil, err
+			return 0, err
 		}
 	} else if match.MatchType.ID == models.KNOCKOUT {
 		params := match.Legs[0].Parameters
 		_, err = tx.Exec("INSERT INTO leg_parameters (leg_id, starting_lives) VALUES (?, ?)", legID, params.StartingLives)
 		if err != nil {
-			tx.Rollback()
-			return nil, err
+			return 0, err
 		}
 	}
 
@@ -80,33 +85,27 @@ func NewMatch(match models.Match) (*models.Match, error) {
 		res, err = tx.Exec("INSERT INTO player2leg (player_id, leg_id, `order`, match_id, handicap) VALUES (?, ?, ?, ?, ?)",
 			playerID, legID, order, matchID, match.PlayerHandicaps[playerID])
 		if err != nil {
-			tx.Rollback()
-			return nil, err
+			return 0, err
 		}
 		if config, ok := match.BotPlayerConfig[playerID]; ok {
 			if config.Skill.ValueOrZero() == 0 {
 				_, err = GetRandomLegForPlayer(playerID, startingScore)
 				if err != nil {
-					tx.Rollback()
-					return nil, &models.MatchConfigError{Err: errors.New("no leg to use when configuring mock bot")}
+					return 0, &models.MatchConfigError{Err: errors.New("no leg to use when configuring mock bot")}
 				}
 			}
 			player2LegID, err := res.LastInsertId()
 			if err != nil {
-				tx.Rollback()
-				return nil, err
+				return 0, err
 			}
 			_, err = tx.Exec("INSERT INTO bot2player2leg (player2leg_id, player_id, skill_level) VALUES (?, ?, ?)", player2LegID, config.PlayerID, config.Skill)
 			if err != nil {
-				tx.Rollback()
-				return nil, err
+				return 0, err
 			}
 		}
 
 	}
-	tx.Commit()
-	log.Printf("Started new match %d", matchID)
-	return GetMatch(int(matchID))
+	return matchID, nil
 }
 
 // GetMatches returns all matches
@@ -231,14 +230,14 @@ func GetActiveMatches() ([]*models.Match, error) {
 }
 
 // GetMatchProbabilities will return single match for given id with winning probabilities for players
-func GetMatchProbabilities(id int) (*models.Probability, error) {
+func GetMatchProbabilities(id int, system string) (*models.Probability, error) {
 	rows, err := models.DB.Query(`
 		SELECT
 			m.id, m.created_at, m.updated_at, IF(TIMEDIFF(MAX(l.updated_at), NOW() - INTERVAL 15 MINUTE) > 0, 1, 0) AS 'is_started',
 			m.is_finished, m.is_abandoned, m.is_walkover, m.winner_id,
 			GROUP_CONCAT(DISTINCT p2l.player_id ORDER BY p2l.order) AS 'players',
 			GROUP_CONCAT(DISTINCT pe.current_elo ORDER BY p2l.order) AS 'elos',
-			mm.is_draw_possible
+			mm.is_draw_possible, m.match_type_id
 		FROM matches m
 			JOIN player2leg p2l ON p2l.match_id = m.id
 			LEFT JOIN leg l ON l.match_id = m.id
@@ -257,8 +256,9 @@ func GetMatchProbabilities(id int) (*models.Probability, error) {
 		var players string
 		var elos string
 		var isDrawPossible bool
+		var matchTypeID int
 		err := rows.Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt, &p.IsStarted, &p.IsFinished, &p.IsAbandoned, &p.IsWalkover, &p.WinnerID,
-			&players, &elos, &isDrawPossible)
+			&players, &elos, &isDrawPossible, &matchTypeID)
 		if err != nil {
 			return nil, err
 		}
@@ -273,9 +273,10 @@ func GetMatchProbabilities(id int) (*models.Probability, error) {
 			p.Players[1]: playerElos[1],
 		}
 
-		pHome := GetPlayerWinProbability(playerElos[0], playerElos[1])
-		pAway := GetPlayerWinProbability(playerElos[1], playerElos[0])
-		probDraw := GetPlayerDrawProbability(playerElos[0], playerElos[1])
+		pHome, pAway, probDraw, err := getWinProbabilities(p, playerElos, system, matchTypeID)
+		if err != nil {
+			return nil, err
+		}
 
 		if isDrawPossible {
 			pHome = pHome * (1 - probDraw)
@@ -516,11 +517,21 @@ func SetScore(matchID int, result models.MatchResult) (*models.Match, error) {
 	}
 	tx.Commit()
 
-	// Update Elo for players if match is finished
+	// Update Elo and Glicko-2 ratings for players if match is finished
 	err = UpdateEloForMatch(matchID)
 	if err != nil {
 		return nil, err
 	}
+	// Glicko-2 and match type Elo are secondary ratings, so a failure is logged instead of stopping tournament advancement
+	err = UpdateGlickoForMatch(matchID)
+	if err != nil {
+		log.Printf("Unable to update Glicko-2 ratings for match %d: %s", matchID, err)
+	}
+	err = UpdateMatchTypeEloForMatch(matchID)
+	if err != nil {
+		log.Printf("Unable to update match type Elo for match %d: %s", matchID, err)
+	}
+	// Match badges are not checked, since the legs of a match with only the score set have no visits
 
 	if match.TournamentID.Valid {
 		metadata, err := GetMatchMetadata(matchID)
@@ -0,0 +1,152 @@
+package data
+
+import (
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// GetMatchmaking will return suggested pairings for the given players, or players recently at the given venue.
+// If a player is given, a ranked list of opponents for that player is also returned
+func GetMatchmaking(input *models.MatchmakingInput) (*models.Matchmaking, error) {
+	if input.MatchTypeID == 0 {
+		input.MatchTypeID = models.X01
+	}
+	players := input.Players
+	if len(players) == 0 {
+		recent, err := GetRecentPlayers(int(input.VenueID.Int64))
+		if err != nil {
+			return nil, err
+		}
+		players = recent
+	}
+	if input.PlayerID.Valid && !containsPlayer(players, int(input.PlayerID.Int64)) {
+		players = append(players, int(input.PlayerID.Int64))
+	}
+	matchmaking := &models.Matchmaking{MatchTypeID: input.MatchTypeID, Players: players, Pairings: make([]*models.MatchmakingCandidate, 0)}
+	if len(players) == 0 {
+		return matchmaking, nil
+	}
+
+	elos, err := getMatchmakingElos(input.MatchTypeID, players)
+	if err != nil {
+		return nil, err
+	}
+	lastMet, err := getLastMeetings(input.MatchTypeID, players)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	candidates := make([]*models.MatchmakingCandidate, 0)
+	for i, player1 := range players {
+		for _, player2 := range players[i+1:] {
+			candidate := &models.MatchmakingCandidate{Players: []int{player1, player2},
+				Ratings:        map[int]int{player1: elos[player1], player2: elos[player2]},
+				WinProbability: GetPlayerWinProbability(elos[player1], elos[player2]),
+				LastMet:        lastMet[getMatchmakingKey(player1, player2)]}
+			candidate.SetScore(now)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if input.PlayerID.Valid {
+		playerID := int(input.PlayerID.Int64)
+		matchmaking.Opponents = make([]*models.MatchmakingCandidate, 0)
+		for _, candidate := range models.SortMatchmakingCandidates(candidates) {
+			if candidate.Players[1] == playerID {
+				candidate.Players = []int{playerID, candidate.Players[0]}
+				candidate.WinProbability = 1 - candidate.WinProbability
+			}
+			if candidate.Players[0] == playerID {
+				matchmaking.Opponents = append(matchmaking.Opponents, candidate)
+			}
+		}
+	}
+
+	pairings, unpaired := models.GetMatchmakingPairings(players, candidates)
+	matchmaking.Pairings = pairings
+	if len(unpaired) == 1 {
+		matchmaking.Fill = models.GetMatchmakingFill(unpaired[0], elos[unpaired[0]], candidates)
+	}
+	return matchmaking, nil
+}
+
+// getMatchmakingElos will return the Elo of each player for the given match type
+func getMatchmakingElos(matchTypeID int, players []int) (map[int]int, error) {
+	elos := make(map[int]int)
+	if matchTypeID != models.X01 {
+		typeElos, err := GetPlayersMatchTypeElo(matchTypeID, players...)
+		if err != nil {
+			return nil, err
+		}
+		for playerID, elo := range typeElos {
+			elos[playerID] = elo.Elo
+		}
+		return elos, nil
+	}
+	for _, playerID := range players {
+		elos[playerID] = models.EloDefault
+	}
+	playerElos, err := GetPlayersElo(players...)
+	if err != nil {
+		return nil, err
+	}
+	for _, elo := range playerElos {
+		elos[elo.PlayerID] = elo.CurrentElo
+	}
+	return elos, nil
+}
+
+// getLastMeetings will return the time of the last finished match of the given type between each pair of the given players
+func getLastMeetings(matchTypeID int, players []int) (map[[2]int]null.Time, error) {
+	q, args, err := sqlx.In(`
+		SELECT a.player_id, b.player_id, MAX(m.updated_at)
+		FROM (SELECT DISTINCT match_id, player_id FROM player2leg WHERE player_id IN (?)) a
+			JOIN (SELECT DISTINCT match_id, player_id FROM player2leg WHERE player_id IN (?)) b
+				ON b.match_id = a.match_id AND b.player_id > a.player_id
+			JOIN matches m ON m.id = a.match_id
+		WHERE m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0 AND m.match_type_id = ?
+		GROUP BY a.player_id, b.player_id`, players, players, matchTypeID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastMet := make(map[[2]int]null.Time)
+	for rows.Next() {
+		var player1, player2 int
+		var met null.Time
+		err := rows.Scan(&player1, &player2, &met)
+		if err != nil {
+			return nil, err
+		}
+		lastMet[getMatchmakingKey(player1, player2)] = met
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return lastMet, nil
+}
+
+func getMatchmakingKey(player1 int, player2 int) [2]int {
+	key := []int{player1, player2}
+	sort.Ints(key)
+	return [2]int{key[0], key[1]}
+}
+
+func containsPlayer(players []int, playerID int) bool {
+	for _, id := range players {
+		if id == playerID {
+			return true
+		}
+	}
+	return false
+}
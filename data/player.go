@@ -1080,6 +1080,16 @@ func GetPlayerHeadToHead(player1 int, player2 int) (*models.StatisticsHead2Head,
 	}
 	head2head.PlayerElos = playerElos
 
+	clutch := make(map[int]*models.StatisticsClutch)
+	for i, playerID := range playerIDs {
+		stats, err := GetPlayerClutchStatisticsAgainst(playerID, playerIDs[1-i])
+		if err != nil {
+			return nil, err
+		}
+		clutch[playerID] = stats
+	}
+	head2head.PlayerClutch = clutch
+
 	return head2head, nil
 }
 
@@ -1107,7 +1117,16 @@ func UpdateEloForMatch(matchID int) error {
 	if err != nil {
 		return err
 	}
+	calculateEloForMatch(match, wins, p1, p2)
+	err = updateElo(matchID, p1, p2)
+	if err != nil {
+		return err
+	}
+	return nil
+}
 
+// calculateEloForMatch will set the new Elo of both players based on the number of legs won by each player
+func calculateEloForMatch(match *models.Match, wins map[int]int, p1 *models.PlayerElo, p2 *models.PlayerElo) {
 	// Calculate elo for winner and looser
 	p1.CurrentEloNew, p2.CurrentEloNew = CalculateElo(p1.CurrentElo, p1.CurrentEloMatches, wins[p1.PlayerID], p2.CurrentElo,
 		p2.CurrentEloMatches, wins[p2.PlayerID])
@@ -1121,11 +1140,6 @@ func UpdateEloForMatch(matchID int) error {
 		p1.TournamentEloMatches++
 		p2.TournamentEloMatches++
 	}
-	err = updateElo(matchID, p1, p2)
-	if err != nil {
-		return err
-	}
-	return nil
 }
 
 // GetPlayersElo will get the Elo for the given player IDs
@@ -1156,6 +1170,7 @@ func GetPlayersElo(playerIDs ...int) ([]*models.PlayerElo, error) {
 		if err != nil {
 			return nil, err
 		}
+		p.IsProvisional = models.EloSettings.IsProvisional(p.CurrentEloMatches)
 		players = append(players, p)
 	}
 	if err = rows.Err(); err != nil {
@@ -1280,11 +1295,45 @@ func calculateElo(winnerElo int, winnerMatches int, looserElo int, looserMatches
 	return calculatedWinner, calculatedLooser
 }
 
+// getWinProbabilities will return the probability of the home player winning, the away player winning, and a draw, using the given rating system.
+// For Elo, matches which are not X01 use the Elo of the match type. The ratings used are added to the given probability
+func getWinProbabilities(p *models.Probability, elos []int, system string, matchTypeID int) (float64, float64, float64, error) {
+	p.RatingSystem = system
+	if system != models.RatingSystemGlicko {
+		if matchTypeID != models.X01 {
+			typeElos, err := GetPlayersMatchTypeElo(matchTypeID, p.Players[0], p.Players[1])
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			elos = []int{typeElos[p.Players[0]].Elo, typeElos[p.Players[1]].Elo}
+			p.Elos = map[int]int{
+				p.Players[0]: elos[0],
+				p.Players[1]: elos[1],
+			}
+		}
+		return GetPlayerWinProbability(elos[0], elos[1]), GetPlayerWinProbability(elos[1], elos[0]), GetPlayerDrawProbability(elos[0], elos[1]), nil
+	}
+	ratings, err := GetPlayersGlicko(p.Players[0], p.Players[1])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	home := ratings[p.Players[0]]
+	away := ratings[p.Players[1]]
+	p.Ratings = map[int]float64{
+		home.PlayerID: math.Round(home.Rating),
+		away.PlayerID: math.Round(away.Rating),
+	}
+	pHome := models.GetGlickoWinProbability(home, away)
+	return pHome, 1 - pHome, GetPlayerDrawProbability(int(home.Rating), int(away.Rating)), nil
+}
+
+// GetPlayerWinProbability will return the probability of player1 beating player2 based on their Elo
 func GetPlayerWinProbability(player1Elo int, player2Elo int) float64 {
 	// Pr(A) = 1 / (10^(-ELODIFF/400) + 1)
 	return 1 / (math.Pow(10, float64(-(player1Elo-player2Elo))/400) + 1)
 }
 
+// GetPlayerDrawProbability will return the probability of a draw between two players based on their Elo
 func GetPlayerDrawProbability(player1Elo int, player2Elo int) float64 {
 	// Quants Magic using Binomial Regression and Elos from 800 matches
 	// Caveat: Model won´t be accurate for extreme cases (Elo Diff >500)
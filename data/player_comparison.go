@@ -0,0 +1,270 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// GetPlayersComparison will compare the given players across all match types. If versus is set, statistics for every
+// match type only include legs the players played against each other, and their results against each other are included
+func GetPlayersComparison(ids []int, versus bool) (*models.PlayerComparison, error) {
+	comparison := &models.PlayerComparison{
+		Players:    ids,
+		Statistics: make(map[int]map[int]interface{}),
+		Winners:    make(map[int]map[string][]int),
+	}
+	var versusIDs []int
+	if versus {
+		versusIDs = ids
+	}
+
+	for matchType := range models.MatchTypes {
+		statistics := make(map[int]interface{})
+		legsPlayed := make(map[int]int)
+		played := false
+		for _, id := range ids {
+			stats, legs, err := GetPlayerStatisticsForMatchType(id, matchType, versusIDs)
+			if err != nil {
+				return nil, err
+			}
+			if legs > 0 {
+				played = true
+			}
+			statistics[id] = stats
+			legsPlayed[id] = legs
+		}
+		if !played {
+			continue
+		}
+		winners, err := models.GetMetricWinners(statistics, legsPlayed)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Statistics[matchType] = statistics
+		comparison.Winners[matchType] = winners
+	}
+
+	opponents, err := getPlayersOpponentRecords(ids)
+	if err != nil {
+		return nil, err
+	}
+	comparison.SharedOpponents = models.GetSharedOpponents(opponents, ids)
+
+	if versus {
+		comparison.Versus, err = getPlayersVersusRecords(ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return comparison, nil
+}
+
+// GetPlayerStatisticsForMatchType will return statistics for the given player and match type, and the number of legs played.
+// If versus players are given, only legs where all of them played against each other are included
+func GetPlayerStatisticsForMatchType(id int, matchType int, versus []int) (interface{}, int, error) {
+	switch matchType {
+	case models.X01, models.X01HANDICAP:
+		stats, err := GetX01StatisticsForPlayer(id, matchType, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.SHOOTOUT:
+		stats, err := GetShootoutStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.CRICKET:
+		stats, err := GetCricketStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.DARTSATX:
+		stats, err := GetDartsAtXStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.AROUNDTHEWORLD:
+		stats, err := GetAroundTheWorldStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.SHANGHAI:
+		stats, err := GetShanghaiStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.AROUNDTHECLOCK:
+		stats, err := GetAroundTheClockStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.TICTACTOE:
+		stats, err := GetTicTacToeStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.BERMUDATRIANGLE:
+		stats, err := GetBermudaTriangleStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.FOURTWENTY:
+		stats, err := Get420StatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.KILLBULL:
+		stats, err := GetKillBullStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.GOTCHA:
+		stats, err := GetGotchaStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.JDCPRACTICE:
+		stats, err := GetJDCPracticeStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.KNOCKOUT:
+		stats, err := GetKnockoutStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	case models.SCAM:
+		stats, err := GetScamStatisticsForPlayer(id, versus)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stats, stats.LegsPlayed, nil
+	}
+	return nil, 0, errors.New("unknown match type")
+}
+
+// getPlayersOpponentRecords will return results in two player matches for each of the given players against all their opponents
+func getPlayersOpponentRecords(ids []int) (map[int]map[int]*models.PlayerRecord, error) {
+	q, args, err := sqlx.In(`
+		SELECT
+			opp.player_id AS 'opponent_id',
+			p2l.player_id,
+			COUNT(DISTINCT m.id) AS 'matches_played',
+			COUNT(DISTINCT won.id) AS 'matches_won',
+			COUNT(DISTINCT p2l.leg_id) AS 'legs_played',
+			COUNT(DISTINCT legs_won.id) AS 'legs_won'
+		FROM player2leg p2l
+			JOIN matches m ON m.id = p2l.match_id
+			JOIN player2leg opp ON opp.leg_id = p2l.leg_id AND opp.player_id <> p2l.player_id
+			LEFT JOIN matches won ON won.id = m.id AND won.winner_id = p2l.player_id
+			LEFT JOIN leg legs_won ON legs_won.id = p2l.leg_id AND legs_won.winner_id = p2l.player_id
+		WHERE p2l.player_id IN (?) AND opp.player_id NOT IN (?)
+			AND m.id IN (SELECT match_id FROM player2leg GROUP BY match_id HAVING COUNT(DISTINCT player_id) = 2)
+			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0 AND m.is_practice = 0
+		GROUP BY opp.player_id, p2l.player_id`, ids, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make(map[int]map[int]*models.PlayerRecord)
+	for rows.Next() {
+		var opponentID, playerID int
+		record := new(models.PlayerRecord)
+		err := rows.Scan(&opponentID, &playerID, &record.MatchesPlayed, &record.MatchesWon, &record.LegsPlayed, &record.LegsWon)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := records[opponentID]; !ok {
+			records[opponentID] = make(map[int]*models.PlayerRecord)
+		}
+		records[opponentID][playerID] = record
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// getPlayersVersusRecords will return results per match type for matches where all the given players played against each other
+func getPlayersVersusRecords(ids []int) (map[int]map[int]*models.PlayerRecord, error) {
+	q, args, err := sqlx.In(`
+		SELECT
+			m.match_type_id,
+			p2l.player_id,
+			COUNT(DISTINCT m.id) AS 'matches_played',
+			COUNT(DISTINCT won.id) AS 'matches_won',
+			COUNT(DISTINCT p2l.leg_id) AS 'legs_played',
+			COUNT(DISTINCT legs_won.id) AS 'legs_won'
+		FROM player2leg p2l
+			JOIN matches m ON m.id = p2l.match_id
+			LEFT JOIN matches won ON won.id = m.id AND won.winner_id = p2l.player_id
+			LEFT JOIN leg legs_won ON legs_won.id = p2l.leg_id AND legs_won.winner_id = p2l.player_id
+		WHERE p2l.player_id IN (?)
+			AND m.id IN (SELECT match_id FROM player2leg WHERE player_id IN (?) GROUP BY match_id HAVING COUNT(DISTINCT player_id) = ?)
+			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+		GROUP BY m.match_type_id, p2l.player_id`, ids, ids, len(ids))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make(map[int]map[int]*models.PlayerRecord)
+	for rows.Next() {
+		var matchType, playerID int
+		record := new(models.PlayerRecord)
+		err := rows.Scan(&matchType, &playerID, &record.MatchesPlayed, &record.MatchesWon, &record.LegsPlayed, &record.LegsWon)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := records[matchType]; !ok {
+			records[matchType] = make(map[int]*models.PlayerRecord)
+		}
+		records[matchType][playerID] = record
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// versusLegs will return a condition restricting legs to those where all the given players played against each other,
+// or no condition if no players are given
+func versusLegs(ids []int) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	players := make([]string, len(ids))
+	for i, id := range ids {
+		players[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf(` AND l.id IN (SELECT leg_id FROM player2leg WHERE player_id IN (%s) GROUP BY leg_id HAVING COUNT(DISTINCT player_id) = %d)`,
+		strings.Join(players, ","), len(ids))
+}
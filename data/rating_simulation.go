@@ -0,0 +1,33 @@
+package data
+
+import (
+	"github.com/kcapp/api/models"
+)
+
+// SimulateRatings will apply the given hypothetical results in order, and return the projected ratings and rankings of all affected players
+func SimulateRatings(input *models.RatingSimulationInput) (*models.RatingSimulation, error) {
+	players := input.GetPlayers()
+	playerElos, err := GetPlayersElo(players...)
+	if err != nil {
+		return nil, err
+	}
+	elos := make(map[int]*models.PlayerElo)
+	for _, elo := range playerElos {
+		elos[elo.PlayerID] = elo
+	}
+	simulation := models.SimulateRatings(input, elos, CalculateElo)
+
+	standings, err := GetTournamentStandings()
+	if err != nil {
+		return nil, err
+	}
+	leaderboard := make(map[int]int)
+	for _, standing := range standings {
+		leaderboard[standing.PlayerID] = standing.CurrentElo
+		if input.Rating == models.SimulationRatingTournament {
+			leaderboard[standing.PlayerID] = standing.Elo
+		}
+	}
+	simulation.SetRanks(leaderboard)
+	return simulation, nil
+}
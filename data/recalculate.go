@@ -5,8 +5,9 @@ import (
 	"log"
 	"os"
 	"sort"
-	"strings"
+	"strconv"
 	"text/tabwriter"
+	"time"
 
 	"github.com/guregu/null"
 	"github.com/kcapp/api/models"
@@ -72,7 +73,11 @@ func RecalculateStatistics(matchType int, legID int, since string, dryRun bool)
 	if err != nil {
 		return err
 	}
+	return executeRecalculateQueries(queries, dryRun)
+}
 
+// executeRecalculateQueries will execute the given queries in a single transaction, or print them if dry-run is enabled
+func executeRecalculateQueries(queries []string, dryRun bool) error {
 	if len(queries) == 0 {
 		log.Print("No legs to recalculate")
 	} else {
@@ -101,27 +106,10 @@ func RecalculateStatistics(matchType int, legID int, since string, dryRun bool)
 
 // RecalculateElo will recalculate Elo for all players
 func RecalculateElo(dryRun bool) error {
-	rows, err := models.DB.Query(`
-		SELECT id FROM matches
-		WHERE is_finished = 1 AND is_practice = 0 AND is_abandoned = 0 AND match_type_id = 1
-		ORDER BY updated_at`)
+	matches, err := getEloMatches()
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-
-	matches := make([]int, 0)
-	for rows.Next() {
-		var id int
-		err := rows.Scan(&id)
-		if err != nil {
-			return err
-		}
-		matches = append(matches, id)
-	}
-	if err = rows.Err(); err != nil {
-		return err
-	}
 	if dryRun {
 		log.Print("Elo not reset because dry-run is enabled")
 	} else {
@@ -133,6 +121,7 @@ func RecalculateElo(dryRun bool) error {
 		// Reset the Elo for all players back to initial values
 		tx.Exec(`UPDATE player_elo SET current_elo = 1500, current_elo_matches = 0, tournament_elo = 1500, tournament_elo_matches = 0;`)
 		tx.Exec(`DELETE FROM player_elo_changelog;`)
+		tx.Exec(`DELETE FROM player_elo_decay;`)
 		tx.Commit()
 
 		for _, id := range matches {
@@ -146,6 +135,329 @@ func RecalculateElo(dryRun bool) error {
 	return nil
 }
 
+// eloChange struct used for storing the Elo change of a single player in a replayed match
+type eloChange struct {
+	matchID  int
+	player   models.PlayerElo
+	playedAt time.Time
+}
+
+// eloDecayChange struct used for storing a decay of a single player which is replayed
+type eloDecayChange struct {
+	id         int
+	playerID   int
+	oldElo     int
+	newElo     int
+	officeMean int
+	createdAt  time.Time
+}
+
+// RecalculateEloSince will restore the Elo of all players as of the given match ID or date (YYYY-MM-DD) from the Elo changelog,
+// and replay only the matches finished after that point. Matches are rewound and replayed in the order they were finished,
+// and decays after that point are replayed between them toward the same office mean. Changed ratings are printed before
+// anything is committed
+func RecalculateEloSince(since string, dryRun bool) error {
+	cutoff, err := getEloCutoff(since)
+	if err != nil {
+		return err
+	}
+	ids, err := getEloMatchesSince(cutoff)
+	if err != nil {
+		return err
+	}
+	restore, err := getEloChangelogSince(cutoff)
+	if err != nil {
+		return err
+	}
+	decays, err := getEloDecaysSince(cutoff)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 && len(restore) == 0 && len(decays) == 0 {
+		log.Printf("No matches to recalculate since %s", cutoff.Format("2006-01-02 15:04:05"))
+		return nil
+	}
+
+	matches := make([]*models.Match, 0)
+	playerIDs := make(map[int]bool)
+	for _, id := range ids {
+		match, err := GetMatch(id)
+		if err != nil {
+			return err
+		}
+		if len(match.Players) != 2 || match.IsWalkover {
+			continue
+		}
+		matches = append(matches, match)
+		for _, playerID := range match.Players {
+			playerIDs[playerID] = true
+		}
+	}
+	for _, change := range restore {
+		playerIDs[change.player.PlayerID] = true
+	}
+	for _, decay := range decays {
+		playerIDs[decay.playerID] = true
+	}
+	players := make([]int, 0)
+	for playerID := range playerIDs {
+		players = append(players, playerID)
+	}
+	sort.Ints(players)
+	before, err := GetPlayersElo(players...)
+	if err != nil {
+		return err
+	}
+
+	// Restore the Elo each player had before their first match or decay after the cutoff
+	elos := make(map[int]*models.PlayerElo)
+	for _, elo := range before {
+		restored := *elo
+		elos[elo.PlayerID] = &restored
+	}
+	restoredElo := make(map[int]time.Time)
+	restoredTournamentElo := make(map[int]bool)
+	for _, change := range restore {
+		elo, ok := elos[change.player.PlayerID]
+		if !ok {
+			continue
+		}
+		if _, ok := restoredElo[elo.PlayerID]; !ok {
+			elo.CurrentElo = change.player.CurrentElo
+			restoredElo[elo.PlayerID] = change.playedAt
+		}
+		elo.CurrentEloMatches--
+		if change.player.TournamentElo.Valid {
+			if !restoredTournamentElo[elo.PlayerID] {
+				elo.TournamentElo = change.player.TournamentElo
+				restoredTournamentElo[elo.PlayerID] = true
+			}
+			elo.TournamentEloMatches--
+		}
+	}
+
+	for _, decay := range decays {
+		elo, ok := elos[decay.playerID]
+		if !ok {
+			continue
+		}
+		if restoredAt, ok := restoredElo[elo.PlayerID]; !ok || decay.createdAt.Before(restoredAt) {
+			elo.CurrentElo = decay.oldElo
+			restoredElo[elo.PlayerID] = decay.createdAt
+		}
+	}
+
+	// Replay all matches and decays after the cutoff
+	changes := make([]*eloChange, 0)
+	replayDecay := func(decay *eloDecayChange) {
+		elo, ok := elos[decay.playerID]
+		if !ok {
+			return
+		}
+		decay.oldElo = elo.CurrentElo
+		decay.newElo = models.EloSettings.CalculateEloDecay(elo.CurrentElo, decay.officeMean)
+		elo.CurrentElo = decay.newElo
+	}
+	next := 0
+	for _, match := range matches {
+		for ; next < len(decays) && decays[next].createdAt.Before(match.UpdatedAt); next++ {
+			replayDecay(decays[next])
+		}
+		p1, ok1 := elos[match.Players[0]]
+		p2, ok2 := elos[match.Players[1]]
+		if !ok1 || !ok2 {
+			continue
+		}
+		wins, err := GetWinsPerPlayer(match.ID)
+		if err != nil {
+			return err
+		}
+		calculateEloForMatch(match, wins, p1, p2)
+		for _, elo := range []*models.PlayerElo{p1, p2} {
+			changes = append(changes, &eloChange{matchID: match.ID, player: *elo})
+			elo.CurrentElo = elo.CurrentEloNew
+			elo.CurrentEloNew = 0
+			if elo.TournamentEloNew.Valid {
+				elo.TournamentElo = elo.TournamentEloNew
+				elo.TournamentEloNew = null.Int{}
+			}
+		}
+	}
+
+	for ; next < len(decays); next++ {
+		replayDecay(decays[next])
+	}
+
+	log.Printf("Replayed %d matches and %d decays since %s, with the following changes in final ratings:", len(matches), len(decays), cutoff.Format("2006-01-02 15:04:05"))
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	fmt.Fprintln(w, "ID\tElo\tNew Elo\tTournament Elo\tNew Tournament Elo\tMatches\tNew Matches")
+	changed := 0
+	for _, elo := range before {
+		after := elos[elo.PlayerID]
+		if elo.CurrentElo == after.CurrentElo && elo.TournamentElo == after.TournamentElo && elo.CurrentEloMatches == after.CurrentEloMatches {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%d\n", elo.PlayerID, elo.CurrentElo, after.CurrentElo, elo.TournamentElo.Int64,
+			after.TournamentElo.Int64, elo.CurrentEloMatches, after.CurrentEloMatches)
+		changed++
+	}
+	w.Flush()
+	log.Printf("%d players changed", changed)
+	if dryRun {
+		log.Print("Elo not updated because dry-run is enabled")
+		return nil
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		DELETE pec FROM player_elo_changelog pec
+			JOIN matches m ON m.id = pec.match_id
+		WHERE m.updated_at >= ?`, cutoff)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, change := range changes {
+		var tournamentElo *int64
+		var tournamentEloNew *int64
+		if change.player.TournamentEloNew.Valid {
+			tournamentElo = &change.player.TournamentElo.Int64
+			tournamentEloNew = &change.player.TournamentEloNew.Int64
+		}
+		_, err = tx.Exec(`INSERT INTO player_elo_changelog (match_id, player_id, old_elo, new_elo, old_tournament_elo, new_tournament_elo) VALUES (?, ?, ?, ?, ?, ?)`,
+			change.matchID, change.player.PlayerID, change.player.CurrentElo, change.player.CurrentEloNew, tournamentElo, tournamentEloNew)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, elo := range elos {
+		_, err = tx.Exec(`UPDATE player_elo SET current_elo = ?, current_elo_matches = ?, tournament_elo = ?, tournament_elo_matches = ? WHERE player_id = ?`,
+			elo.CurrentElo, elo.CurrentEloMatches, elo.TournamentElo, elo.TournamentEloMatches, elo.PlayerID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, decay := range decays {
+		_, err = tx.Exec("UPDATE player_elo_decay SET old_elo = ?, new_elo = ? WHERE id = ?", decay.oldElo, decay.newElo, decay.id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	tx.Commit()
+	log.Printf("Updated Elo for %d players", len(elos))
+	return nil
+}
+
+// getEloCutoff will return the point in time for the given match ID or date (YYYY-MM-DD)
+func getEloCutoff(since string) (time.Time, error) {
+	if matchID, err := strconv.Atoi(since); err == nil {
+		var cutoff time.Time
+		err = models.DB.QueryRow("SELECT updated_at FROM matches WHERE id = ?", matchID).Scan(&cutoff)
+		if err != nil {
+			return cutoff, fmt.Errorf("unable to find match %d: %s", matchID, err)
+		}
+		return cutoff, nil
+	}
+	cutoff, err := time.Parse("2006-01-02", since)
+	if err != nil {
+		return cutoff, fmt.Errorf("since must be a match ID or a date in the format YYYY-MM-DD")
+	}
+	return cutoff, nil
+}
+
+// getEloChangelogSince will return all Elo changelog entries for matches finished since the given time, with the ratings
+// before each match, in the order the matches were finished
+func getEloChangelogSince(since time.Time) ([]*eloChange, error) {
+	rows, err := models.DB.Query(`
+		SELECT pec.match_id, pec.player_id, pec.old_elo, pec.old_tournament_elo, m.updated_at
+		FROM player_elo_changelog pec
+			JOIN matches m ON m.id = pec.match_id
+		WHERE m.updated_at >= ?
+		ORDER BY m.updated_at, m.id, pec.id`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]*eloChange, 0)
+	for rows.Next() {
+		change := new(eloChange)
+		err := rows.Scan(&change.matchID, &change.player.PlayerID, &change.player.CurrentElo, &change.player.TournamentElo, &change.playedAt)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// getEloDecaysSince will return all Elo decays since the given time, in the order they were applied
+func getEloDecaysSince(since time.Time) ([]*eloDecayChange, error) {
+	rows, err := models.DB.Query(`
+		SELECT id, player_id, old_elo, new_elo, office_mean, created_at
+		FROM player_elo_decay
+		WHERE created_at >= ?
+		ORDER BY created_at, id`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decays := make([]*eloDecayChange, 0)
+	for rows.Next() {
+		decay := new(eloDecayChange)
+		err := rows.Scan(&decay.id, &decay.playerID, &decay.oldElo, &decay.newElo, &decay.officeMean, &decay.createdAt)
+		if err != nil {
+			return nil, err
+		}
+		decays = append(decays, decay)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return decays, nil
+}
+
+// getEloMatches will return all matches which affect ratings, in the order they were finished
+func getEloMatches() ([]int, error) {
+	return getEloMatchesSince(time.Unix(0, 0))
+}
+
+// getEloMatchesSince will return all matches finished since the given time which affect ratings, in the order they were finished
+func getEloMatchesSince(since time.Time) ([]int, error) {
+	rows, err := models.DB.Query(`
+		SELECT id FROM matches
+		WHERE is_finished = 1 AND is_practice = 0 AND is_abandoned = 0 AND match_type_id = 1 AND updated_at >= ?
+		ORDER BY updated_at, id`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]int, 0)
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
 // CalculateEloForTournament will calculate a local elo for a given tournament
 func CalculateEloForTournament(tournamentID int) error {
 	players, err := GetPlayers()
@@ -158,9 +470,10 @@ func CalculateEloForTournament(tournamentID int) error {
 	}
 
 	rows, err := models.DB.Query(`
-		SELECT id FROM matches
-		WHERE tournament_id = ? AND is_finished = 1 AND is_practice = 0 AND is_abandoned = 0 AND match_type_id = 1
-		ORDER BY updated_at`, tournamentID)
+		SELECT m.id FROM matches m
+		WHERE m.tournament_id = ? AND m.is_finished = 1 AND m.is_practice = 0 AND m.is_abandoned = 0 AND m.is_walkover = 0 AND m.match_type_id = 1
+			AND NOT `+tournamentMatchAnnulled+`
+		ORDER BY m.updated_at`, tournamentID)
 	if err != nil {
 		return err
 	}
@@ -230,7 +543,8 @@ func CalculateEloForTournament(tournamentID int) error {
 	return nil
 }
 
-func RecalculateLegBadges() error {
+// RecalculateLegBadges will award leg and visit badges for all finished legs, optionally limited to the given badge IDs
+func RecalculateLegBadges(badgeIDs ...int) error {
 	ids, err := GetBadgeLegsToRecalculate()
 	if err != nil {
 		return err
@@ -249,7 +563,7 @@ func RecalculateLegBadges() error {
 		}
 
 		// Calculate badges
-		err = CheckLegForBadges(leg, statistics)
+		err = CheckLegForBadges(leg, statistics, badgeIDs...)
 		if err != nil {
 			return err
 		}
@@ -258,82 +572,69 @@ func RecalculateLegBadges() error {
 	return nil
 }
 
-func RecalculateGlobalBadges() error {
-	players, err := GetPlayers()
+// RecalculateMatchBadges will award match badges for all finished matches, optionally limited to the given badge IDs
+func RecalculateMatchBadges(badgeIDs ...int) error {
+	ids, err := GetBadgeMatchesToRecalculate()
 	if err != nil {
 		return err
 	}
-	for _, player := range players {
-		if player.IsSupporter {
-			// Add supporter badge
-			err = AddBadge(player.ID, new(models.BadgeKcappSupporter))
-			if err != nil {
-				return err
-			}
-		}
-		if player.VocalName.Valid && strings.HasSuffix(player.VocalName.String, ".wav") {
-			// Add vocal name badge
-			err = AddBadge(player.ID, new(models.BadgeSayMyName))
-			if err != nil {
-				return err
-			}
-		}
 
-		standings, err := GetPlayerTournamentStandings(player.ID)
+	for _, matchID := range ids {
+		log.Printf("Checking Match %d for badges", matchID)
+		err = CheckMatchForBadges(matchID, badgeIDs...)
 		if err != nil {
 			return err
 		}
-		if len(standings) > 0 {
-			standing := standings[len(standings)-1]
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeItsOfficial), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
-		}
-
-		standing := getPlayerTournamentStanding(1, standings)
-		if standing != nil {
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeTournament1st), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
-		}
-		standing = getPlayerTournamentStanding(2, standings)
-		if standing != nil {
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeTournament2nd), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
-		}
-		standing = getPlayerTournamentStanding(3, standings)
-		if standing != nil {
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeTournament3rd), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
-		}
 	}
 
+	return nil
+}
+
+// RecalculateGlobalBadges will award player and tournament badges to all players, optionally limited to the given badge IDs
+func RecalculateGlobalBadges(badgeIDs ...int) error {
+	players, err := GetPlayers()
+	if err != nil {
+		return err
+	}
 	undefeated, err := GetUndefeatedPlayers()
 	if err != nil {
 		return err
 	}
-	for playerID, tournament := range undefeated {
-		err := AddTournamentBadge(playerID, tournament.ID, new(models.BadgeUntouchable), tournament.EndTime.Time)
+	playerBadges := models.GetBadgeDefinitions(models.BadgeScopePlayer, badgeIDs...)
+	tournamentBadges := models.GetBadgeDefinitions(models.BadgeScopeTournament, badgeIDs...)
+	for _, player := range players {
+		facts := models.GetPlayerBadgeFacts(player)
+		for _, badge := range playerBadges {
+			if badge.Evaluate(facts) {
+				err = AddBadge(player.ID, badge.ID)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if len(tournamentBadges) == 0 {
+			continue
+		}
+
+		standings, err := GetPlayerTournamentStandings(player.ID)
 		if err != nil {
 			return err
 		}
-	}
-
-	return nil
-}
-
-func getPlayerTournamentStanding(pos int, standings []*models.PlayerTournamentStanding) *models.PlayerTournamentStanding {
-	for i := len(standings) - 1; i >= 0; i-- {
-		standing := standings[i]
-		if standing.FinalStanding == pos {
-			return standing
+		// Standings are ordered newest first, so check from the end to give each badge for the first tournament it was earned in
+		for i := len(standings) - 1; i >= 0; i-- {
+			standing := standings[i]
+			tournament, ok := undefeated[player.ID]
+			facts := models.GetTournamentBadgeFacts(standing, ok && tournament.ID == standing.Tournament.ID)
+			for _, badge := range tournamentBadges {
+				if badge.Evaluate(facts) {
+					err = AddTournamentBadge(player.ID, standing.Tournament.ID, badge.ID, standing.Tournament.EndTime.Time)
+					if err != nil {
+						return err
+					}
+				}
+			}
 		}
 	}
+
 	return nil
 }
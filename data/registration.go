@@ -0,0 +1,417 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// NewTournamentRegistration will open registration for a new tournament
+func NewTournamentRegistration(registration models.TournamentRegistration) (*models.TournamentRegistration, error) {
+	res, err := models.DB.Exec(`INSERT INTO tournament_registration (name, short_name, office_id, preset_id, manual_admin, deadline, capacity, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW())`, registration.Name, registration.ShortName, registration.OfficeID, registration.PresetID,
+		registration.ManualAdmin, registration.Deadline, registration.Capacity)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Opened registration %d for %s", id, registration.Name)
+	return GetTournamentRegistration(int(id))
+}
+
+// GetTournamentRegistrations will return all tournament registrations
+func GetTournamentRegistrations() ([]*models.TournamentRegistration, error) {
+	rows, err := models.DB.Query(`
+		SELECT id, name, short_name, office_id, preset_id, manual_admin, deadline, capacity, tournament_id, created_at
+		FROM tournament_registration
+		ORDER BY deadline DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registrations := make([]*models.TournamentRegistration, 0)
+	for rows.Next() {
+		r := new(models.TournamentRegistration)
+		err := rows.Scan(&r.ID, &r.Name, &r.ShortName, &r.OfficeID, &r.PresetID, &r.ManualAdmin, &r.Deadline, &r.Capacity, &r.TournamentID, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		registrations = append(registrations, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return registrations, nil
+}
+
+// registrationQuerier is implemented by both sql.DB and sql.Tx, so registrations can be read inside a transaction
+type registrationQuerier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// GetTournamentRegistration will return the given registration, with all registered players
+func GetTournamentRegistration(id int) (*models.TournamentRegistration, error) {
+	return getTournamentRegistration(models.DB, id, "")
+}
+
+// getTournamentRegistration will return the given registration using the given querier, optionally locking the registration row
+func getTournamentRegistration(db registrationQuerier, id int, lock string) (*models.TournamentRegistration, error) {
+	r := new(models.TournamentRegistration)
+	err := db.QueryRow(`
+		SELECT id, name, short_name, office_id, preset_id, manual_admin, deadline, capacity, tournament_id, created_at
+		FROM tournament_registration WHERE id = ? `+lock, id).
+		Scan(&r.ID, &r.Name, &r.ShortName, &r.OfficeID, &r.PresetID, &r.ManualAdmin, &r.Deadline, &r.Capacity, &r.TournamentID, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT player_id, tournament_group_id, status, registered_at, checked_in_at, withdrawn_at
+		FROM tournament_registration_player
+		WHERE registration_id = ?
+		ORDER BY registered_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r.Players = make([]*models.RegisteredPlayer, 0)
+	for rows.Next() {
+		p := new(models.RegisteredPlayer)
+		err := rows.Scan(&p.PlayerID, &p.TournamentGroupID, &p.Status, &p.RegisteredAt, &p.CheckedInAt, &p.WithdrawnAt)
+		if err != nil {
+			return nil, err
+		}
+		r.Players = append(r.Players, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	r.UpdateWaitlist()
+	return r, nil
+}
+
+// RegisterPlayer will register the given player for the tournament, or put the player on the waitlist if it is full
+func RegisterPlayer(registrationID int, playerID int, groupID null.Int) (*models.TournamentRegistration, error) {
+	err := updateRegisteredPlayers(registrationID, func(registration *models.TournamentRegistration) ([]*models.RegisteredPlayer, error) {
+		player, err := registration.Register(playerID, groupID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Player %d is %s for registration %d", playerID, player.Status, registrationID)
+		return []*models.RegisteredPlayer{player}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetTournamentRegistration(registrationID)
+}
+
+// CheckInPlayer will check in the given player on tournament day
+func CheckInPlayer(registrationID int, playerID int) (*models.TournamentRegistration, error) {
+	err := updateRegisteredPlayers(registrationID, func(registration *models.TournamentRegistration) ([]*models.RegisteredPlayer, error) {
+		err := registration.CheckIn(playerID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Player %d checked in for registration %d", playerID, registrationID)
+		return []*models.RegisteredPlayer{registration.GetPlayer(playerID)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetTournamentRegistration(registrationID)
+}
+
+// WithdrawRegisteredPlayer will withdraw the given player before the tournament starts, giving the place to the first player on the waitlist
+func WithdrawRegisteredPlayer(registrationID int, playerID int) (*models.TournamentRegistration, error) {
+	err := updateRegisteredPlayers(registrationID, func(registration *models.TournamentRegistration) ([]*models.RegisteredPlayer, error) {
+		promoted, err := registration.Withdraw(playerID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		players := []*models.RegisteredPlayer{registration.GetPlayer(playerID)}
+		if promoted != nil {
+			log.Printf("Player %d got a place from the waitlist of registration %d", promoted.PlayerID, registrationID)
+			players = append(players, promoted)
+		}
+		log.Printf("Player %d withdrew from registration %d", playerID, registrationID)
+		return players, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetTournamentRegistration(registrationID)
+}
+
+// updateRegisteredPlayers will apply the given update to the registration and save the players it returns, in a single transaction.
+// The registration row is locked while updating, so concurrent registrations can not exceed the capacity
+func updateRegisteredPlayers(registrationID int, update func(registration *models.TournamentRegistration) ([]*models.RegisteredPlayer, error)) error {
+	return models.Transaction(models.DB, func(tx *sql.Tx) error {
+		registration, err := getTournamentRegistration(tx, registrationID, "FOR UPDATE")
+		if err != nil {
+			return err
+		}
+		players, err := update(registration)
+		if err != nil {
+			return err
+		}
+		return saveRegisteredPlayers(tx, registrationID, players...)
+	})
+}
+
+// StartRegisteredTournament will close registration and generate the tournament with all checked in players.
+// The registration row is locked until the tournament is stored, so concurrent starts can not generate two tournaments
+func StartRegisteredTournament(registrationID int) (*models.Tournament, error) {
+	var tournament *models.Tournament
+	var players []*models.Player2Tournament
+	err := models.Transaction(models.DB, func(tx *sql.Tx) error {
+		registration, err := getTournamentRegistration(tx, registrationID, "FOR UPDATE")
+		if err != nil {
+			return err
+		}
+		if registration.TournamentID.Valid {
+			return errors.New("tournament has already started")
+		}
+		preset, err := GetTournamentPreset(int(registration.PresetID.Int64))
+		if err != nil {
+			return err
+		}
+		players = registration.GetCheckedInPlayers(preset.Group1TournamentGroup.ID)
+		if len(players) < 2 {
+			return errors.New("at least two players must be checked in to start the tournament")
+		}
+
+		tournament, err = GenerateTournament(models.Tournament{
+			Name:        registration.Name,
+			ShortName:   registration.ShortName,
+			OfficeID:    registration.OfficeID,
+			PresetID:    registration.PresetID,
+			ManualAdmin: registration.ManualAdmin,
+			Players:     players,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE tournament_registration SET tournament_id = ? WHERE id = ?`, tournament.ID, registrationID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Started tournament %d from registration %d with %d players", tournament.ID, registrationID, len(players))
+	return tournament, nil
+}
+
+// tournamentMatchAnnulled is the condition for a tournament match against a player whose matches were annulled when withdrawing
+const tournamentMatchAnnulled = `EXISTS (
+	SELECT 1 FROM player2leg ap2l
+		JOIN player2tournament ap2t ON ap2t.player_id = ap2l.player_id AND ap2t.tournament_id = m.tournament_id
+	WHERE ap2l.match_id = m.id AND ap2t.is_annulled = 1)`
+
+// WithdrawPlayer will withdraw the given player from a tournament which has started. Depending on the withdrawal rule of the preset,
+// the remaining matches are given to the opponents as walkovers, or all matches are annulled so no one gets points from them.
+// Tournament Elo is recalculated afterwards, and the player will no longer be seeded into playoffs
+func WithdrawPlayer(tournamentID int, playerID int) (*models.WithdrawalResult, error) {
+	tournament, err := GetTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if tournament.IsFinished {
+		return nil, errors.New("tournament is already finished")
+	}
+	matches, err := GetTournamentMatchesForPlayer(tournamentID, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholders := make(map[int]bool)
+	rule := models.WithdrawalAuto
+	if preset := tournament.Preset; preset != nil {
+		rule = preset.WithdrawalRule
+		placeholders = map[int]bool{preset.PlayerIDPlaceholderHome: true, preset.PlayerIDPlaceholderAway: true, preset.PlayerIDWalkover: true}
+	}
+	played := 0
+	total := 0
+	for _, match := range matches {
+		if match.IsAbandoned || match.IsBye {
+			continue
+		}
+		total++
+		if match.IsFinished {
+			played++
+		}
+	}
+	result := &models.WithdrawalResult{TournamentID: tournamentID, PlayerID: playerID, Rule: models.GetWithdrawalRule(rule, played, total),
+		Walkovers: make([]int, 0), Annulled: make([]int, 0)}
+	if tournament.IsPlayoffs {
+		// Matches can not be annulled in playoffs, since the winner has to move on
+		result.Rule = models.WithdrawalWalkover
+	}
+
+	err = models.Transaction(models.DB, func(tx *sql.Tx) error {
+		var isWithdrawn bool
+		err := tx.QueryRow(`SELECT is_withdrawn FROM player2tournament WHERE tournament_id = ? AND player_id = ? FOR UPDATE`,
+			tournamentID, playerID).Scan(&isWithdrawn)
+		if err != nil {
+			return err
+		}
+		if isWithdrawn {
+			return errors.New("player has already withdrawn from the tournament")
+		}
+
+		for _, match := range matches {
+			if match.IsAbandoned || match.IsBye || len(match.Players) != 2 {
+				continue
+			}
+			if match.IsFinished {
+				if result.Rule == models.WithdrawalAnnul {
+					// Played matches are kept, but are excluded from standings and Elo since the player is annulled
+					result.Annulled = append(result.Annulled, match.ID)
+				}
+				continue
+			}
+			if result.Rule == models.WithdrawalAnnul {
+				_, err = tx.Exec(`UPDATE matches SET is_abandoned = 1 WHERE id = ? AND is_finished = 0`, match.ID)
+				if err != nil {
+					return err
+				}
+				result.Annulled = append(result.Annulled, match.ID)
+				continue
+			}
+			opponent := match.Players[0]
+			if opponent == playerID {
+				opponent = match.Players[1]
+			}
+			if placeholders[opponent] {
+				// Opponent is not decided yet
+				continue
+			}
+			err = finishWalkoverMatch(tx, match, opponent)
+			if err != nil {
+				return err
+			}
+			result.Walkovers = append(result.Walkovers, match.ID)
+		}
+
+		_, err = tx.Exec(`UPDATE player2tournament SET is_withdrawn = 1, is_annulled = ? WHERE tournament_id = ? AND player_id = ?`,
+			result.Rule == models.WithdrawalAnnul, tournamentID, playerID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Player %d withdrew from tournament %d (%s), %d walkovers and %d annulled matches", playerID, tournamentID, result.Rule,
+		len(result.Walkovers), len(result.Annulled))
+
+	err = CalculateEloForTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// finishWalkoverMatch will finish the given match as a walkover won by the given player, moving the winner on to the next match
+func finishWalkoverMatch(tx *sql.Tx, match *models.Match, winnerID int) error {
+	metadata, err := GetMatchMetadata(match.ID)
+	if err != nil {
+		return err
+	}
+	if metadata.WinnerOutcomeMatchID.Valid {
+		next, err := GetMatch(int(metadata.WinnerOutcomeMatchID.Int64))
+		if err != nil {
+			return err
+		}
+		idx := 0
+		if !metadata.IsWinnerOutcomeHome {
+			idx = 1
+		}
+		_, err = tx.Exec("UPDATE leg SET current_player_id = ? WHERE match_id = ?", winnerID, next.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE player2leg SET player_id = ? WHERE match_id = ? AND player_id = ?", winnerID, next.ID, next.Players[idx])
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.Exec(`UPDATE matches SET is_finished = 1, is_walkover = 1, winner_id = ? WHERE id = ? AND is_finished = 0`, winnerID, match.ID)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("match %d was finished while withdrawing", match.ID)
+	}
+	_, err = tx.Exec(`UPDATE leg SET is_finished = 1, end_time = NOW(), has_scores = 0 WHERE match_id = ? AND is_finished = 0`, match.ID)
+	if err != nil {
+		return err
+	}
+	log.Printf("Match %d was given to player %d as a walkover", match.ID, winnerID)
+	return nil
+}
+
+// getWithdrawnPlayers will return all players who have withdrawn from the given tournament
+func getWithdrawnPlayers(tournamentID int) (map[int]bool, error) {
+	rows, err := models.DB.Query(`SELECT player_id FROM player2tournament WHERE tournament_id = ? AND is_withdrawn = 1`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	withdrawn := make(map[int]bool)
+	for rows.Next() {
+		var playerID int
+		err := rows.Scan(&playerID)
+		if err != nil {
+			return nil, err
+		}
+		withdrawn[playerID] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return withdrawn, nil
+}
+
+// removeWithdrawnPlayers will remove withdrawn players from the given overview, so they are not seeded into playoffs
+func removeWithdrawnPlayers(overview map[int][]*models.TournamentOverview) map[int][]*models.TournamentOverview {
+	qualified := make(map[int][]*models.TournamentOverview)
+	for groupID, group := range overview {
+		for _, player := range group {
+			if !player.IsWithdrawn {
+				qualified[groupID] = append(qualified[groupID], player)
+			}
+		}
+	}
+	return qualified
+}
+
+func saveRegisteredPlayers(tx *sql.Tx, registrationID int, players ...*models.RegisteredPlayer) error {
+	for _, p := range players {
+		_, err := tx.Exec(`
+			INSERT INTO tournament_registration_player (registration_id, player_id, tournament_group_id, status, registered_at, checked_in_at, withdrawn_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE tournament_group_id = VALUES(tournament_group_id), status = VALUES(status), registered_at = VALUES(registered_at),
+				checked_in_at = VALUES(checked_in_at), withdrawn_at = VALUES(withdrawn_at)`,
+			registrationID, p.PlayerID, p.TournamentGroupID, p.Status, p.RegisteredAt, p.CheckedInAt, p.WithdrawnAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
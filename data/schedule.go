@@ -0,0 +1,268 @@
+package data
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// scheduleMatchPlayed is the condition for a match which is finished, abandoned or has been started, and so is never rescheduled
+const scheduleMatchPlayed = `(m.is_finished = 1 OR m.is_abandoned = 1 OR EXISTS (SELECT 1 FROM leg l WHERE l.match_id = m.id AND l.has_scores = 1))`
+
+// GenerateTournamentSchedule will assign all unplayed matches of the given tournament to venues and time slots.
+// Manually rescheduled matches and matches which have been played are kept, while all other matches are rescheduled.
+// If no availability is given, the availability stored for the tournament is used
+func GenerateTournamentSchedule(tournamentID int, options *models.ScheduleOptions) ([]*models.ScheduledMatch, error) {
+	matches, err := getScheduleMatches(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if options.Availability == nil {
+		options.Availability, err = GetTournamentPlayerAvailability(tournamentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	current, err := GetTournamentSchedule(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	fixed := make([]*models.ScheduledMatch, 0)
+	for _, match := range current {
+		if match.IsManual || match.IsPlayed {
+			fixed = append(fixed, match)
+		}
+	}
+	schedule, err := models.NewSchedule(tournamentID, matches, fixed, options)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`
+		DELETE ts FROM tournament_schedule ts
+			JOIN matches m ON m.id = ts.match_id
+		WHERE ts.tournament_id = ? AND ts.is_manual = 0 AND NOT `+scheduleMatchPlayed, tournamentID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	_, err = tx.Exec(`DELETE FROM tournament_player_availability WHERE tournament_id = ?`, tournamentID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, availability := range options.Availability {
+		_, err = tx.Exec(`INSERT INTO tournament_player_availability (tournament_id, player_id, available_from, available_to) VALUES (?, ?, ?, ?)`,
+			tournamentID, availability.PlayerID, availability.AvailableFrom, availability.AvailableTo)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, match := range schedule {
+		if match.IsManual || match.IsPlayed {
+			continue
+		}
+		_, err = tx.Exec(`INSERT INTO tournament_schedule (tournament_id, match_id, venue_id, start_time, end_time, is_manual) VALUES (?, ?, ?, ?, ?, 0)`,
+			tournamentID, match.MatchID, match.VenueID, match.StartTime, match.EndTime)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		_, err = tx.Exec(`UPDATE matches SET venue_id = ? WHERE id = ?`, match.VenueID, match.MatchID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	tx.Commit()
+	log.Printf("Scheduled %d matches for tournament %d", len(schedule), tournamentID)
+	return GetTournamentSchedule(tournamentID)
+}
+
+// RescheduleMatch will manually move the given match to a new venue and time slot, where all players are available.
+// Manually scheduled matches are kept when the schedule is regenerated
+func RescheduleMatch(tournamentID int, matchID int, input models.ScheduledMatch) ([]*models.ScheduledMatch, error) {
+	schedule, err := GetTournamentSchedule(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := GetTournamentPlayerAvailability(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	var match *models.ScheduledMatch
+	for _, scheduled := range schedule {
+		if scheduled.MatchID == matchID {
+			match = scheduled
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("match %d is not scheduled in tournament %d", matchID, tournamentID)
+	}
+	match.VenueID = input.VenueID
+	match.StartTime = input.StartTime
+	match.EndTime = input.EndTime
+	if input.EndTime.IsZero() {
+		// Keep the same duration as before
+		match.EndTime = input.StartTime.Add(match.EndTime.Sub(match.StartTime))
+	}
+	err = models.CheckScheduleConflicts(schedule, match)
+	if err != nil {
+		return nil, err
+	}
+	for _, playerID := range match.Players {
+		if !models.IsAvailable(availability, playerID, match.StartTime, match.EndTime) {
+			return nil, fmt.Errorf("player %d is not available at that time", playerID)
+		}
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`UPDATE tournament_schedule SET venue_id = ?, start_time = ?, end_time = ?, is_manual = 1 WHERE tournament_id = ? AND match_id = ?`,
+		match.VenueID, match.StartTime, match.EndTime, tournamentID, matchID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	_, err = tx.Exec(`UPDATE matches SET venue_id = ? WHERE id = ?`, match.VenueID, matchID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	log.Printf("Rescheduled match %d to venue %d at %s", matchID, match.VenueID, match.StartTime)
+	return GetTournamentSchedule(tournamentID)
+}
+
+// GetTournamentSchedule will return the schedule for the given tournament
+func GetTournamentSchedule(tournamentID int) ([]*models.ScheduledMatch, error) {
+	return getSchedule("ts.tournament_id = ?", tournamentID)
+}
+
+// GetVenueSchedule will return all scheduled matches for the given venue
+func GetVenueSchedule(venueID int) ([]*models.ScheduledMatch, error) {
+	return getSchedule("ts.venue_id = ?", venueID)
+}
+
+// GetTournamentPlayerAvailability will return the availability windows registered for players in the given tournament
+func GetTournamentPlayerAvailability(tournamentID int) ([]*models.PlayerAvailability, error) {
+	rows, err := models.DB.Query(`
+		SELECT player_id, available_from, available_to
+		FROM tournament_player_availability
+		WHERE tournament_id = ?
+		ORDER BY player_id, available_from`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	availability := make([]*models.PlayerAvailability, 0)
+	for rows.Next() {
+		a := new(models.PlayerAvailability)
+		err := rows.Scan(&a.PlayerID, &a.AvailableFrom, &a.AvailableTo)
+		if err != nil {
+			return nil, err
+		}
+		availability = append(availability, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return availability, nil
+}
+
+func getSchedule(where string, args ...interface{}) ([]*models.ScheduledMatch, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			ts.tournament_id, ts.match_id, ts.venue_id, v.name, ts.start_time, ts.end_time, ts.is_manual,
+			`+scheduleMatchPlayed+` AS 'is_played',
+			GROUP_CONCAT(DISTINCT p2l.player_id ORDER BY p2l.order) AS 'players',
+			mm.match_displayname, t.name
+		FROM tournament_schedule ts
+			JOIN matches m ON m.id = ts.match_id
+			JOIN tournament t ON t.id = ts.tournament_id
+			LEFT JOIN venue v ON v.id = ts.venue_id
+			LEFT JOIN match_metadata mm ON mm.match_id = ts.match_id
+			LEFT JOIN player2leg p2l ON p2l.match_id = ts.match_id
+		WHERE `+where+`
+		GROUP BY ts.match_id
+		ORDER BY ts.start_time, ts.venue_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedule := make([]*models.ScheduledMatch, 0)
+	for rows.Next() {
+		match := new(models.ScheduledMatch)
+		var players null.String
+		err := rows.Scan(&match.TournamentID, &match.MatchID, &match.VenueID, &match.VenueName, &match.StartTime, &match.EndTime,
+			&match.IsManual, &match.IsPlayed, &players, &match.MatchDisplayname, &match.TournamentName)
+		if err != nil {
+			return nil, err
+		}
+		if players.Valid {
+			match.Players = util.StringToIntArray(players.String)
+		}
+		schedule = append(schedule, match)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// getScheduleMatches will return all unplayed matches of the given tournament, with the matches they depend on
+func getScheduleMatches(tournamentID int) ([]*models.ScheduleMatch, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			m.id, IFNULL(mm.order_of_play, 0),
+			GROUP_CONCAT(DISTINCT IF(p.is_placeholder, NULL, p2l.player_id) ORDER BY p2l.order) AS 'players',
+			(SELECT GROUP_CONCAT(dep.match_id) FROM match_metadata dep
+				WHERE dep.winner_outcome_match_id = m.id OR dep.looser_outcome_match_id = m.id) AS 'depends_on'
+		FROM matches m
+			LEFT JOIN match_metadata mm ON mm.match_id = m.id
+			LEFT JOIN player2leg p2l ON p2l.match_id = m.id
+			LEFT JOIN player p ON p.id = p2l.player_id
+		WHERE m.tournament_id = ? AND NOT `+scheduleMatchPlayed+`
+		GROUP BY m.id
+		ORDER BY m.id`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*models.ScheduleMatch, 0)
+	for rows.Next() {
+		match := new(models.ScheduleMatch)
+		var players null.String
+		var dependsOn null.String
+		err := rows.Scan(&match.MatchID, &match.OrderOfPlay, &players, &dependsOn)
+		if err != nil {
+			return nil, err
+		}
+		match.Players = make([]int, 0)
+		if players.Valid {
+			match.Players = util.StringToIntArray(players.String)
+		}
+		match.DependsOn = make([]int, 0)
+		if dependsOn.Valid {
+			match.DependsOn = util.StringToIntArray(dependsOn.String)
+		}
+		matches = append(matches, match)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
@@ -0,0 +1,253 @@
+package data
+
+import (
+	"errors"
+	"log"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// NewSeason will create a new season for the given divisional tournament
+func NewSeason(season models.Season) (*models.Season, error) {
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	res, err := tx.Exec(`INSERT INTO season (name, short_name, office_id, tournament_id, previous_season_id) VALUES (?, ?, ?, ?, ?)`,
+		season.Name, season.ShortName, season.OfficeID, season.TournamentID, season.PreviousSeasonID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	seasonID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, division := range season.Divisions {
+		_, err = tx.Exec(`INSERT INTO season_division (season_id, division, tournament_group_id, promotion_count, relegation_count) VALUES (?, ?, ?, ?, ?)`,
+			seasonID, division.Division, division.TournamentGroupID, division.PromotionCount, division.RelegationCount)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if season.PreviousSeasonID.Valid {
+		_, err = tx.Exec(`UPDATE season SET next_season_id = ? WHERE id = ?`, seasonID, season.PreviousSeasonID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	tx.Commit()
+	log.Printf("Created new season %d for tournament %d", seasonID, season.TournamentID)
+	return GetSeason(int(seasonID))
+}
+
+// GetSeasons will return all seasons
+func GetSeasons() ([]*models.Season, error) {
+	rows, err := models.DB.Query(`
+		SELECT id, name, short_name, office_id, tournament_id, previous_season_id, next_season_id, is_finished
+		FROM season
+		ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := make([]*models.Season, 0)
+	for rows.Next() {
+		season := new(models.Season)
+		err := rows.Scan(&season.ID, &season.Name, &season.ShortName, &season.OfficeID, &season.TournamentID, &season.PreviousSeasonID,
+			&season.NextSeasonID, &season.IsFinished)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return seasons, nil
+}
+
+// GetSeason will return the season with the given ID
+func GetSeason(id int) (*models.Season, error) {
+	season := new(models.Season)
+	err := models.DB.QueryRow(`
+		SELECT id, name, short_name, office_id, tournament_id, previous_season_id, next_season_id, is_finished
+		FROM season WHERE id = ?`, id).Scan(&season.ID, &season.Name, &season.ShortName, &season.OfficeID, &season.TournamentID,
+		&season.PreviousSeasonID, &season.NextSeasonID, &season.IsFinished)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT division, tournament_group_id, promotion_count, relegation_count
+		FROM season_division WHERE season_id = ? ORDER BY division`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	season.Divisions = make([]*models.SeasonDivision, 0)
+	for rows.Next() {
+		division := new(models.SeasonDivision)
+		err := rows.Scan(&division.Division, &division.TournamentGroupID, &division.PromotionCount, &division.RelegationCount)
+		if err != nil {
+			return nil, err
+		}
+		season.Divisions = append(season.Divisions, division)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// FinishSeason will set promotions and relegations based on the final standings of the given season, and create the next season
+// with groups seeded from the movements. The next season is created first and the season is only marked as finished last, so a
+// failed rollover can be retried, reusing the next season or its tournament if they were already created
+func FinishSeason(id int, next models.Season) (*models.Season, error) {
+	season, err := GetSeason(id)
+	if err != nil {
+		return nil, err
+	}
+	if season.IsFinished {
+		return nil, errors.New("season is already finished")
+	}
+	tournament, err := GetTournament(season.TournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if !tournament.PresetID.Valid {
+		return nil, errors.New("tournament does not have a preset")
+	}
+
+	overview, err := GetTournamentOverview(season.TournamentID)
+	if err != nil {
+		return nil, err
+	}
+	standings := make(map[int][]int)
+	for groupID, players := range overview {
+		for _, player := range players {
+			standings[groupID] = append(standings[groupID], player.PlayerID)
+		}
+	}
+	movements := models.CalculateSeasonMovements(season, standings)
+
+	var nextSeason *models.Season
+	if season.NextSeasonID.Valid {
+		nextSeason, err = GetSeason(int(season.NextSeasonID.Int64))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var nextTournamentID null.Int
+		err = models.DB.QueryRow(`SELECT next_tournament_id FROM season WHERE id = ?`, season.ID).Scan(&nextTournamentID)
+		if err != nil {
+			return nil, err
+		}
+		if !nextTournamentID.Valid {
+			players := make([]*models.Player2Tournament, 0)
+			for groupID, group := range movements.NextGroup {
+				for _, playerID := range group {
+					players = append(players, &models.Player2Tournament{PlayerID: playerID, TournamentGroupID: groupID})
+				}
+			}
+			nextTournament, err := GenerateTournament(models.Tournament{
+				Name:        next.Name,
+				ShortName:   next.ShortName,
+				OfficeID:    season.OfficeID,
+				PresetID:    tournament.PresetID,
+				ManualAdmin: tournament.ManualAdmin,
+				Players:     players,
+			})
+			if err != nil {
+				return nil, err
+			}
+			// Record the tournament before creating the next season, so a retry reuses it instead of generating another one
+			_, err = models.DB.Exec(`UPDATE season SET next_tournament_id = ? WHERE id = ?`, nextTournament.ID, season.ID)
+			if err != nil {
+				return nil, err
+			}
+			nextTournamentID = null.IntFrom(int64(nextTournament.ID))
+		}
+
+		divisions := next.Divisions
+		if len(divisions) == 0 {
+			divisions = season.Divisions
+		}
+		nextSeason, err = NewSeason(models.Season{
+			Name:             next.Name,
+			ShortName:        next.ShortName,
+			OfficeID:         season.OfficeID,
+			TournamentID:     int(nextTournamentID.Int64),
+			PreviousSeasonID: null.IntFrom(int64(season.ID)),
+			Divisions:        divisions,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	for groupID, players := range standings {
+		for _, playerID := range players {
+			_, err = tx.Exec(`UPDATE player2tournament SET is_promoted = ?, is_relegated = ? WHERE tournament_id = ? AND tournament_group_id = ? AND player_id = ?`,
+				movements.Promoted[playerID], movements.Relegated[playerID], season.TournamentID, groupID, playerID)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+	_, err = tx.Exec(`UPDATE season SET is_finished = 1 WHERE id = ?`, season.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Finished season %d with %d promotions and %d relegations", season.ID, len(movements.Promoted), len(movements.Relegated))
+	return nextSeason, nil
+}
+
+// GetPlayerDivisionHistory will return the division the given player played in for each season
+func GetPlayerDivisionHistory(playerID int) ([]*models.DivisionHistory, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			p2t.player_id, s.id, s.name, s.tournament_id, IFNULL(sd.division, tg.division), p2t.tournament_group_id, tg.name,
+			p2t.is_promoted, p2t.is_relegated
+		FROM season s
+			JOIN player2tournament p2t ON p2t.tournament_id = s.tournament_id
+			LEFT JOIN tournament_group tg ON tg.id = p2t.tournament_group_id
+			LEFT JOIN season_division sd ON sd.season_id = s.id AND sd.tournament_group_id = p2t.tournament_group_id
+		WHERE p2t.player_id = ?
+		ORDER BY s.id`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]*models.DivisionHistory, 0)
+	for rows.Next() {
+		h := new(models.DivisionHistory)
+		err := rows.Scan(&h.PlayerID, &h.SeasonID, &h.SeasonName, &h.TournamentID, &h.Division, &h.TournamentGroupID, &h.GroupName,
+			&h.IsPromoted, &h.IsRelegated)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
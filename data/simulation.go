@@ -0,0 +1,152 @@
+package data
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// SimulateTournament will simulate the remaining group and playoff matches of the given tournament the given number of times,
+// and return the finishing position distribution for each player
+func SimulateTournament(tournamentID int, iterations int, playoffsSize int) (*models.TournamentSimulation, error) {
+	tournament, err := GetTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &models.TournamentSimulationInput{TournamentID: tournamentID, Groups: make(map[int][]int), PlayoffsSize: playoffsSize,
+		PlayoffsWinsRequired: make(map[int]int), ThreeDartAvg: make(map[int]float32)}
+	if tournament.IsPlayoffs {
+		input.PlayoffMatches, err = getSimulationMatches(tournamentID, true)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		overview, err := GetTournamentOverview(tournamentID)
+		if err != nil {
+			return nil, err
+		}
+		for groupID, players := range overview {
+			for _, player := range players {
+				if player.IsWithdrawn {
+					continue
+				}
+				input.Groups[groupID] = append(input.Groups[groupID], player.PlayerID)
+				input.ThreeDartAvg[player.PlayerID] = player.ThreeDartAvg
+			}
+		}
+		input.GroupMatches, err = getSimulationMatches(tournamentID, false)
+		if err != nil {
+			return nil, err
+		}
+		if tournament.PlayoffsTournamentID.Valid {
+			input.PlayoffMatches, err = getSimulationMatches(int(tournament.PlayoffsTournamentID.Int64), true)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	input.TiebreakRules = models.DefaultTiebreakRules
+	if preset := tournament.Preset; preset != nil {
+		input.TiebreakRules = preset.TiebreakRules
+		if input.PlayoffsSize == 0 {
+			input.PlayoffsSize = preset.PlayoffsSize
+		}
+		modes, err := GetMatchModes()
+		if err != nil {
+			return nil, err
+		}
+		winsRequired := make(map[int]int)
+		for _, mode := range modes {
+			winsRequired[mode.ID] = mode.WinsRequired
+		}
+		for _, size := range []int{16, 32, 64} {
+			input.PlayoffsWinsRequired[size] = winsRequired[preset.MatchMode.ID]
+		}
+		input.PlayoffsWinsRequired[8] = winsRequired[preset.MatchModeLast16.ID]
+		input.PlayoffsWinsRequired[4] = winsRequired[preset.MatchModeQuarterFinal.ID]
+		input.PlayoffsWinsRequired[2] = winsRequired[preset.MatchModeSemiFinal.ID]
+		input.PlayoffsWinsRequired[1] = winsRequired[preset.MatchModeGrandFinal.ID]
+	}
+
+	ids := make([]int, 0)
+	for _, group := range input.Groups {
+		ids = append(ids, group...)
+	}
+	for _, match := range input.PlayoffMatches {
+		for _, playerID := range match.Players {
+			if playerID != 0 {
+				ids = append(ids, playerID)
+			}
+		}
+	}
+	model := &models.SimulationModel{Elo: make(map[int]int), WinProbability: GetPlayerWinProbability, DrawProbability: GetPlayerDrawProbability}
+	if len(ids) > 0 {
+		elos, err := GetPlayersElo(ids...)
+		if err != nil {
+			return nil, err
+		}
+		for _, elo := range elos {
+			model.Elo[elo.PlayerID] = elo.CurrentElo
+		}
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return models.SimulateTournament(input, model, iterations, rng), nil
+}
+
+// getSimulationMatches will return all matches for the given tournament, ordered by order of play. Players are ordered
+// home and away by the order of the first leg
+func getSimulationMatches(tournamentID int, isPlayoffs bool) ([]*models.SimulationMatch, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			m.id, IFNULL(MIN(p2t.tournament_group_id), 0), m.is_finished, m.winner_id,
+			GROUP_CONCAT(IF(p.is_placeholder, 0, p2l.player_id) ORDER BY p2l.order) AS 'players',
+			(SELECT GROUP_CONCAT(l.winner_id ORDER BY l.id) FROM leg l WHERE l.match_id = m.id AND l.is_finished = 1 AND l.winner_id IS NOT NULL) AS 'legs_won',
+			mm.wins_required, mm.legs_required, mm.is_draw_possible,
+			md.winner_outcome_match_id, IFNULL(md.is_winner_outcome_home, 0), md.looser_outcome_match_id, IFNULL(md.is_looser_outcome_home, 0)
+		FROM matches m
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+			JOIN player2leg p2l ON p2l.match_id = m.id AND p2l.leg_id = (SELECT MIN(fl.id) FROM leg fl WHERE fl.match_id = m.id)
+			JOIN player p ON p.id = p2l.player_id
+			LEFT JOIN player2tournament p2t ON p2t.tournament_id = m.tournament_id AND p2t.player_id = p2l.player_id
+			LEFT JOIN match_metadata md ON md.match_id = m.id
+		WHERE m.tournament_id = ? AND m.is_abandoned = 0 AND (m.is_bye = 0 OR ?) AND NOT `+tournamentMatchAnnulled+`
+		GROUP BY m.id
+		ORDER BY IFNULL(MIN(md.order_of_play), 0), m.id`, tournamentID, isPlayoffs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*models.SimulationMatch, 0)
+	for rows.Next() {
+		match := new(models.SimulationMatch)
+		var players string
+		var legsWon null.String
+		err := rows.Scan(&match.MatchID, &match.GroupID, &match.IsFinished, &match.WinnerID, &players, &legsWon,
+			&match.WinsRequired, &match.LegsRequired, &match.IsDrawPossible,
+			&match.WinnerTo, &match.IsWinnerToHome, &match.LooserTo, &match.IsLooserToHome)
+		if err != nil {
+			return nil, err
+		}
+		match.Players = util.StringToIntArray(players)
+		for len(match.Players) < 2 {
+			// Both players are placeholders
+			match.Players = append(match.Players, 0)
+		}
+		match.LegsWon = make(map[int]int)
+		if legsWon.Valid {
+			for _, winnerID := range util.StringToIntArray(legsWon.String) {
+				match.LegsWon[winnerID]++
+			}
+		}
+		matches = append(matches, match)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
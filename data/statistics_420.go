@@ -198,7 +198,7 @@ func Get420StatisticsForMatch(id int) ([]*models.Statistics420, error) {
 }
 
 // Get420StatisticsForPlayer will return 420 statistics for the given player
-func Get420StatisticsForPlayer(id int) (*models.Statistics420, error) {
+func Get420StatisticsForPlayer(id int, versus []int) (*models.Statistics420, error) {
 	s := new(models.Statistics420)
 	h := make([]*float64, 22)
 	err := models.DB.QueryRow(`
@@ -237,7 +237,7 @@ func Get420StatisticsForPlayer(id int) (*models.Statistics420, error) {
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 11
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.Score,
@@ -0,0 +1,120 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/kcapp/api/models"
+)
+
+// AccuracyMatchTypes are the match types where the intended target of each dart can be inferred
+var AccuracyMatchTypes = []int{models.X01, models.AROUNDTHECLOCK, models.BERMUDATRIANGLE, models.JDCPRACTICE}
+
+// CalculateAccuracyStatistics will calculate accuracy statistics for each player in the given leg
+func CalculateAccuracyStatistics(legID int) (map[int]*models.StatisticsAccuracy, error) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		return nil, err
+	}
+	return models.CalculateAccuracyStatistics(leg), nil
+}
+
+// GetPlayerAccuracyStatistics will return accuracy statistics for the given player for each supported match type
+func GetPlayerAccuracyStatistics(playerID int) ([]*models.StatisticsAccuracy, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			s.player_id,
+			IFNULL(l.leg_type_id, m.match_type_id) AS 'match_type_id',
+			SUM(s.darts),
+			SUM(s.hits),
+			SUM(s.segment_hits),
+			SUM(s.misses),
+			SUM(s.clockwise),
+			SUM(s.anticlockwise),
+			SUM(s.angular_offset),
+			SUM(s.radial_inside),
+			SUM(s.radial_outside)
+		FROM statistics_accuracy s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE s.player_id = ?
+			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+		GROUP BY s.player_id, IFNULL(l.leg_type_id, m.match_type_id)`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statistics := make([]*models.StatisticsAccuracy, 0)
+	for rows.Next() {
+		s := new(models.StatisticsAccuracy)
+		err := rows.Scan(&s.PlayerID, &s.MatchTypeID, &s.Darts, &s.Hits, &s.SegmentHits, &s.Misses, &s.Clockwise, &s.Anticlockwise,
+			&s.AngularOffset, &s.RadialInside, &s.RadialOutside)
+		if err != nil {
+			return nil, err
+		}
+		s.Calculate()
+		statistics = append(statistics, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return statistics, nil
+}
+
+// insertAccuracyStatistics will calculate and insert accuracy statistics for the given leg
+func insertAccuracyStatistics(tx *sql.Tx, leg *models.Leg) error {
+	for playerID, stats := range models.CalculateAccuracyStatistics(leg) {
+		_, err := tx.Exec(`
+			INSERT INTO statistics_accuracy
+				(leg_id, player_id, darts, hits, segment_hits, misses, clockwise, anticlockwise, angular_offset, radial_inside, radial_outside)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, leg.ID, playerID, stats.Darts, stats.Hits, stats.SegmentHits, stats.Misses,
+			stats.Clockwise, stats.Anticlockwise, stats.AngularOffset, stats.RadialInside, stats.RadialOutside)
+		if err != nil {
+			return err
+		}
+		log.Printf("[%d] Inserting accuracy statistics for player %d", leg.ID, playerID)
+	}
+	return nil
+}
+
+// RecalculateAccuracyStatistics will recalculate accuracy statistics for all supported legs since the given date, or the given leg
+func RecalculateAccuracyStatistics(legID int, since string, dryRun bool) error {
+	legs := make([]int, 0)
+	if legID != 0 {
+		log.Printf("Recalculating accuracy statistics for leg %d", legID)
+		legs = append(legs, legID)
+	} else {
+		if since == "" {
+			since = "1970-01-01"
+		}
+		log.Printf("Recalculating accuracy statistics since=%s", since)
+		for _, matchType := range AccuracyMatchTypes {
+			ids, err := GetLegsToRecalculate(matchType, since)
+			if err != nil {
+				return err
+			}
+			legs = append(legs, ids...)
+		}
+	}
+
+	queries := make([]string, 0)
+	for _, id := range legs {
+		stats, err := CalculateAccuracyStatistics(id)
+		if err != nil {
+			return err
+		}
+		for playerID, stat := range stats {
+			query := fmt.Sprintf(`INSERT INTO statistics_accuracy (leg_id, player_id, darts, hits, segment_hits, misses, clockwise, anticlockwise, angular_offset, radial_inside, radial_outside)
+				VALUES (%d, %d, %d, %d, %d, %d, %d, %d, %d, %d, %d)
+				ON DUPLICATE KEY UPDATE darts = VALUES(darts), hits = VALUES(hits), segment_hits = VALUES(segment_hits), misses = VALUES(misses),
+					clockwise = VALUES(clockwise), anticlockwise = VALUES(anticlockwise), angular_offset = VALUES(angular_offset),
+					radial_inside = VALUES(radial_inside), radial_outside = VALUES(radial_outside);`,
+				id, playerID, stat.Darts, stat.Hits, stat.SegmentHits, stat.Misses, stat.Clockwise, stat.Anticlockwise, stat.AngularOffset,
+				stat.RadialInside, stat.RadialOutside)
+			queries = append(queries, query)
+		}
+	}
+	return executeRecalculateQueries(queries, dryRun)
+}
@@ -208,7 +208,7 @@ func GetAroundTheClockStatisticsForMatch(id int) ([]*models.StatisticsAroundThe,
 }
 
 // GetAroundTheClockStatisticsForPlayer will return Around the Clock statistics for the given player
-func GetAroundTheClockStatisticsForPlayer(id int) (*models.StatisticsAroundThe, error) {
+func GetAroundTheClockStatisticsForPlayer(id int, versus []int) (*models.StatisticsAroundThe, error) {
 	s := new(models.StatisticsAroundThe)
 	h := make([]*float64, 26)
 	err := models.DB.QueryRow(`
@@ -249,7 +249,7 @@ func GetAroundTheClockStatisticsForPlayer(id int) (*models.StatisticsAroundThe,
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 8
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon,
@@ -207,7 +207,7 @@ func GetAroundTheWorldStatisticsForMatch(id int) ([]*models.StatisticsAroundThe,
 }
 
 // GetAroundTheWorldStatisticsForPlayer will return Around the World statistics for the given player
-func GetAroundTheWorldStatisticsForPlayer(id int) (*models.StatisticsAroundThe, error) {
+func GetAroundTheWorldStatisticsForPlayer(id int, versus []int) (*models.StatisticsAroundThe, error) {
 	s := new(models.StatisticsAroundThe)
 	h := make([]*float64, 26)
 	err := models.DB.QueryRow(`
@@ -248,7 +248,7 @@ func GetAroundTheWorldStatisticsForPlayer(id int) (*models.StatisticsAroundThe,
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 6
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
@@ -552,7 +552,7 @@ func GetShanghaiStatisticsForMatch(id int) ([]*models.StatisticsAroundThe, error
 }
 
 // GetShanghaiStatisticsForPlayer will return Shanghai statistics for the given player
-func GetShanghaiStatisticsForPlayer(id int) (*models.StatisticsAroundThe, error) {
+func GetShanghaiStatisticsForPlayer(id int, versus []int) (*models.StatisticsAroundThe, error) {
 	s := new(models.StatisticsAroundThe)
 	h := make([]null.Float, 26)
 	err := models.DB.QueryRow(`
@@ -592,7 +592,7 @@ func GetShanghaiStatisticsForPlayer(id int) (*models.StatisticsAroundThe, error)
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 7
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
@@ -183,7 +183,7 @@ func GetBermudaTriangleStatisticsForMatch(id int) ([]*models.StatisticsBermudaTr
 }
 
 // GetBermudaTriangleStatisticsForPlayer will return Bermuda Triangle statistics for the given player
-func GetBermudaTriangleStatisticsForPlayer(id int) (*models.StatisticsBermudaTriangle, error) {
+func GetBermudaTriangleStatisticsForPlayer(id int, versus []int) (*models.StatisticsBermudaTriangle, error) {
 	s := new(models.StatisticsBermudaTriangle)
 	h := make([]*float64, 26)
 	err := models.DB.QueryRow(`
@@ -218,7 +218,7 @@ func GetBermudaTriangleStatisticsForPlayer(id int) (*models.StatisticsBermudaTri
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 10
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
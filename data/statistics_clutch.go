@@ -0,0 +1,109 @@
+package data
+
+import (
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// GetPlayerClutchStatistics will return clutch statistics for the given player, calculated from all finished two player X01 matches
+func GetPlayerClutchStatistics(playerID int) (*models.StatisticsClutch, error) {
+	return getPlayerClutchStatistics(playerID, null.Int{})
+}
+
+// GetPlayerClutchStatisticsAgainst will return clutch statistics for the given player, calculated from all finished two player X01 matches
+// against the given opponent
+func GetPlayerClutchStatisticsAgainst(playerID int, opponentID int) (*models.StatisticsClutch, error) {
+	return getPlayerClutchStatistics(playerID, null.IntFrom(int64(opponentID)))
+}
+
+func getPlayerClutchStatistics(playerID int, opponentID null.Int) (*models.StatisticsClutch, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			m.id, mm.wins_required, l.id, l.starting_score, l.winner_id, IFNULL(lp.outshot_type_id, 1),
+			(SELECT GROUP_CONCAT(p2l.player_id ORDER BY p2l.order) FROM player2leg p2l WHERE p2l.leg_id = l.id) AS 'players',
+			s.id, s.player_id,
+			s.first_dart, s.first_dart_multiplier,
+			s.second_dart, s.second_dart_multiplier,
+			s.third_dart, s.third_dart_multiplier,
+			s.is_bust
+		FROM score s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+			LEFT JOIN leg_parameters lp ON lp.leg_id = l.id
+		WHERE m.id IN (SELECT match_id FROM player2leg WHERE player_id = ?)
+			AND (? IS NULL OR m.id IN (SELECT match_id FROM player2leg WHERE player_id = ?))
+			AND m.id IN (SELECT match_id FROM player2leg GROUP BY match_id HAVING COUNT(DISTINCT player_id) = 2)
+			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+			AND m.match_type_id = 1 AND l.leg_type_id IS NULL AND l.is_finished = 1
+		ORDER BY m.id, l.id, s.id`, playerID, opponentID, opponentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &models.StatisticsClutch{PlayerID: playerID}
+
+	var leg *models.Leg
+	var winsRequired null.Int
+	legsWon := make(map[int]int)
+	addLeg := func() {
+		if leg == nil {
+			return
+		}
+		for _, id := range leg.Players {
+			if _, ok := legsWon[id]; !ok {
+				legsWon[id] = 0
+			}
+		}
+		stats.AddLeg(leg, models.IsDecidingLeg(int(winsRequired.Int64), legsWon))
+		if leg.WinnerPlayerID.Valid {
+			legsWon[int(leg.WinnerPlayerID.Int64)]++
+		}
+	}
+	for rows.Next() {
+		var matchID, legID, startingScore, outshotTypeID int
+		var wins null.Int
+		var winnerID null.Int
+		var players string
+		v := new(models.Visit)
+		v.FirstDart = new(models.Dart)
+		v.SecondDart = new(models.Dart)
+		v.ThirdDart = new(models.Dart)
+		err := rows.Scan(&matchID, &wins, &legID, &startingScore, &winnerID, &outshotTypeID, &players,
+			&v.ID, &v.PlayerID,
+			&v.FirstDart.Value, &v.FirstDart.Multiplier,
+			&v.SecondDart.Value, &v.SecondDart.Multiplier,
+			&v.ThirdDart.Value, &v.ThirdDart.Multiplier,
+			&v.IsBust)
+		if err != nil {
+			return nil, err
+		}
+		v.LegID = legID
+
+		if leg == nil || leg.ID != legID {
+			addLeg()
+			if leg == nil || leg.MatchID != matchID {
+				legsWon = make(map[int]int)
+			}
+			leg = &models.Leg{
+				ID:             legID,
+				MatchID:        matchID,
+				StartingScore:  startingScore,
+				WinnerPlayerID: winnerID,
+				Players:        util.StringToIntArray(players),
+				Parameters:     &models.LegParameters{OutshotType: &models.OutshotType{ID: outshotTypeID}},
+				Visits:         make([]*models.Visit, 0),
+			}
+			winsRequired = wins
+		}
+		leg.Visits = append(leg.Visits, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	addLeg()
+
+	return stats, nil
+}
@@ -138,7 +138,7 @@ func GetCricketStatisticsForMatch(id int) ([]*models.StatisticsCricket, error) {
 }
 
 // GetCricketStatisticsForPlayer will return Cricket statistics for the given player
-func GetCricketStatisticsForPlayer(id int) (*models.StatisticsCricket, error) {
+func GetCricketStatisticsForPlayer(id int, versus []int) (*models.StatisticsCricket, error) {
 	s := new(models.StatisticsCricket)
 	err := models.DB.QueryRow(`
 		SELECT
@@ -162,7 +162,7 @@ func GetCricketStatisticsForPlayer(id int) (*models.StatisticsCricket, error) {
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 4
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.TotalMarks, &s.FirstNineMarks,
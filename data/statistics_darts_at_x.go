@@ -136,7 +136,7 @@ func GetDartsAtXStatisticsForMatch(id int) ([]*models.StatisticsDartsAtX, error)
 }
 
 // GetDartsAtXStatisticsForPlayer will return Darts at X statistics for the given player
-func GetDartsAtXStatisticsForPlayer(id int) (*models.StatisticsDartsAtX, error) {
+func GetDartsAtXStatisticsForPlayer(id int, versus []int) (*models.StatisticsDartsAtX, error) {
 	s := new(models.StatisticsDartsAtX)
 	err := models.DB.QueryRow(`
 		SELECT
@@ -161,7 +161,7 @@ func GetDartsAtXStatisticsForPlayer(id int) (*models.StatisticsDartsAtX, error)
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 5
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.AvgScore,
@@ -180,7 +180,7 @@ func GetDartsAtXStatisticsForPlayer(id int) (*models.StatisticsDartsAtX, error)
 		FROM statistics_darts_at_x s
 			LEFT JOIN leg l ON l.id = s.leg_id
 			LEFT JOIN matches m ON m.id = l.match_id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0
 		GROUP BY l.starting_score`, id)
 	if err != nil {
@@ -1,78 +1,177 @@
 package data
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/guregu/null"
 	"github.com/kcapp/api/models"
 )
 
+// globalStatisticsBuckets contains the expression used to group matches into each time series bucket. Weeks start on Monday
+var globalStatisticsBuckets = map[string]string{
+	"":                                 "''",
+	models.GlobalStatisticsBucketDay:   "DATE_FORMAT(m.updated_at, '%Y-%m-%d')",
+	models.GlobalStatisticsBucketWeek:  "DATE_FORMAT(DATE_SUB(m.updated_at, INTERVAL WEEKDAY(m.updated_at) DAY), '%Y-%m-%d')",
+	models.GlobalStatisticsBucketMonth: "DATE_FORMAT(m.updated_at, '%Y-%m-01')",
+}
+
 // GetGlobalStatistics will return global statistics for all matches
 func GetGlobalStatistics() (map[int]*models.GlobalStatistics, error) {
-	rows, err := models.DB.Query(`
+	stats, err := getGlobalStatistics(globalStatisticsBuckets[""], "")
+	if err != nil {
+		return nil, err
+	}
+	fnc, err := getGlobalStatisticsFnc(globalStatisticsBuckets[""], "")
+	if err != nil {
+		return nil, err
+	}
+	return mergeGlobalStatistics(stats[""], fnc[""]), nil
+}
+
+// GetGlobalStatisticsFnc will return global fish and chips statistics
+func GetGlobalStatisticsFnc() (map[int]*models.GlobalStatistics, error) {
+	fnc, err := getGlobalStatisticsFnc(globalStatisticsBuckets[""], "")
+	if err != nil {
+		return nil, err
+	}
+	stats := fnc[""]
+	if stats == nil {
+		stats = make(map[int]*models.GlobalStatistics)
+	}
+	all := new(models.GlobalStatistics)
+	for _, s := range stats {
+		all.FishNChips += s.FishNChips
+	}
+	stats[0] = all
+
+	return stats, nil
+}
+
+// GetGlobalStatisticsForPeriod will return global statistics for matches in the given period, from inclusive and to exclusive,
+// optionally filtered by match type and office (0 means all), and split into day, week or month buckets if a bucket is given
+func GetGlobalStatisticsForPeriod(from string, to string, matchType int, officeID int, bucket string) (*models.GlobalStatisticsPeriod, error) {
+	where := " AND m.updated_at >= ? AND m.updated_at < ?"
+	args := []interface{}{from, to}
+	if matchType != 0 {
+		where += " AND IFNULL(l.leg_type_id, m.match_type_id) = ?"
+		args = append(args, matchType)
+	}
+	if officeID != 0 {
+		where += " AND m.office_id = ?"
+		args = append(args, officeID)
+	}
+
+	period := &models.GlobalStatisticsPeriod{From: from, To: to, MatchTypeID: matchType, OfficeID: officeID, Bucket: bucket}
+	stats, err := getGlobalStatistics(globalStatisticsBuckets[""], where, args...)
+	if err != nil {
+		return nil, err
+	}
+	fnc, err := getGlobalStatisticsFnc(globalStatisticsBuckets[""], where, args...)
+	if err != nil {
+		return nil, err
+	}
+	period.Total = mergeGlobalStatistics(stats[""], fnc[""])
+
+	if bucket != "" {
+		stats, err := getGlobalStatistics(globalStatisticsBuckets[bucket], where, args...)
+		if err != nil {
+			return nil, err
+		}
+		fnc, err := getGlobalStatisticsFnc(globalStatisticsBuckets[bucket], where, args...)
+		if err != nil {
+			return nil, err
+		}
+		starts := make([]string, 0)
+		for start := range stats {
+			starts = append(starts, start)
+		}
+		sort.Strings(starts)
+
+		period.Buckets = make([]*models.GlobalStatisticsBucket, 0)
+		for _, start := range starts {
+			period.Buckets = append(period.Buckets, &models.GlobalStatisticsBucket{Start: start, Offices: mergeGlobalStatistics(stats[start], fnc[start])})
+		}
+	}
+	return period, nil
+}
+
+// mergeGlobalStatistics will add fish and chips to the statistics of each office, calculate rates and add a total for all offices as office 0
+func mergeGlobalStatistics(stats map[int]*models.GlobalStatistics, fnc map[int]*models.GlobalStatistics) map[int]*models.GlobalStatistics {
+	if stats == nil {
+		stats = make(map[int]*models.GlobalStatistics)
+	}
+	all := new(models.GlobalStatistics)
+	for officeID, s := range stats {
+		if officeID == 0 {
+			continue
+		}
+		if _, ok := fnc[officeID]; ok {
+			s.FishNChips = fnc[officeID].FishNChips
+		}
+		s.CalculateRates()
+		all.Add(s)
+	}
+	all.FishNChips = 0
+	for _, s := range fnc {
+		all.FishNChips += s.FishNChips
+	}
+	all.CalculateRates()
+	stats[0] = all
+
+	return stats
+}
+
+// getGlobalStatistics will return global statistics per bucket and office for all matches matching the given where clause
+func getGlobalStatistics(bucket string, where string, args ...interface{}) (map[string]map[int]*models.GlobalStatistics, error) {
+	rows, err := models.DB.Query(fmt.Sprintf(`
 			SELECT
+				%s AS 'bucket',
 				m.office_id,
 				COUNT(DISTINCT m.id) AS 'matches',
 				COUNT(DISTINCT l.id) AS 'legs',
 				COUNT(DISTINCT s.id) AS 'visits',
 				COUNT(first_dart) + SUM(IF(second_dart is null, 0, 1)) + SUM(IF(third_dart is null, 0, 1)) as darts,
 				IFNULL(SUM(s.first_dart * s.first_dart_multiplier + IFNULL(s.second_dart, 0) * s.second_dart_multiplier + IFNULL(s.third_dart, 0) * s.third_dart_multiplier) - SUM(IF(s.is_bust = 1, s.first_dart * s.first_dart_multiplier + IFNULL(s.second_dart, 0) * s.second_dart_multiplier + IFNULL(s.third_dart, 0) * s.third_dart_multiplier, 0)), 0) as 'points',
-				SUM(IF(s.is_bust = 1, s.first_dart * s.first_dart_multiplier + IFNULL(s.second_dart, 0) * s.second_dart_multiplier + IFNULL(s.third_dart, 0) * s.third_dart_multiplier, 0)) as 'points_busted',
-				SUM(IF(s.is_bust, 0, IF(first_dart = 20 AND first_dart_multiplier = 3 AND second_dart = 20 AND second_dart_multiplier = 3 AND third_dart = 20 AND third_dart_multiplier = 3, 1, 0))) as '180s',
-				SUM(IF(s.is_bust, 0, IF((first_dart = 25 AND first_dart_multiplier = 2) OR (second_dart = 25 AND second_dart_multiplier = 2) OR (third_dart = 25 AND third_dart_multiplier = 2), 1, 0))) as 'bullseyes'
+				IFNULL(SUM(IF(s.is_bust = 1, s.first_dart * s.first_dart_multiplier + IFNULL(s.second_dart, 0) * s.second_dart_multiplier + IFNULL(s.third_dart, 0) * s.third_dart_multiplier, 0)), 0) as 'points_busted',
+				IFNULL(SUM(IF(s.is_bust, 0, IF(first_dart = 20 AND first_dart_multiplier = 3 AND second_dart = 20 AND second_dart_multiplier = 3 AND third_dart = 20 AND third_dart_multiplier = 3, 1, 0))), 0) as '180s',
+				IFNULL(SUM(IF(s.is_bust, 0, IF((first_dart = 25 AND first_dart_multiplier = 2) OR (second_dart = 25 AND second_dart_multiplier = 2) OR (third_dart = 25 AND third_dart_multiplier = 2), 1, 0))), 0) as 'bullseyes'
 			FROM matches m
 				LEFT JOIN leg l on l.match_id = m.id
 				LEFT JOIN score s on s.leg_id = l.id
 				LEFT JOIN player p on p.id = s.player_id
-			WHERE m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0 AND (p.id is null OR p.is_bot = 0)
-			GROUP BY m.office_id`)
+			WHERE m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0 AND (p.id is null OR p.is_bot = 0) %s
+			GROUP BY 1, m.office_id`, bucket, where), args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make(map[int]*models.GlobalStatistics)
+	stats := make(map[string]map[int]*models.GlobalStatistics)
 	for rows.Next() {
+		var start string
 		var officeID null.Int
 		s := new(models.GlobalStatistics)
-		err := rows.Scan(&officeID, &s.Matches, &s.Legs, &s.Visits, &s.Darts, &s.Points, &s.PointsBusted, &s.Score180s, &s.ScoreBullseyes)
+		err := rows.Scan(&start, &officeID, &s.Matches, &s.Legs, &s.Visits, &s.Darts, &s.Points, &s.PointsBusted, &s.Score180s, &s.ScoreBullseyes)
 		if err != nil {
 			return nil, err
 		}
-		stats[int(officeID.Int64)] = s
+		if _, ok := stats[start]; !ok {
+			stats[start] = make(map[int]*models.GlobalStatistics)
+		}
+		stats[start][int(officeID.Int64)] = s
 	}
-
-	fnc, err := GetGlobalStatisticsFnc()
-	if err != nil {
+	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-
-	all := new(models.GlobalStatistics)
-	for officeID, s := range stats {
-		if officeID == 0 {
-			continue
-		}
-		if _, ok := fnc[officeID]; ok {
-			s.FishNChips = fnc[officeID].FishNChips
-		}
-
-		all.Legs += s.Legs
-		all.Matches += s.Matches
-		all.Visits += s.Visits
-		all.Darts += s.Darts
-		all.Points += s.Points
-		all.PointsBusted += s.PointsBusted
-		all.Score180s += s.Score180s
-		all.ScoreBullseyes += s.ScoreBullseyes
-	}
-	all.FishNChips = fnc[0].FishNChips
-	stats[0] = all
-
 	return stats, nil
 }
 
-// GetGlobalStatisticsFnc will return global fish and chips statistics
-func GetGlobalStatisticsFnc() (map[int]*models.GlobalStatistics, error) {
-	rows, err := models.DB.Query(`
+// getGlobalStatisticsFnc will return fish and chips per bucket and office for all matches matching the given where clause
+func getGlobalStatisticsFnc(bucket string, where string, args ...interface{}) (map[string]map[int]*models.GlobalStatistics, error) {
+	rows, err := models.DB.Query(fmt.Sprintf(`
 		SELECT
+			%s AS 'bucket',
 			m.office_id,
 			COUNT(s.id) AS 'Fish-n-Chips'
 		FROM score s
@@ -86,28 +185,29 @@ func GetGlobalStatisticsFnc() (map[int]*models.GlobalStatistics, error) {
 			((first_dart * first_dart_multiplier) + (second_dart * second_dart_multiplier) +
 			(third_dart * third_dart_multiplier) = 26)
 			AND m.is_abandoned <> 1
-			AND p.is_bot = 0
-		GROUP BY m.office_id`)
+			AND p.is_bot = 0 %s
+		GROUP BY 1, m.office_id`, bucket, where), args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make(map[int]*models.GlobalStatistics)
+	stats := make(map[string]map[int]*models.GlobalStatistics)
 	for rows.Next() {
+		var start string
 		var officeID null.Int
 		s := new(models.GlobalStatistics)
-		err := rows.Scan(&officeID, &s.FishNChips)
+		err := rows.Scan(&start, &officeID, &s.FishNChips)
 		if err != nil {
 			return nil, err
 		}
-		stats[int(officeID.Int64)] = s
+		if _, ok := stats[start]; !ok {
+			stats[start] = make(map[int]*models.GlobalStatistics)
+		}
+		stats[start][int(officeID.Int64)] = s
 	}
-	all := new(models.GlobalStatistics)
-	for _, s := range stats {
-		all.FishNChips += s.FishNChips
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
-	stats[0] = all
-
 	return stats, nil
 }
@@ -121,7 +121,7 @@ func GetJDCPracticeStatisticsForMatch(id int) ([]*models.StatisticsJDCPractice,
 }
 
 // GetJDCPracticeStatisticsForPlayer will return JDC Practice statistics for the given player
-func GetJDCPracticeStatisticsForPlayer(id int) (*models.StatisticsJDCPractice, error) {
+func GetJDCPracticeStatisticsForPlayer(id int, versus []int) (*models.StatisticsJDCPractice, error) {
 	s := new(models.StatisticsJDCPractice)
 	err := models.DB.QueryRow(`
 			SELECT
@@ -142,7 +142,7 @@ func GetJDCPracticeStatisticsForPlayer(id int) (*models.StatisticsJDCPractice, e
 				JOIN matches m ON m.id = l.match_id
 				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-			WHERE s.player_id = ?
+			WHERE s.player_id = ?`+versusLegs(versus)+`
 				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 				AND m.match_type_id = 14
 			GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
@@ -131,7 +131,7 @@ func GetKillBullStatisticsForMatch(id int) ([]*models.StatisticsKillBull, error)
 }
 
 // GetKillBullStatisticsForPlayer will return Kill Bull statistics for the given player
-func GetKillBullStatisticsForPlayer(id int) (*models.StatisticsKillBull, error) {
+func GetKillBullStatisticsForPlayer(id int, versus []int) (*models.StatisticsKillBull, error) {
 	s := new(models.StatisticsKillBull)
 	err := models.DB.QueryRow(`
 		SELECT
@@ -155,7 +155,7 @@ func GetKillBullStatisticsForPlayer(id int) (*models.StatisticsKillBull, error)
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 12
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown, &s.Score, &s.Marks3, &s.Marks4, &s.Marks5, &s.Marks6,
@@ -120,7 +120,7 @@ func GetKnockoutStatisticsForMatch(id int) ([]*models.StatisticsKnockout, error)
 }
 
 // GetKnockoutStatisticsForPlayer will return Knockout statistics for the given player
-func GetKnockoutStatisticsForPlayer(id int) (*models.StatisticsKnockout, error) {
+func GetKnockoutStatisticsForPlayer(id int, versus []int) (*models.StatisticsKnockout, error) {
 	s := new(models.StatisticsKnockout)
 	err := models.DB.QueryRow(`
 			SELECT
@@ -140,7 +140,7 @@ func GetKnockoutStatisticsForPlayer(id int) (*models.StatisticsKnockout, error)
 				JOIN matches m ON m.id = l.match_id
 				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-			WHERE s.player_id = ?
+			WHERE s.player_id = ?`+versusLegs(versus)+`
 				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 				AND m.match_type_id = 15
 			GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
@@ -123,7 +123,7 @@ func GetScamStatisticsForMatch(id int) ([]*models.StatisticsScam, error) {
 }
 
 // GetScamStatisticsForPlayer will return Scam statistics for the given player
-func GetScamStatisticsForPlayer(id int) (*models.StatisticsScam, error) {
+func GetScamStatisticsForPlayer(id int, versus []int) (*models.StatisticsScam, error) {
 	s := new(models.StatisticsScam)
 	err := models.DB.QueryRow(`
 			SELECT
@@ -144,7 +144,7 @@ func GetScamStatisticsForPlayer(id int) (*models.StatisticsScam, error) {
 				JOIN matches m ON m.id = l.match_id
 				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-			WHERE s.player_id = ?
+			WHERE s.player_id = ?`+versusLegs(versus)+`
 				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 				AND m.match_type_id = 16
 			GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrownScorer, &s.DartsThrownStopper,
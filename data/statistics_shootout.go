@@ -123,7 +123,7 @@ func GetShootoutStatisticsForLeg(id int) ([]*models.StatisticsShootout, error) {
 }
 
 // GetShootoutStatisticsForPlayer will return Shootout statistics for the given player
-func GetShootoutStatisticsForPlayer(id int) (*models.StatisticsShootout, error) {
+func GetShootoutStatisticsForPlayer(id int, versus []int) (*models.StatisticsShootout, error) {
 	s := new(models.StatisticsShootout)
 	err := models.DB.QueryRow(`
 		SELECT
@@ -145,7 +145,7 @@ func GetShootoutStatisticsForPlayer(id int) (*models.StatisticsShootout, error)
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND (m.match_type_id = 2 OR l.leg_type_id = 2)
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.Score, &s.PPD, &s.Score60sPlus, &s.Score100sPlus, &s.Score140sPlus, &s.Score180s, &s.HighestScore)
@@ -117,7 +117,7 @@ func GetTicTacToeStatisticsForMatch(id int) ([]*models.StatisticsTicTacToe, erro
 }
 
 // GetTicTacToeStatisticsForPlayer will return statistics for the given player
-func GetTicTacToeStatisticsForPlayer(id int) (*models.StatisticsTicTacToe, error) {
+func GetTicTacToeStatisticsForPlayer(id int, versus []int) (*models.StatisticsTicTacToe, error) {
 	s := new(models.StatisticsTicTacToe)
 	err := models.DB.QueryRow(`
 		SELECT
@@ -136,7 +136,7 @@ func GetTicTacToeStatisticsForPlayer(id int) (*models.StatisticsTicTacToe, error
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = 9
 		GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.Score, &s.DartsThrown, &s.NumbersClosed, &s.HighestClosed)
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// GetVisitDistribution will return visit score distribution for all players for the given period
+func GetVisitDistribution(from string, to string, matchType int) ([]*models.StatisticsVisitDistribution, error) {
+	legs, err := getLegsWithVisits(`
+		WHERE m.updated_at >= ? AND m.updated_at < ?
+			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+			AND IFNULL(l.leg_type_id, m.match_type_id) = ?`, from, to, matchType)
+	if err != nil {
+		return nil, err
+	}
+	statistics := make([]*models.StatisticsVisitDistribution, 0)
+	for _, stats := range models.CalculateVisitDistribution(legs) {
+		statistics = append(statistics, stats)
+	}
+	return statistics, nil
+}
+
+// GetVisitDistributionForLeg will return visit score distribution for each player in the given leg
+func GetVisitDistributionForLeg(id int) (map[int]*models.StatisticsVisitDistribution, error) {
+	leg, err := GetLeg(id)
+	if err != nil {
+		return nil, err
+	}
+	return models.CalculateVisitDistribution([]*models.Leg{leg}), nil
+}
+
+// GetVisitDistributionForMatch will return visit score distribution for each player in the given match
+func GetVisitDistributionForMatch(id int) (map[int]*models.StatisticsVisitDistribution, error) {
+	legs, err := GetLegsForMatch(id)
+	if err != nil {
+		return nil, err
+	}
+	return models.CalculateVisitDistribution(legs), nil
+}
+
+// GetVisitDistributionForPlayer will return visit score distribution for the given player and match type
+func GetVisitDistributionForPlayer(playerID int, matchType int) (*models.StatisticsVisitDistribution, error) {
+	legs, err := getLegsWithVisits(`
+		WHERE s.player_id = ?
+			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+			AND IFNULL(l.leg_type_id, m.match_type_id) = ?`, playerID, matchType)
+	if err != nil {
+		return nil, err
+	}
+	statistics := models.CalculateVisitDistribution(legs)
+	if stats, ok := statistics[playerID]; ok {
+		return stats, nil
+	}
+	stats := models.NewStatisticsVisitDistribution(playerID)
+	stats.Calculate()
+	return stats, nil
+}
+
+// getLegsWithVisits will return all finished legs matching the given where clause, with visits loaded in the order they were thrown
+func getLegsWithVisits(where string, args ...interface{}) ([]*models.Leg, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			l.id, l.match_id, l.starting_score, l.winner_id, IFNULL(l.leg_type_id, m.match_type_id),
+			(SELECT GROUP_CONCAT(p2l.player_id ORDER BY p2l.order) FROM player2leg p2l WHERE p2l.leg_id = l.id) AS 'players',
+			s.id, s.player_id,
+			s.first_dart, s.first_dart_multiplier,
+			s.second_dart, s.second_dart_multiplier,
+			s.third_dart, s.third_dart_multiplier,
+			s.is_bust, s.created_at
+		FROM score s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		`+where+` AND l.is_finished = 1
+		ORDER BY l.id, s.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := make([]*models.Leg, 0)
+	var leg *models.Leg
+	for rows.Next() {
+		l := new(models.Leg)
+		l.LegType = new(models.MatchType)
+		var players string
+		v := new(models.Visit)
+		v.FirstDart = new(models.Dart)
+		v.SecondDart = new(models.Dart)
+		v.ThirdDart = new(models.Dart)
+		err := rows.Scan(&l.ID, &l.MatchID, &l.StartingScore, &l.WinnerPlayerID, &l.LegType.ID, &players,
+			&v.ID, &v.PlayerID,
+			&v.FirstDart.Value, &v.FirstDart.Multiplier,
+			&v.SecondDart.Value, &v.SecondDart.Multiplier,
+			&v.ThirdDart.Value, &v.ThirdDart.Multiplier,
+			&v.IsBust, &v.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		v.LegID = l.ID
+
+		if leg == nil || leg.ID != l.ID {
+			leg = l
+			leg.Players = util.StringToIntArray(players)
+			leg.Visits = make([]*models.Visit, 0)
+			legs = append(legs, leg)
+		}
+		leg.Visits = append(leg.Visits, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return legs, nil
+}
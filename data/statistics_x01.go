@@ -419,7 +419,7 @@ func GetPlayerProgression(id int) (map[string]*models.StatisticsX01, error) {
 }
 
 // GetX01StatisticsForPlayer will return X01 statistics for the given player
-func GetX01StatisticsForPlayer(id int, matchType int) (*models.StatisticsX01, error) {
+func GetX01StatisticsForPlayer(id int, matchType int, versus []int) (*models.StatisticsX01, error) {
 	s := new(models.StatisticsX01)
 	err := models.DB.QueryRow(`
 		SELECT
@@ -447,7 +447,7 @@ func GetX01StatisticsForPlayer(id int, matchType int) (*models.StatisticsX01, er
 			JOIN matches m ON m.id = l.match_id
 			LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
 			LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
-		WHERE s.player_id = ?
+		WHERE s.player_id = ?`+versusLegs(versus)+`
 			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND m.match_type_id = ?
 		GROUP BY p.id`, id, matchType).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.PPD, &s.FirstNinePPD, &s.ThreeDartAvg,
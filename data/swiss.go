@@ -0,0 +1,148 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// GenerateSwissTournament will create a new Swiss-system tournament with the given number of rounds, and generate the first round.
+// The preset is checked first, since matches can not be generated without it
+func GenerateSwissTournament(input models.SwissTournamentInput) (*models.SwissTournament, error) {
+	if !input.PresetID.Valid {
+		return nil, errors.New("tournament does not have a preset")
+	}
+	_, err := GetTournamentPreset(int(input.PresetID.Int64))
+	if err != nil {
+		return nil, err
+	}
+	tournament, err := NewTournament(models.Tournament{
+		Name:        input.Name,
+		ShortName:   input.ShortName,
+		IsPlayoffs:  false,
+		OfficeID:    input.OfficeID,
+		PresetID:    input.PresetID,
+		ManualAdmin: input.ManualAdmin,
+		Players:     input.Players,
+		StartTime:   null.TimeFrom(time.Now()),
+		EndTime:     null.TimeFrom(time.Now()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = models.DB.Exec(`INSERT INTO tournament_swiss (tournament_id, rounds) VALUES (?, ?)`, tournament.ID, input.Rounds)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Created Swiss tournament %d with %d rounds", tournament.ID, input.Rounds)
+	return GenerateNextSwissRound(tournament.ID)
+}
+
+// GetSwissTournament will return standings and pairings for the given Swiss-system tournament
+func GetSwissTournament(tournamentID int) (*models.SwissTournament, error) {
+	var rounds int
+	err := models.DB.QueryRow(`SELECT rounds FROM tournament_swiss WHERE tournament_id = ?`, tournamentID).Scan(&rounds)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT
+			r.round, r.match_id, r.home_player_id, r.away_player_id, IFNULL(m.is_finished, 1), m.winner_id
+		FROM tournament_swiss_round r
+			LEFT JOIN matches m ON m.id = r.match_id
+		WHERE r.tournament_id = ?
+		ORDER BY r.round, r.match_id`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*models.SwissMatch, 0)
+	for rows.Next() {
+		match := new(models.SwissMatch)
+		err := rows.Scan(&match.Round, &match.MatchID, &match.HomePlayerID, &match.AwayPlayerID, &match.IsFinished, &match.WinnerID)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	seeds, err := getSwissSeeds(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewSwissTournament(tournamentID, rounds, seeds, matches), nil
+}
+
+// GenerateNextSwissRound will generate matches for the next round of the given Swiss-system tournament,
+// once all matches in the current round are finished. The tournament is locked while generating, so a round is only generated once
+func GenerateNextSwissRound(tournamentID int) (*models.SwissTournament, error) {
+	tournament, err := GetTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	preset := tournament.Preset
+	if preset == nil {
+		return nil, errors.New("tournament does not have a preset")
+	}
+
+	var round, pairings int
+	err = models.Transaction(models.DB, func(tx *sql.Tx) error {
+		var rounds int
+		err := tx.QueryRow(`SELECT rounds FROM tournament_swiss WHERE tournament_id = ? FOR UPDATE`, tournamentID).Scan(&rounds)
+		if err != nil {
+			return err
+		}
+		swiss, err := GetSwissTournament(tournamentID)
+		if err != nil {
+			return err
+		}
+		next, err := swiss.GetNextRound()
+		if err != nil {
+			return err
+		}
+		for _, pairing := range next {
+			if !pairing.IsBye() {
+				matchID, err := insertMatch(tx, getTournamentMatch(tournamentID, []int{pairing.HomePlayerID, int(pairing.AwayPlayerID.Int64)},
+					preset.StartingScore, preset.OutshotType, tournament.OfficeID, preset.MatchType, preset.MatchMode))
+				if err != nil {
+					return err
+				}
+				pairing.MatchID = null.IntFrom(matchID)
+			}
+			_, err = tx.Exec(`INSERT INTO tournament_swiss_round (tournament_id, round, match_id, home_player_id, away_player_id) VALUES (?, ?, ?, ?, ?)`,
+				tournamentID, pairing.Round, pairing.MatchID, pairing.HomePlayerID, pairing.AwayPlayerID)
+			if err != nil {
+				return err
+			}
+		}
+		round, pairings = swiss.CurrentRound+1, len(next)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Generated round %d with %d pairings for Swiss tournament %d", round, pairings, tournamentID)
+	return GetSwissTournament(tournamentID)
+}
+
+// getSwissSeeds will return all players in the given tournament, ordered by Elo
+func getSwissSeeds(tournamentID int) ([]int, error) {
+	players, err := GetTournamentPlayers(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0)
+	for _, player := range players {
+		ids = append(ids, player.ID)
+	}
+	return sortPlayersByElo(ids)
+}
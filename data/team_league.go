@@ -0,0 +1,413 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// NewTeamLeague will create a new team league, with a tournament holding all matches, and generate the fixtures between the offices
+func NewTeamLeague(league models.TeamLeague) (*models.TeamLeague, error) {
+	preset, err := GetTournamentPreset(league.PresetID)
+	if err != nil {
+		return nil, err
+	}
+	offices := league.GetOffices()
+
+	players := make([]*models.Player2Tournament, 0)
+	for _, player := range league.Roster {
+		players = append(players, &models.Player2Tournament{PlayerID: player.PlayerID, TournamentGroupID: preset.Group1TournamentGroup.ID})
+	}
+	var leagueID int64
+	err = models.Transaction(models.DB, func(tx *sql.Tx) error {
+		tournamentID, err := insertTournament(tx, models.Tournament{
+			Name:      league.Name,
+			ShortName: league.ShortName,
+			OfficeID:  offices[0],
+			PresetID:  null.IntFrom(int64(league.PresetID)),
+			Players:   players,
+			StartTime: null.TimeFrom(time.Now()),
+			EndTime:   null.TimeFrom(time.Now()),
+		})
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(`INSERT INTO team_league (name, short_name, tournament_id, preset_id, format, created_at) VALUES (?, ?, ?, ?, ?, NOW())`,
+			league.Name, league.ShortName, tournamentID, league.PresetID, strings.Join(league.Format, ","))
+		if err != nil {
+			return err
+		}
+		leagueID, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, player := range league.Roster {
+			_, err = tx.Exec(`INSERT INTO team_league_roster (league_id, office_id, player_id, is_captain) VALUES (?, ?, ?, ?)`,
+				leagueID, player.OfficeID, player.PlayerID, player.IsCaptain)
+			if err != nil {
+				return err
+			}
+		}
+		for _, fixture := range models.NewTeamFixtures(int(leagueID), offices) {
+			res, err := tx.Exec(`INSERT INTO team_fixture (league_id, round, home_office_id, away_office_id) VALUES (?, ?, ?, ?)`,
+				leagueID, fixture.Round, fixture.HomeOfficeID, fixture.AwayOfficeID)
+			if err != nil {
+				return err
+			}
+			fixtureID, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			for i, rubberType := range league.Format {
+				_, err = tx.Exec(`INSERT INTO team_fixture_rubber (fixture_id, rubber, type) VALUES (?, ?, ?)`, fixtureID, i+1, rubberType)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Created team league %d (%s) for offices %v", leagueID, league.Name, offices)
+	return GetTeamLeague(int(leagueID))
+}
+
+// GetTeamLeagues will return all team leagues
+func GetTeamLeagues() ([]*models.TeamLeague, error) {
+	rows, err := models.DB.Query(`SELECT id FROM team_league ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	leagues := make([]*models.TeamLeague, 0)
+	for _, id := range ids {
+		league, err := GetTeamLeague(id)
+		if err != nil {
+			return nil, err
+		}
+		leagues = append(leagues, league)
+	}
+	return leagues, nil
+}
+
+// GetTeamLeague will return the team league with the given ID, with the roster of each office
+func GetTeamLeague(id int) (*models.TeamLeague, error) {
+	league := new(models.TeamLeague)
+	var format string
+	err := models.DB.QueryRow(`SELECT id, name, short_name, tournament_id, preset_id, format, created_at FROM team_league WHERE id = ?`, id).
+		Scan(&league.ID, &league.Name, &league.ShortName, &league.TournamentID, &league.PresetID, &format, &league.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	league.Format = strings.Split(format, ",")
+
+	rows, err := models.DB.Query(`
+		SELECT office_id, player_id, is_captain
+		FROM team_league_roster
+		WHERE league_id = ?
+		ORDER BY office_id, is_captain DESC, player_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	league.Roster = make([]*models.TeamRosterPlayer, 0)
+	for rows.Next() {
+		player := new(models.TeamRosterPlayer)
+		err := rows.Scan(&player.OfficeID, &player.PlayerID, &player.IsCaptain)
+		if err != nil {
+			return nil, err
+		}
+		league.Roster = append(league.Roster, player)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	league.Offices = league.GetOffices()
+	return league, nil
+}
+
+// GetTeamFixtures will return all fixtures of the given team league, with results
+func GetTeamFixtures(leagueID int) ([]*models.TeamFixture, error) {
+	return getTeamFixtures("f.league_id = ?", leagueID)
+}
+
+// GetTeamFixture will return the given fixture, with results
+func GetTeamFixture(id int) (*models.TeamFixture, error) {
+	fixtures, err := getTeamFixtures("f.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixtures) == 0 {
+		return nil, errors.New("fixture not found")
+	}
+	return fixtures[0], nil
+}
+
+// SubmitTeamLineup will store the lineup picked by the captain of one of the offices in the fixture.
+// Lineups are hidden until both captains have submitted, and the matches for all rubbers are then created.
+// The fixture is locked while the lineup is stored, so concurrent submissions can not create the matches twice
+func SubmitTeamLineup(fixtureID int, lineup models.TeamLineup) (*models.TeamFixture, error) {
+	fixture, err := GetTeamFixture(fixtureID)
+	if err != nil {
+		return nil, err
+	}
+	league, err := GetTeamLeague(fixture.LeagueID)
+	if err != nil {
+		return nil, err
+	}
+	lineup.FixtureID = fixtureID
+	err = league.ValidateLineup(fixture, &lineup)
+	if err != nil {
+		return nil, err
+	}
+	preset, err := GetTournamentPreset(league.PresetID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.Transaction(models.DB, func(tx *sql.Tx) error {
+		var id int
+		err := tx.QueryRow(`SELECT id FROM team_fixture WHERE id = ? FOR UPDATE`, fixtureID).Scan(&id)
+		if err != nil {
+			return err
+		}
+		var matches int
+		err = tx.QueryRow(`SELECT COUNT(match_id) FROM team_fixture_rubber WHERE fixture_id = ?`, fixtureID).Scan(&matches)
+		if err != nil {
+			return err
+		}
+		if matches > 0 {
+			return errors.New("lineups can not be changed after matches are created")
+		}
+
+		_, err = tx.Exec(`DELETE FROM team_fixture_lineup WHERE fixture_id = ? AND office_id = ?`, fixtureID, lineup.OfficeID)
+		if err != nil {
+			return err
+		}
+		for i, players := range lineup.Rubbers {
+			for position, playerID := range players {
+				_, err = tx.Exec(`
+					INSERT INTO team_fixture_lineup (fixture_id, office_id, rubber, position, player_id, submitted_by, submitted_at)
+					VALUES (?, ?, ?, ?, ?, ?, NOW())`, fixtureID, lineup.OfficeID, i+1, position, playerID, lineup.SubmittedBy)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return createTeamFixtureMatches(tx, league, preset, fixture)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Player %d submitted lineup for office %d in fixture %d", lineup.SubmittedBy, lineup.OfficeID, fixtureID)
+	return GetTeamFixture(fixtureID)
+}
+
+// GetTeamLeagueStandings will return the league table of the given team league
+func GetTeamLeagueStandings(leagueID int) ([]*models.TeamLeagueStanding, error) {
+	league, err := GetTeamLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := GetTeamFixtures(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return models.GetTeamLeagueStandings(league.Offices, fixtures), nil
+}
+
+// GetTeamLeagueAppearances will return the appearance record of all rostered players in the given team league
+func GetTeamLeagueAppearances(leagueID int) ([]*models.TeamAppearance, error) {
+	league, err := GetTeamLeague(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := GetTeamFixtures(leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return models.GetTeamAppearances(league, fixtures), nil
+}
+
+// createTeamFixtureMatches will create a tournament match for each rubber of the given fixture using the given transaction,
+// if both offices have submitted their lineup
+func createTeamFixtureMatches(tx *sql.Tx, league *models.TeamLeague, preset *models.TournamentPreset, fixture *models.TeamFixture) error {
+	rows, err := tx.Query(`
+		SELECT office_id, rubber, player_id
+		FROM team_fixture_lineup
+		WHERE fixture_id = ?
+		ORDER BY office_id, rubber, position`, fixture.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	lineups := make(map[int]map[int][]int)
+	for rows.Next() {
+		var officeID, rubber, playerID int
+		err := rows.Scan(&officeID, &rubber, &playerID)
+		if err != nil {
+			return err
+		}
+		if lineups[officeID] == nil {
+			lineups[officeID] = make(map[int][]int)
+		}
+		lineups[officeID][rubber] = append(lineups[officeID][rubber], playerID)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	home := lineups[fixture.HomeOfficeID]
+	away := lineups[fixture.AwayOfficeID]
+	if home == nil || away == nil {
+		return nil
+	}
+
+	for _, rubber := range fixture.Rubbers {
+		rubber.HomePlayers = home[rubber.Rubber]
+		rubber.AwayPlayers = away[rubber.Rubber]
+		matchID, err := insertMatch(tx, getTournamentMatch(league.TournamentID, rubber.GetMatchPlayers(), preset.StartingScore, preset.OutshotType,
+			fixture.HomeOfficeID, preset.MatchType, preset.MatchMode))
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE team_fixture_rubber SET match_id = ? WHERE fixture_id = ? AND rubber = ?`, matchID, fixture.ID, rubber.Rubber)
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("Created %d matches for fixture %d", len(fixture.Rubbers), fixture.ID)
+	return nil
+}
+
+// getTeamFixtures will return all fixtures matching the given condition, with rubbers, lineups and results
+func getTeamFixtures(condition string, arg int) ([]*models.TeamFixture, error) {
+	rows, err := models.DB.Query(`
+		SELECT f.id, f.league_id, f.round, f.home_office_id, f.away_office_id
+		FROM team_fixture f
+		WHERE `+condition+`
+		ORDER BY f.round, f.id`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fixtures := make([]*models.TeamFixture, 0)
+	fixtureMap := make(map[int]*models.TeamFixture)
+	for rows.Next() {
+		fixture := new(models.TeamFixture)
+		err := rows.Scan(&fixture.ID, &fixture.LeagueID, &fixture.Round, &fixture.HomeOfficeID, &fixture.AwayOfficeID)
+		if err != nil {
+			return nil, err
+		}
+		fixture.Rubbers = make([]*models.TeamRubber, 0)
+		fixtures = append(fixtures, fixture)
+		fixtureMap[fixture.ID] = fixture
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = models.DB.Query(`
+		SELECT r.fixture_id, r.rubber, r.type, r.match_id, IFNULL(m.is_finished, 0),
+			(SELECT GROUP_CONCAT(l.winner_id) FROM leg l WHERE l.match_id = r.match_id AND l.is_finished = 1 AND l.winner_id IS NOT NULL)
+		FROM team_fixture_rubber r
+			JOIN team_fixture f ON f.id = r.fixture_id
+			LEFT JOIN matches m ON m.id = r.match_id
+		WHERE `+condition+`
+		ORDER BY r.fixture_id, r.rubber`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	winners := make(map[*models.TeamRubber][]int)
+	for rows.Next() {
+		var fixtureID int
+		var legWinners null.String
+		rubber := new(models.TeamRubber)
+		err := rows.Scan(&fixtureID, &rubber.Rubber, &rubber.Type, &rubber.MatchID, &rubber.IsFinished, &legWinners)
+		if err != nil {
+			return nil, err
+		}
+		rubber.HomePlayers = make([]int, 0)
+		rubber.AwayPlayers = make([]int, 0)
+		if legWinners.Valid {
+			winners[rubber] = util.StringToIntArray(legWinners.String)
+		}
+		fixtureMap[fixtureID].Rubbers = append(fixtureMap[fixtureID].Rubbers, rubber)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = models.DB.Query(`
+		SELECT tl.fixture_id, tl.office_id, tl.rubber, tl.player_id
+		FROM team_fixture_lineup tl
+			JOIN team_fixture f ON f.id = tl.fixture_id
+		WHERE `+condition+`
+		ORDER BY tl.fixture_id, tl.office_id, tl.rubber, tl.position`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lineups := make(map[int]map[int]map[int][]int)
+	for rows.Next() {
+		var fixtureID, officeID, rubber, playerID int
+		err := rows.Scan(&fixtureID, &officeID, &rubber, &playerID)
+		if err != nil {
+			return nil, err
+		}
+		if lineups[fixtureID] == nil {
+			lineups[fixtureID] = make(map[int]map[int][]int)
+		}
+		if lineups[fixtureID][officeID] == nil {
+			lineups[fixtureID][officeID] = make(map[int][]int)
+		}
+		lineups[fixtureID][officeID][rubber] = append(lineups[fixtureID][officeID][rubber], playerID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, fixture := range fixtures {
+		home := lineups[fixture.ID][fixture.HomeOfficeID]
+		away := lineups[fixture.ID][fixture.AwayOfficeID]
+		fixture.HomeLineupSubmitted = home != nil
+		fixture.AwayLineupSubmitted = away != nil
+		if fixture.HomeLineupSubmitted && fixture.AwayLineupSubmitted {
+			// Lineups are only revealed once both captains have submitted
+			for _, rubber := range fixture.Rubbers {
+				rubber.HomePlayers = home[rubber.Rubber]
+				rubber.AwayPlayers = away[rubber.Rubber]
+				rubber.SetLegs(winners[rubber])
+			}
+		}
+		fixture.SetResult()
+	}
+	return fixtures, nil
+}
@@ -2,8 +2,11 @@ package data
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/guregu/null"
@@ -300,7 +303,7 @@ func GetTournamentPlayers(id int) ([]*models.Player, error) {
 }
 
 // GetTournamentProbabilities will return all matches for the given tournament with winning probabilities for players
-func GetTournamentProbabilities(id int) ([]*models.Probability, error) {
+func GetTournamentProbabilities(id int, system string) ([]*models.Probability, error) {
 	rows, err := models.DB.Query(`
 		SELECT
 			m.id, m.created_at, m.updated_at, IF(TIMEDIFF(MAX(l.updated_at), NOW() - INTERVAL 15 MINUTE) > 0, 1, 0) AS 'is_started',
@@ -308,7 +311,7 @@ func GetTournamentProbabilities(id int) ([]*models.Probability, error) {
 			GROUP_CONCAT(DISTINCT p2l.player_id ORDER BY p2l.order) AS 'players',
 			GROUP_CONCAT(DISTINCT pe.current_elo ORDER BY p2l.order) AS 'elos',
 			(MAX(p.is_placeholder) - 1) * -1 AS 'is_players_decided',
-			mm.is_draw_possible
+			mm.is_draw_possible, m.match_type_id
 		FROM matches m
 			JOIN player2leg p2l ON p2l.match_id = m.id
 			LEFT JOIN leg l ON l.match_id = m.id
@@ -329,8 +332,9 @@ func GetTournamentProbabilities(id int) ([]*models.Probability, error) {
 		var players string
 		var elos string
 		var isDrawPossible bool
+		var matchTypeID int
 		err := rows.Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt, &p.IsStarted, &p.IsFinished, &p.IsAbandoned, &p.IsWalkover, &p.WinnerID,
-			&players, &elos, &p.IsPlayersDecided, &isDrawPossible)
+			&players, &elos, &p.IsPlayersDecided, &isDrawPossible, &matchTypeID)
 		if err != nil {
 			return nil, err
 		}
@@ -345,9 +349,10 @@ func GetTournamentProbabilities(id int) ([]*models.Probability, error) {
 			p.Players[1]: playerElos[1],
 		}
 
-		pHome := GetPlayerWinProbability(playerElos[0], playerElos[1])
-		pAway := GetPlayerWinProbability(playerElos[1], playerElos[0])
-		probDraw := GetPlayerDrawProbability(playerElos[0], playerElos[1])
+		pHome, pAway, probDraw, err := getWinProbabilities(p, playerElos, system, matchTypeID)
+		if err != nil {
+			return nil, err
+		}
 
 		if isDrawPossible {
 			pHome = pHome * (1 - probDraw)
@@ -382,7 +387,7 @@ func GetTournamentOverview(id int) (map[int][]*models.TournamentOverview, error)
 			t.id, t.name, t.short_name, t.start_time, t.end_time,
 			tg.id, tg.name, tg.division,
 			p.id AS 'player_id',
-			p2t.is_promoted, p2t.is_relegated, p2t.is_winner, p2t.manual_order,
+			p2t.is_promoted, p2t.is_relegated, p2t.is_winner, p2t.is_withdrawn, p2t.manual_order,
 			COUNT(DISTINCT finished.id) AS 'p',
 			COUNT(DISTINCT won.id) AS 'w',
 			COUNT(DISTINCT draw.id) AS 'd',
@@ -418,7 +423,7 @@ func GetTournamentOverview(id int) (map[int][]*models.TournamentOverview, error)
 			JOIN player2tournament p2t ON p2t.player_id = p.id AND p2t.tournament_id = t.id
 			JOIN tournament_group tg ON tg.id = p2t.tournament_group_id
 		WHERE m.tournament_id = ? AND m.match_type_id = 1
-			AND m.is_bye <> 1
+			AND m.is_bye <> 1 AND m.is_abandoned = 0 AND NOT `+tournamentMatchAnnulled+`
 		GROUP BY p2l.player_id, tg.id
 		ORDER BY tg.division, pts DESC, diff DESC, is_relegated, manual_order`, id)
 	if err != nil {
@@ -431,7 +436,7 @@ func GetTournamentOverview(id int) (map[int][]*models.TournamentOverview, error)
 		group := new(models.TournamentGroup)
 		stats := new(models.TournamentOverview)
 		err := rows.Scan(&tournament.ID, &tournament.Name, &tournament.ShortName, &tournament.StartTime, &tournament.EndTime, &group.ID,
-			&group.Name, &group.Division, &stats.PlayerID, &stats.IsPromoted, &stats.IsRelegated, &stats.IsWinner, &stats.ManualOrder, &stats.Played, &stats.MatchesWon,
+			&group.Name, &group.Division, &stats.PlayerID, &stats.IsPromoted, &stats.IsRelegated, &stats.IsWinner, &stats.IsWithdrawn, &stats.ManualOrder, &stats.Played, &stats.MatchesWon,
 			&stats.MatchesDraw, &stats.MatchesLost, &stats.LegsFor, &stats.LegsAgainst, &stats.LegsDifference, &stats.Points, &stats.PPD,
 			&stats.FirstNinePPD, &stats.ThreeDartAvg, &stats.FirstNineThreeDartAvg, &stats.Score60sPlus, &stats.Score100sPlus, &stats.Score140sPlus,
 			&stats.Score180s, &stats.Accuracy20, &stats.Accuracy19, &stats.AccuracyOverall, &stats.CheckoutAttempts, &stats.CheckoutPercentage)
@@ -449,9 +454,92 @@ func GetTournamentOverview(id int) (map[int][]*models.TournamentOverview, error)
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+	if len(statistics) == 0 {
+		return statistics, nil
+	}
+
+	// Apply the tiebreak rules of the tournament, to explain how tied players were separated
+	rules, err := getTournamentTiebreakRules(id)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := getTiebreakMatches(id)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0)
+	for _, group := range statistics {
+		for _, stats := range group {
+			ids = append(ids, stats.PlayerID)
+		}
+	}
+	playerElos, err := GetPlayersElo(ids...)
+	if err != nil {
+		return nil, err
+	}
+	elos := make(map[int]int)
+	for _, elo := range playerElos {
+		elos[elo.PlayerID] = elo.CurrentElo
+	}
+	for groupID, group := range statistics {
+		sorted := models.SortStandings(group, matches, elos, rules)
+		// Withdrawn players are always placed at the bottom of the group
+		sort.SliceStable(sorted, func(i, j int) bool { return !sorted[i].IsWithdrawn && sorted[j].IsWithdrawn })
+		statistics[groupID] = sorted
+	}
 	return statistics, nil
 }
 
+// getTournamentTiebreakRules will return the tiebreak rules configured for the preset of the given tournament
+func getTournamentTiebreakRules(tournamentID int) ([]string, error) {
+	var rules null.String
+	err := models.DB.QueryRow(`
+		SELECT tp.tiebreak_rules
+		FROM tournament t
+			LEFT JOIN tournament_preset tp ON tp.id = t.preset_id
+		WHERE t.id = ?`, tournamentID).Scan(&rules)
+	if err != nil {
+		return nil, err
+	}
+	return parseTiebreakRules(rules)
+}
+
+// getTiebreakMatches will return the results of all group matches in the given tournament
+func getTiebreakMatches(tournamentID int) ([]*models.TiebreakMatch, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			m.is_finished, m.winner_id, GROUP_CONCAT(DISTINCT p2l.player_id ORDER BY p2l.order) AS 'players'
+		FROM matches m
+			JOIN player2leg p2l ON p2l.match_id = m.id
+		WHERE m.tournament_id = ? AND m.match_type_id = 1 AND m.is_bye <> 1 AND m.is_abandoned = 0 AND NOT `+tournamentMatchAnnulled+`
+		GROUP BY m.id`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*models.TiebreakMatch, 0)
+	for rows.Next() {
+		var players string
+		match := new(models.TiebreakMatch)
+		err := rows.Scan(&match.IsFinished, &match.WinnerID, &players)
+		if err != nil {
+			return nil, err
+		}
+		ids := util.StringToIntArray(players)
+		if len(ids) != 2 {
+			continue
+		}
+		match.HomePlayerID = ids[0]
+		match.AwayPlayerID = ids[1]
+		matches = append(matches, match)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
 // GetTournamentStatistics will return statistics for the given tournament
 func GetTournamentStatistics(tournamentID int) (*models.TournamentStatistics, error) {
 	statistics := new(models.TournamentStatistics)
@@ -505,7 +593,7 @@ func GetNextTournamentMatch(matchID int) (*models.Match, error) {
 	return GetMatch(int(nextMatchID.Int64))
 }
 
-// GetTournamentStandings will return elo standings for all players
+// GetTournamentStandings will return elo standings for all players, excluding players with a provisional rating
 func GetTournamentStandings() ([]*models.TournamentStanding, error) {
 	rows, err := models.DB.Query(`
 		SELECT player_id, first_name, tournament_elo, tournament_elo_matches, current_elo, current_elo_matches,
@@ -519,9 +607,9 @@ func GetTournamentStandings() ([]*models.TournamentStanding, error) {
 					pe.current_elo_matches
 				FROM player_elo pe
 				JOIN player p ON p.id = pe.player_id
-				WHERE pe.current_elo_matches > 5 AND p.active = 1
+				WHERE pe.current_elo_matches >= ? AND p.active = 1
 				ORDER BY tournament_elo DESC
-		) elo, (SELECT @curRank := 0) r`)
+		) elo, (SELECT @curRank := 0) r`, models.EloSettings.ProvisionalMatches)
 	if err != nil {
 		return nil, err
 	}
@@ -761,35 +849,37 @@ func NewTournament(tournament models.Tournament) (*models.Tournament, error) {
 	if err != nil {
 		return nil, err
 	}
+	tournamentID, err := insertTournament(tx, tournament)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+	log.Printf("Created new tournament %d", tournamentID)
+	return GetTournament(int(tournamentID))
+}
 
+// insertTournament will insert a new tournament with the given players using the given transaction
+func insertTournament(tx *sql.Tx, tournament models.Tournament) (int64, error) {
 	res, err := tx.Exec(`
 		INSERT INTO tournament (name, short_name, is_finished, is_playoffs, playoffs_tournament_id, preset_id, manual_admin, office_id, start_time, end_time) VALUES
 		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, tournament.Name, tournament.ShortName, 0, tournament.IsPlayoffs, tournament.PlayoffsTournamentID, tournament.PresetID,
 		tournament.ManualAdmin, tournament.OfficeID, tournament.StartTime, tournament.EndTime)
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return 0, err
 	}
 	tournamentID, err := res.LastInsertId()
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return 0, err
 	}
 	for _, player := range tournament.Players {
 		_, err = tx.Exec(`INSERT INTO player2tournament (player_id, tournament_id, tournament_group_id) VALUES (?, ?, ?)`,
 			player.PlayerID, tournamentID, player.TournamentGroupID)
 		if err != nil {
-			tx.Rollback()
-			return nil, err
+			return 0, err
 		}
 	}
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-	tx.Commit()
-	log.Printf("Created new tournament %d", tournamentID)
-	return GetTournament(int(tournamentID))
+	return tournamentID, nil
 }
 
 // GenerateTournament generates a new tournament
@@ -799,6 +889,14 @@ func GenerateTournament(input models.Tournament) (*models.Tournament, error) {
 		return nil, err
 	}
 
+	groups := make(map[int]bool)
+	for _, player := range input.Players {
+		groups[player.TournamentGroupID] = true
+	}
+	if len(groups) > preset.GroupCount {
+		return nil, fmt.Errorf("preset allows %d group(s), but players are in %d groups", preset.GroupCount, len(groups))
+	}
+
 	officeID := input.OfficeID
 	tournament, err := NewTournament(models.Tournament{
 		Name:        input.Name,
@@ -833,7 +931,7 @@ func GenerateTournament(input models.Tournament) (*models.Tournament, error) {
 				Players:      []int{players[i].PlayerID, players[j].PlayerID},
 				Legs: []*models.Leg{{
 					StartingScore: preset.StartingScore,
-					Parameters:    &models.LegParameters{OutshotType: &models.OutshotType{ID: models.OUTSHOTDOUBLE}}}},
+					Parameters:    &models.LegParameters{OutshotType: preset.OutshotType}}},
 			})
 			if err != nil {
 				return nil, err
@@ -855,6 +953,7 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 	if err != nil {
 		return nil, err
 	}
+	overview = removeWithdrawnPlayers(overview)
 
 	// Get all players for the tournament
 	keys := make([]int, 0)
@@ -910,7 +1009,7 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 
 	matches := make([]*models.Match, 0)
 	// Create Grand Final
-	match, err := createTournamentMatch(playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, models.X01,
+	match, err := createTournamentMatch(playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, preset.OutshotType, models.X01,
 		tournament.OfficeID, mt, preset.MatchModeGrandFinal)
 	if err != nil {
 		return nil, err
@@ -919,7 +1018,7 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 
 	// Create Semi Final Matches
 	if numPlayers > 4 {
-		semis, err := createTournamentMatches(2, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, models.X01,
+		semis, err := createTournamentMatches(2, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, preset.OutshotType, models.X01,
 			tournament.OfficeID, mt, preset.MatchModeSemiFinal)
 		if err != nil {
 			return nil, err
@@ -945,7 +1044,7 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 				// Walkover, so use placeholder
 				away = walkoverPlayerID
 			}
-			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, models.X01,
+			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, preset.OutshotType, models.X01,
 				tournament.OfficeID, mt, preset.MatchModeSemiFinal)
 			if err != nil {
 				return nil, err
@@ -956,7 +1055,7 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 
 	// Create Quarter Final Matches
 	if numPlayers > 8 {
-		quarters, err := createTournamentMatches(4, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, models.X01,
+		quarters, err := createTournamentMatches(4, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, preset.OutshotType, models.X01,
 			tournament.OfficeID, mt, preset.MatchModeQuarterFinal)
 		if err != nil {
 			return nil, err
@@ -976,7 +1075,7 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 				// Walkover, so use placeholder
 				away = walkoverPlayerID
 			}
-			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, models.X01,
+			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, preset.OutshotType, models.X01,
 				tournament.OfficeID, mt, preset.MatchModeLast16)
 			if err != nil {
 				return nil, err
@@ -1003,7 +1102,7 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 				// Walkover, so use placeholder
 				away = walkoverPlayerID
 			}
-			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, models.X01,
+			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, preset.OutshotType, models.X01,
 				tournament.OfficeID, mt, preset.MatchModeQuarterFinal)
 			if err != nil {
 				return nil, err
@@ -1091,10 +1190,195 @@ func GeneratePlayoffsTournament(tournamentID int) (*models.Tournament, error) {
 	return GetTournament(playoffs.ID)
 }
 
-func createTournamentMatches(num int, tournamentID int, players []int, startingScore int, venueID int, officeID int, matchType *models.MatchType, matchMode *models.MatchMode) ([]*models.Match, error) {
+// GenerateBracketTournament generates a single or double elimination playoffs tournament for the given tournament,
+// for any number of players. Players are seeded by Elo, by group position or in the given order
+func GenerateBracketTournament(tournamentID int, options *models.BracketOptions) (*models.Tournament, error) {
+	tournament, err := GetTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	preset := tournament.Preset
+	if preset == nil {
+		return nil, errors.New("tournament does not have a preset")
+	}
+
+	seeds, err := getBracketSeeds(tournamentID, preset, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(options.Players) == 0 && preset.PlayoffsSize > 0 && len(seeds) > preset.PlayoffsSize {
+		// Only the top seeds qualify for the playoffs
+		seeds = seeds[:preset.PlayoffsSize]
+	}
+	bracket, err := models.NewBracket(seeds, options)
+	if err != nil {
+		return nil, err
+	}
+
+	playoffsGroupID := preset.PlayoffsTournamentGroup.ID
+	placeholderHomeID := preset.PlayerIDPlaceholderHome
+	placeholderAwayID := preset.PlayerIDPlaceholderAway
+
+	players := make([]*models.Player2Tournament, 0)
+	for _, playerID := range seeds {
+		players = append(players, &models.Player2Tournament{PlayerID: playerID, TournamentGroupID: playoffsGroupID})
+	}
+	players = append(players,
+		&models.Player2Tournament{PlayerID: placeholderHomeID, TournamentGroupID: playoffsGroupID},
+		&models.Player2Tournament{PlayerID: placeholderAwayID, TournamentGroupID: playoffsGroupID})
+
+	playoffs, err := NewTournament(models.Tournament{
+		Name:        tournament.Name + " Playoffs",
+		ShortName:   tournament.ShortName,
+		IsPlayoffs:  true,
+		OfficeID:    tournament.OfficeID,
+		Players:     players,
+		PresetID:    tournament.PresetID,
+		StartTime:   null.TimeFrom(time.Now()),
+		EndTime:     null.TimeFrom(time.Now()),
+		ManualAdmin: tournament.ManualAdmin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = models.DB.Exec(`UPDATE tournament SET playoffs_tournament_id = ? WHERE id = ?`, playoffs.ID, tournament.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create all matches first, so that outcomes can refer to them
+	matches := make([]*models.Match, len(bracket.Matches))
+	for _, bm := range bracket.Matches {
+		home := placeholderHomeID
+		if bm.Home.MatchIdx == -1 {
+			home = bm.Home.PlayerID
+		}
+		away := placeholderAwayID
+		if bm.Away.MatchIdx == -1 {
+			away = bm.Away.PlayerID
+		}
+		matchMode := preset.MatchMode
+		if bm.IsGrandFinal {
+			matchMode = preset.MatchModeGrandFinal
+		} else if bm.Bracket == models.BracketWinners {
+			switch bm.RoundSize {
+			case 2:
+				matchMode = preset.MatchModeSemiFinal
+			case 4:
+				matchMode = preset.MatchModeQuarterFinal
+			case 8:
+				matchMode = preset.MatchModeLast16
+			}
+		}
+		match, err := createTournamentMatch(playoffs.ID, []int{home, away}, preset.StartingScore, preset.OutshotType, models.X01, tournament.OfficeID,
+			preset.MatchType, matchMode)
+		if err != nil {
+			return nil, err
+		}
+		matches[bm.Idx] = match
+	}
+
+	tg := &models.TournamentGroup{ID: playoffsGroupID}
+	metadata := make([]*models.MatchMetadata, 0)
+	for i, bm := range bracket.GetOrderOfPlay() {
+		m := &models.MatchMetadata{MatchID: matches[bm.Idx].ID, OrderOfPlay: i + 1, TournamentGroup: tg, MatchDisplayname: bm.Name,
+			SemiFinal: bm.IsSemiFinal, GrandFinal: bm.IsGrandFinal}
+		if bm.WinnerTo != -1 {
+			m.WinnerOutcomeMatchID = null.IntFrom(int64(matches[bm.WinnerTo].ID))
+			m.IsWinnerOutcomeHome = bm.IsWinnerToHome
+		}
+		if bm.LooserTo != -1 {
+			m.LooserOutcomeMatchID = null.IntFrom(int64(matches[bm.LooserTo].ID))
+			m.IsLooserOutcomeHome = bm.IsLooserToHome
+		}
+		metadata = append(metadata, m)
+	}
+	err = insertMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Generated %s elimination bracket with %d matches for tournament %d", bracket.Type, len(matches), playoffs.ID)
+	return GetTournament(playoffs.ID)
+}
+
+// getBracketSeeds will return the players of the given tournament ordered by seed
+func getBracketSeeds(tournamentID int, preset *models.TournamentPreset, options *models.BracketOptions) ([]int, error) {
+	switch options.Seeding {
+	case models.BracketSeedingManual:
+		return options.Players, nil
+	case models.BracketSeedingGroup:
+		overview, err := GetTournamentOverview(tournamentID)
+		if err != nil {
+			return nil, err
+		}
+		overview = removeWithdrawnPlayers(overview)
+		groups := make([]int, 0)
+		for groupID := range overview {
+			groups = append(groups, groupID)
+		}
+		sort.Ints(groups)
+
+		// Group winners are seeded first, then all runners-up etc.
+		seeds := make([]int, 0)
+		for position := 0; ; position++ {
+			added := false
+			for _, groupID := range groups {
+				if position < len(overview[groupID]) {
+					seeds = append(seeds, overview[groupID][position].PlayerID)
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+		return seeds, nil
+	case models.BracketSeedingElo, "":
+		players := options.Players
+		if len(players) == 0 {
+			tournamentPlayers, err := GetTournamentPlayers(tournamentID)
+			if err != nil {
+				return nil, err
+			}
+			withdrawn, err := getWithdrawnPlayers(tournamentID)
+			if err != nil {
+				return nil, err
+			}
+			for _, player := range tournamentPlayers {
+				if withdrawn[player.ID] || player.ID == preset.PlayerIDWalkover || player.ID == preset.PlayerIDPlaceholderHome || player.ID == preset.PlayerIDPlaceholderAway {
+					continue
+				}
+				players = append(players, player.ID)
+			}
+		}
+		return sortPlayersByElo(players)
+	}
+	return nil, fmt.Errorf("unknown seeding '%s'", options.Seeding)
+}
+
+// sortPlayersByElo will return the given players ordered by current Elo, highest first
+func sortPlayersByElo(players []int) ([]int, error) {
+	if len(players) == 0 {
+		return players, nil
+	}
+	elos, err := GetPlayersElo(players...)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(elos, func(i, j int) bool {
+		return elos[i].CurrentElo > elos[j].CurrentElo
+	})
+	sorted := make([]int, 0)
+	for _, elo := range elos {
+		sorted = append(sorted, elo.PlayerID)
+	}
+	return sorted, nil
+}
+
+func createTournamentMatches(num int, tournamentID int, players []int, startingScore int, outshotType *models.OutshotType, venueID int, officeID int, matchType *models.MatchType, matchMode *models.MatchMode) ([]*models.Match, error) {
 	matches := make([]*models.Match, 0)
 	for i := 0; i < num; i++ {
-		match, err := createTournamentMatch(tournamentID, players, startingScore, venueID, officeID, matchType, matchMode)
+		match, err := createTournamentMatch(tournamentID, players, startingScore, outshotType, venueID, officeID, matchType, matchMode)
 		if err != nil {
 			return nil, err
 		}
@@ -1103,8 +1387,19 @@ func createTournamentMatches(num int, tournamentID int, players []int, startingS
 	return matches, nil
 }
 
-func createTournamentMatch(tournamentID int, players []int, startingScore int, venueID int, officeID int, matchType *models.MatchType, matchMode *models.MatchMode) (*models.Match, error) {
-	match, err := NewMatch(models.Match{
+func createTournamentMatch(tournamentID int, players []int, startingScore int, outshotType *models.OutshotType, venueID int, officeID int, matchType *models.MatchType, matchMode *models.MatchMode) (*models.Match, error) {
+	match, err := NewMatch(getTournamentMatch(tournamentID, players, startingScore, outshotType, officeID, matchType, matchMode))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Generated Match %d for %d vs %d", match.ID, players[0], players[1])
+
+	return match, nil
+}
+
+// getTournamentMatch will return a match between the given players in the given tournament, ready to be inserted
+func getTournamentMatch(tournamentID int, players []int, startingScore int, outshotType *models.OutshotType, officeID int, matchType *models.MatchType, matchMode *models.MatchMode) models.Match {
+	return models.Match{
 		MatchType: matchType,
 		MatchMode: matchMode,
 		//VenueID:      null.IntFrom(int64(venueID)),
@@ -1114,14 +1409,8 @@ func createTournamentMatch(tournamentID int, players []int, startingScore int, v
 		Players:      players,
 		Legs: []*models.Leg{{
 			StartingScore: startingScore,
-			Parameters:    &models.LegParameters{OutshotType: &models.OutshotType{ID: models.OUTSHOTDOUBLE}}}},
-	})
-	if err != nil {
-		return nil, err
+			Parameters:    &models.LegParameters{OutshotType: outshotType}}},
 	}
-	log.Printf("Generated Match %d for %d vs %d", match.ID, players[0], players[1])
-
-	return match, nil
 }
 
 // FinishByeMatch will finish a given match, and move winner to the next match
@@ -1292,7 +1581,7 @@ func AddPlayerToTournament(playerID int, tournamentGroupID int, tournamentID int
 			Players:      []int{players[i].PlayerID, playerID},
 			Legs: []*models.Leg{{
 				StartingScore: tournament.Preset.StartingScore,
-				Parameters:    &models.LegParameters{OutshotType: &models.OutshotType{ID: models.OUTSHOTDOUBLE}}}},
+				Parameters:    &models.LegParameters{OutshotType: tournament.Preset.OutshotType}}},
 		})
 		if err != nil {
 			return nil, err
@@ -1317,7 +1606,8 @@ func insertMetadata(matches []*models.MatchMetadata) error {
 		return err
 	}
 	stmt, err := tx.Prepare(`INSERT INTO match_metadata (match_id, order_of_play, tournament_group_id, match_displayname, elimination, promotion, 
-		trophy, semi_final,  grand_final, winner_outcome_match_id, is_winner_outcome_home) VALUES (?, ?, ?, ?, 1, 0, 0, ?, ?, ?, ?)`)
+		trophy, semi_final,  grand_final, winner_outcome_match_id, is_winner_outcome_home, looser_outcome_match_id, is_looser_outcome_home)
+		VALUES (?, ?, ?, ?, 1, 0, 0, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -1327,8 +1617,12 @@ func insertMetadata(matches []*models.MatchMetadata) error {
 		if metadata.WinnerOutcomeMatchID.Valid {
 			winnerOutcomeMatchID = &metadata.WinnerOutcomeMatchID.Int64
 		}
+		var looserOutcomeMatchID *int64
+		if metadata.LooserOutcomeMatchID.Valid {
+			looserOutcomeMatchID = &metadata.LooserOutcomeMatchID.Int64
+		}
 		_, err = stmt.Exec(metadata.MatchID, metadata.OrderOfPlay, metadata.TournamentGroup.ID, metadata.MatchDisplayname, metadata.SemiFinal,
-			metadata.GrandFinal, winnerOutcomeMatchID, metadata.IsWinnerOutcomeHome)
+			metadata.GrandFinal, winnerOutcomeMatchID, metadata.IsWinnerOutcomeHome, looserOutcomeMatchID, metadata.IsLooserOutcomeHome)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -1,6 +1,12 @@
 package data
 
 import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -16,7 +22,9 @@ func GetTournamentPresets() ([]*models.TournamentPreset, error) {
 			mmsf.id, mmsf.name, mmsf.short_name,
 			mmgf.id, mmgf.name, mmgf.short_name,
 			tg.id, tg.name, tg1.id, tg1.name, tg2.id, tg2.name,
-			tp.player_id_walkover, tp.player_id_placeholder_home, tp.player_id_placeholder_away
+			tp.player_id_walkover, tp.player_id_placeholder_home, tp.player_id_placeholder_away,
+			IFNULL(tp.group_count, 2), IFNULL(tp.playoffs_size, 0), ot.id, ot.name, ot.short_name, tp.tiebreak_rules,
+			IFNULL(tp.withdrawal_rule, 'auto')
 		FROM tournament_preset tp
 			JOIN match_type mt ON mt.id = tp.match_type_id
 			JOIN match_mode mm ON mm.id = tp.match_mode_id
@@ -26,7 +34,8 @@ func GetTournamentPresets() ([]*models.TournamentPreset, error) {
 			JOIN match_mode mmgf ON mmgf.id = tp.match_mode_id_grand_final
 			JOIN tournament_group tg ON tg.id = tp.playoffs_tournament_group_id
 			JOIN tournament_group tg1 ON tg1.id = tp.group1_tournament_group_id
-			JOIN tournament_group tg2 ON tg2.id = tp.group2_tournament_group_id`)
+			JOIN tournament_group tg2 ON tg2.id = tp.group2_tournament_group_id
+			JOIN outshot_type ot ON ot.id = IFNULL(tp.outshot_type_id, 1)`)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +53,9 @@ func GetTournamentPresets() ([]*models.TournamentPreset, error) {
 		tp.PlayoffsTournamentGroup = new(models.TournamentGroup)
 		tp.Group1TournamentGroup = new(models.TournamentGroup)
 		tp.Group2TournamentGroup = new(models.TournamentGroup)
+		tp.OutshotType = new(models.OutshotType)
 
+		var tiebreakRules null.String
 		err := rows.Scan(&tp.ID, &tp.Name, &tp.StartingScore, &tp.Description, &tp.MatchType.ID, &tp.MatchType.Name,
 			&tp.MatchMode.ID, &tp.MatchMode.Name, &tp.MatchMode.ShortName,
 			&tp.MatchModeLast16.ID, &tp.MatchModeLast16.Name, &tp.MatchModeLast16.ShortName,
@@ -53,10 +64,16 @@ func GetTournamentPresets() ([]*models.TournamentPreset, error) {
 			&tp.MatchModeGrandFinal.ID, &tp.MatchModeGrandFinal.Name, &tp.MatchModeGrandFinal.ShortName,
 			&tp.PlayoffsTournamentGroup.ID, &tp.PlayoffsTournamentGroup.Name, &tp.Group1TournamentGroup.ID,
 			&tp.Group1TournamentGroup.Name, &tp.Group2TournamentGroup.ID, &tp.Group2TournamentGroup.Name,
-			&tp.PlayerIDWalkover, &tp.PlayerIDPlaceholderHome, &tp.PlayerIDPlaceholderAway)
+			&tp.PlayerIDWalkover, &tp.PlayerIDPlaceholderHome, &tp.PlayerIDPlaceholderAway, &tp.GroupCount, &tp.PlayoffsSize,
+			&tp.OutshotType.ID, &tp.OutshotType.Name, &tp.OutshotType.ShortName, &tiebreakRules,
+			&tp.WithdrawalRule)
 		if err != nil {
 			return nil, err
 		}
+		tp.TiebreakRules, err = parseTiebreakRules(tiebreakRules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tiebreak rules for preset %d: %s", tp.ID, err)
+		}
 		presets = append(presets, tp)
 	}
 	if err = rows.Err(); err != nil {
@@ -66,7 +83,7 @@ func GetTournamentPresets() ([]*models.TournamentPreset, error) {
 	return presets, nil
 }
 
-// GetPreset returns the preset for the given ID
+// GetTournamentPreset returns the preset for the given ID
 func GetTournamentPreset(id int) (*models.TournamentPreset, error) {
 	tp := new(models.TournamentPreset)
 	tp.MatchMode = new(models.MatchMode)
@@ -78,6 +95,8 @@ func GetTournamentPreset(id int) (*models.TournamentPreset, error) {
 	tp.PlayoffsTournamentGroup = new(models.TournamentGroup)
 	tp.Group1TournamentGroup = new(models.TournamentGroup)
 	tp.Group2TournamentGroup = new(models.TournamentGroup)
+	tp.OutshotType = new(models.OutshotType)
+	var tiebreakRules null.String
 	err := models.DB.QueryRow(`
 		SELECT
 			tp.id, tp.name, tp.starting_score, tp.description,
@@ -88,7 +107,9 @@ func GetTournamentPreset(id int) (*models.TournamentPreset, error) {
 			mmsf.id, mmsf.name, mmsf.short_name,
 			mmgf.id, mmgf.name, mmgf.short_name,
 			tg.id, tg.name, tg1.id, tg1.name, tg2.id, tg2.name,
-			tp.player_id_walkover, tp.player_id_placeholder_home, tp.player_id_placeholder_away
+			tp.player_id_walkover, tp.player_id_placeholder_home, tp.player_id_placeholder_away,
+			IFNULL(tp.group_count, 2), IFNULL(tp.playoffs_size, 0), ot.id, ot.name, ot.short_name, tp.tiebreak_rules,
+			IFNULL(tp.withdrawal_rule, 'auto')
 		FROM tournament_preset tp
 			JOIN match_type mt ON mt.id = tp.match_type_id
 			JOIN match_mode mm ON mm.id = tp.match_mode_id
@@ -99,6 +120,7 @@ func GetTournamentPreset(id int) (*models.TournamentPreset, error) {
 			JOIN tournament_group tg ON tg.id = tp.playoffs_tournament_group_id
 			JOIN tournament_group tg1 ON tg1.id = tp.group1_tournament_group_id
 			JOIN tournament_group tg2 ON tg2.id = tp.group2_tournament_group_id
+			JOIN outshot_type ot ON ot.id = IFNULL(tp.outshot_type_id, 1)
 		WHERE tp.id = ?`, id).
 		Scan(&tp.ID, &tp.Name, &tp.StartingScore, &tp.Description, &tp.MatchType.ID, &tp.MatchType.Name,
 			&tp.MatchMode.ID, &tp.MatchMode.Name, &tp.MatchMode.ShortName,
@@ -108,46 +130,209 @@ func GetTournamentPreset(id int) (*models.TournamentPreset, error) {
 			&tp.MatchModeGrandFinal.ID, &tp.MatchModeGrandFinal.Name, &tp.MatchModeGrandFinal.ShortName,
 			&tp.PlayoffsTournamentGroup.ID, &tp.PlayoffsTournamentGroup.Name, &tp.Group1TournamentGroup.ID,
 			&tp.Group1TournamentGroup.Name, &tp.Group2TournamentGroup.ID, &tp.Group2TournamentGroup.Name,
-			&tp.PlayerIDWalkover, &tp.PlayerIDPlaceholderHome, &tp.PlayerIDPlaceholderAway)
+			&tp.PlayerIDWalkover, &tp.PlayerIDPlaceholderHome, &tp.PlayerIDPlaceholderAway, &tp.GroupCount, &tp.PlayoffsSize,
+			&tp.OutshotType.ID, &tp.OutshotType.Name, &tp.OutshotType.ShortName, &tiebreakRules,
+			&tp.WithdrawalRule)
 	if err != nil {
 		return nil, err
 	}
+	tp.TiebreakRules, err = parseTiebreakRules(tiebreakRules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tiebreak rules for preset %d: %s", tp.ID, err)
+	}
 	return tp, nil
 }
 
+// parseTiebreakRules will return the comma separated tiebreak rules, or the default rules if none are configured.
+// An error is returned if the stored rules are not valid
+func parseTiebreakRules(rules null.String) ([]string, error) {
+	if !rules.Valid || rules.String == "" {
+		return models.DefaultTiebreakRules, nil
+	}
+	parsed := strings.Split(rules.String, ",")
+	for i, rule := range parsed {
+		parsed[i] = strings.TrimSpace(rule)
+	}
+	err := models.ValidateTiebreakRules(parsed)
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
 // AddTournamentPreset will add a new preset to the database
-/*func AddTournamentPreset(preset models.TournamentPreset) error {
-	stmt, err := models.DB.Prepare(`
-		INSERT INTO match_preset(name, match_type_id, match_mode_id, starting_score, smartcard_uid, description) VALUES(?, ?, ?, ?, ?, ?)`)
+func AddTournamentPreset(preset models.TournamentPreset) (*models.TournamentPreset, error) {
+	res, err := models.DB.Exec(`
+		INSERT INTO tournament_preset (name, match_type_id, starting_score, match_mode_id, match_mode_id_last_16, match_mode_id_quarter_final,
+			match_mode_id_semi_final, match_mode_id_grand_final, playoffs_tournament_group_id, group1_tournament_group_id, group2_tournament_group_id,
+			player_id_walkover, player_id_placeholder_home, player_id_placeholder_away, group_count, playoffs_size, outshot_type_id,
+			tiebreak_rules, withdrawal_rule, description)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, getTournamentPresetValues(preset)...)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Created tournament preset %d (%s)", id, preset.Name)
+	return GetTournamentPreset(int(id))
+}
+
+// UpdateTournamentPreset will update the given preset
+func UpdateTournamentPreset(id int, preset models.TournamentPreset) (*models.TournamentPreset, error) {
+	values := append(getTournamentPresetValues(preset), id)
+	_, err := models.DB.Exec(`
+		UPDATE tournament_preset SET
+			name = ?, match_type_id = ?, starting_score = ?, match_mode_id = ?, match_mode_id_last_16 = ?, match_mode_id_quarter_final = ?,
+			match_mode_id_semi_final = ?, match_mode_id_grand_final = ?, playoffs_tournament_group_id = ?, group1_tournament_group_id = ?,
+			group2_tournament_group_id = ?, player_id_walkover = ?, player_id_placeholder_home = ?, player_id_placeholder_away = ?,
+			group_count = ?, playoffs_size = ?, outshot_type_id = ?, tiebreak_rules = ?, withdrawal_rule = ?, description = ?
+		WHERE id = ?`, values...)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Updated tournament preset %d (%s)", id, preset.Name)
+	return GetTournamentPreset(id)
+}
+
+// CloneTournamentPreset will create a copy of the given preset with the given name
+func CloneTournamentPreset(id int, name string) (*models.TournamentPreset, error) {
+	preset, err := GetTournamentPreset(id)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = preset.Name + " (Copy)"
+	}
+	preset.Name = name
+	return AddTournamentPreset(*preset)
+}
+
+// DeleteTournamentPreset will delete the given preset, unless it is in use
+func DeleteTournamentPreset(id int) error {
+	err := CheckTournamentPresetUnused(id)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	_, err = models.DB.Exec(`DELETE FROM tournament_preset WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	log.Printf("Deleted tournament preset %d", id)
+	return nil
+}
 
-	_, err = stmt.Exec(preset.Name, preset.MatchType.ID, preset.MatchMode.ID, preset.StartingScore, preset.SmartcardUID, preset.Description)
+// CheckTournamentPresetUnused will return an error if the given preset is used by any tournament, tournament registration or team league
+func CheckTournamentPresetUnused(id int) error {
+	var tournaments, registrations, leagues int
+	err := models.DB.QueryRow(`
+		SELECT
+			(SELECT COUNT(1) FROM tournament WHERE preset_id = ?),
+			(SELECT COUNT(1) FROM tournament_registration WHERE preset_id = ?),
+			(SELECT COUNT(1) FROM team_league WHERE preset_id = ?)`, id, id, id).Scan(&tournaments, &registrations, &leagues)
 	if err != nil {
 		return err
 	}
-	log.Printf("Created preset %s (%v)", preset.Name, preset)
+	if tournaments > 0 || registrations > 0 || leagues > 0 {
+		return fmt.Errorf("preset is used by %d tournament(s), %d tournament registration(s) and %d team league(s)", tournaments, registrations, leagues)
+	}
 	return nil
 }
 
-// UpdateTournamentPreset will update the given preset
-func UpdateTournamentPreset(id int, preset models.TournamentPreset) error {
-	stmt, err := models.DB.Prepare(`
-		UPDATE match_preset SET
-			name = ?, match_type_id = ?, match_mode_id = ?, starting_score = ?, smartcard_uid = ?, description = ?
-		WHERE id = ?`)
+// ValidateTournamentPreset will check that the given preset is valid, and that all referenced match modes, tournament groups
+// and outshot type exist, and that the walkover and placeholder players are placeholders
+func ValidateTournamentPreset(preset *models.TournamentPreset) error {
+	err := preset.Validate()
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(preset.Name, preset.MatchType.ID, preset.MatchMode.ID, preset.StartingScore, preset.SmartcardUID, preset.Description, id)
+	var exists bool
+	err = models.DB.QueryRow(`SELECT COUNT(1) > 0 FROM match_type WHERE id = ?`, preset.MatchType.ID).Scan(&exists)
 	if err != nil {
 		return err
 	}
-	log.Printf("Updated preset %s (%v)", preset.Name, preset)
+	if !exists {
+		return fmt.Errorf("match type %d does not exist", preset.MatchType.ID)
+	}
+	err = models.DB.QueryRow(`SELECT COUNT(1) > 0 FROM outshot_type WHERE id = ?`, preset.OutshotType.ID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("outshot type %d does not exist", preset.OutshotType.ID)
+	}
+
+	modes := []int{preset.MatchMode.ID, preset.MatchModeLast16.ID, preset.MatchModeQuarterFinal.ID, preset.MatchModeSemiFinal.ID,
+		preset.MatchModeGrandFinal.ID}
+	missing, err := getMissingIDs(`SELECT id FROM match_mode WHERE id IN (?)`, modes)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("match mode(s) %v do not exist", missing)
+	}
+	groups := []int{preset.PlayoffsTournamentGroup.ID, preset.Group1TournamentGroup.ID, preset.Group2TournamentGroup.ID}
+	missing, err = getMissingIDs(`SELECT id FROM tournament_group WHERE id IN (?)`, groups)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("tournament group(s) %v do not exist", missing)
+	}
+	placeholders := []int{preset.PlayerIDWalkover, preset.PlayerIDPlaceholderHome, preset.PlayerIDPlaceholderAway}
+	missing, err = getMissingIDs(`SELECT id FROM player WHERE id IN (?) AND is_placeholder = 1`, placeholders)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("player(s) %v do not exist or are not placeholders", missing)
+	}
 	return nil
 }
-*/
+
+// getMissingIDs will return the given IDs which are not returned by the given query
+func getMissingIDs(query string, ids []int) ([]int, error) {
+	q, args, err := sqlx.In(query, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	missing := make([]int, 0)
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func getTournamentPresetValues(preset models.TournamentPreset) []interface{} {
+	var tiebreakRules null.String
+	if len(preset.TiebreakRules) > 0 {
+		tiebreakRules = null.StringFrom(strings.Join(preset.TiebreakRules, ","))
+	}
+	return []interface{}{preset.Name, preset.MatchType.ID, preset.StartingScore, preset.MatchMode.ID, preset.MatchModeLast16.ID,
+		preset.MatchModeQuarterFinal.ID, preset.MatchModeSemiFinal.ID, preset.MatchModeGrandFinal.ID, preset.PlayoffsTournamentGroup.ID,
+		preset.Group1TournamentGroup.ID, preset.Group2TournamentGroup.ID, preset.PlayerIDWalkover, preset.PlayerIDPlaceholderHome,
+		preset.PlayerIDPlaceholderAway, preset.GroupCount, preset.PlayoffsSize, preset.OutshotType.ID, tiebreakRules, null.NewString(preset.WithdrawalRule, preset.WithdrawalRule != ""),
+		preset.Description}
+}
@@ -10,13 +10,14 @@ require (
 	github.com/jmoiron/sqlx v1.3.4
 	github.com/jordic/goics v0.0.0-20210404174824-5a0337b716a0
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.7.0 // indirect
+	github.com/stretchr/testify v1.7.0
 	gopkg.in/yaml.v2 v2.4.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
 
 require (
-	github.com/inconshreveable/mousetrap v1.0.0 // indirect
-	github.com/spf13/cobra v1.3.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/spf13/cobra v1.3.0
+	github.com/spf13/pflag v1.0.5
 )
+
+require github.com/inconshreveable/mousetrap v1.0.0 // indirect
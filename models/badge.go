@@ -1,9 +1,15 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/guregu/null"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // Badge represents a badge model.
@@ -38,335 +44,371 @@ type BadgeStatistics struct {
 	Score180s     int
 }
 
-type GlobalBadge interface {
-	GetID() int
-}
-
-type BadgeKcappSupporter struct{ ID int }
-type BadgeSayMyName struct{ ID int }
-type BadgeItsOfficial struct{ ID int }
-type BadgeTournament1st struct{ ID int }
-type BadgeTournament2nd struct{ ID int }
-type BadgeTournament3rd struct{ ID int }
-type BadgeUntouchable struct{ ID int }
-
-func (b BadgeKcappSupporter) GetID() int {
-	return 4
-}
-
-func (b BadgeSayMyName) GetID() int {
-	return 12
-}
-
-func (b BadgeItsOfficial) GetID() int {
-	return 17
-}
-
-func (b BadgeTournament1st) GetID() int {
-	return 18
-}
-
-func (b BadgeTournament2nd) GetID() int {
-	return 19
-}
-
-func (b BadgeTournament3rd) GetID() int {
-	return 20
-}
-
-func (b BadgeUntouchable) GetID() int {
-	return 26
-}
-
-var MatchBadges = []MatchBadge{}
-
-type MatchBadge interface {
-	GetID() int
-	Validate(*Match) (bool, *int)
-}
+// LegBadgeStatistics is implemented by the finished leg statistics of each leg type, providing the facts leg badges are evaluated against
+type LegBadgeStatistics interface {
+	GetPlayerID() int
+	GetBadgeFacts() BadgeFacts
+}
+
+// Scopes a badge definition can be evaluated in
+const (
+	// BadgeScopeLeg badges are evaluated for each player of a finished leg
+	BadgeScopeLeg = "leg"
+	// BadgeScopeVisit badges are evaluated for each visit of a finished leg, and awarded with a level based on the number of matching visits
+	BadgeScopeVisit = "visit"
+	// BadgeScopeMatch badges are evaluated for each player of a finished match
+	BadgeScopeMatch = "match"
+	// BadgeScopePlayer badges are evaluated for each player during recalculation
+	BadgeScopePlayer = "player"
+	// BadgeScopeTournament badges are evaluated for each tournament a player has a final standing in during recalculation
+	BadgeScopeTournament = "tournament"
+)
 
-var LegBadges = []LegBadge{
-	BadgeDoubleDouble{ID: 6},
-	BadgeTripleDouble{ID: 7},
-	BadgeMadHouse{ID: 8},
-	BadgeMerryChristmas{ID: 9},
-	BadgeHappyNewYear{ID: 10},
-	BadgeBigFish{ID: 11},
-	BadgeGettingCrowded{ID: 13},
-	BadgeBullseye{ID: 14},
-	BadgeEasyAs123{ID: 15},
-	BadgeCloseToPerfect{ID: 16},
+// BadgeLegStatistics are the finished leg statistics available as 'stats.<name>' for the player, and as 'opponents.<name>'
+// for the best value among the other players. Which statistics are set depends on the type of the leg
+var BadgeLegStatistics = []string{
+	"darts", "rounds", "score", "highest_score", "marks", "max_round_marks", "longest_streak", "hit_rate",
+	"shanghai", "numbers_closed", "highest_closed", "position",
+}
+
+// BadgeScopeFacts contains the facts which can be used in rules of each scope
+var BadgeScopeFacts = map[string][]string{
+	BadgeScopeLeg: append([]string{
+		"leg.players", "leg.starting_score", "leg.type", "leg.x01",
+		"match.type", "match.official", "match.practice",
+		"time.month", "time.day", "time.weekday", "time.hour",
+		"player.winner", "player.checkout",
+		"checkout.score", "checkout.darts", "checkout.doubles", "checkout.triples",
+		"dart1.value", "dart1.multiplier", "dart2.value", "dart2.multiplier", "dart3.value", "dart3.multiplier",
+		"last_dart.value", "last_dart.multiplier",
+		"opponent.bot", "opponent.bot_skill", "opponent.bot_mocking",
+	}, getBadgeLegStatisticsFacts()...),
+	BadgeScopeVisit: {
+		"leg.type", "leg.x01",
+		"visit.score", "visit.bust", "visit.darts",
+		"dart1.value", "dart1.multiplier", "dart2.value", "dart2.multiplier", "dart3.value", "dart3.multiplier",
+	},
+	BadgeScopeMatch: {
+		"match.type", "match.official", "match.practice", "match.players", "match.legs", "match.rated",
+		"time.month", "time.day", "time.weekday", "time.hour",
+		"player.winner", "player.legs_won", "player.max_deficit", "player.won_decider", "player.max_winning_darts", "player.elo",
+		"opponents.legs_won", "opponents.elo",
+	},
+	BadgeScopePlayer: {
+		"player.supporter", "player.vocal_name",
+	},
+	BadgeScopeTournament: {
+		"tournament.position", "tournament.undefeated",
+	},
+}
+
+// BadgeDefinitions contains all loaded badge definitions
+var BadgeDefinitions = make([]*BadgeDefinition, 0)
+
+// BadgeDefinition struct used for storing the rule of a badge
+type BadgeDefinition struct {
+	ID    int    `yaml:"id"`
+	Name  string `yaml:"name"`
+	Scope string `yaml:"scope"`
+	Rule  string `yaml:"rule"`
+	// Levels and Statistic are used by visit badges, where the level is given by the statistic of the player plus the number of matching visits
+	Levels    []int  `yaml:"levels"`
+	Statistic string `yaml:"statistic"`
+	rule      BadgeRule
+}
+
+// LoadBadgeDefinitions will load and validate badge definitions from the given yaml file
+func LoadBadgeDefinitions(path string) ([]*BadgeDefinition, error) {
+	yamlFile, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBadgeDefinitions(yamlFile)
 }
 
-type LegBadge interface {
-	GetID() int
-	Validate(*Leg) (bool, *int)
+// ParseBadgeDefinitions will parse and validate the given badge definitions
+func ParseBadgeDefinitions(definitions []byte) ([]*BadgeDefinition, error) {
+	badges := make([]*BadgeDefinition, 0)
+	err := yaml.UnmarshalStrict(definitions, &badges)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool)
+	for _, badge := range badges {
+		if badge.ID <= 0 {
+			return nil, fmt.Errorf("badge '%s' must have a positive id", badge.Name)
+		}
+		if ids[badge.ID] {
+			return nil, fmt.Errorf("badge %d is defined more than once", badge.ID)
+		}
+		ids[badge.ID] = true
+		err = badge.compile()
+		if err != nil {
+			return nil, fmt.Errorf("badge %d: %s", badge.ID, err)
+		}
+	}
+	return badges, nil
 }
 
-type BadgeDoubleDouble struct{ ID int }
-type BadgeTripleDouble struct{ ID int }
-type BadgeMadHouse struct{ ID int }
-type BadgeMerryChristmas struct{ ID int }
-type BadgeHappyNewYear struct{ ID int }
-type BadgeBigFish struct{ ID int }
-type BadgeGettingCrowded struct{ ID int }
-type BadgeBullseye struct{ ID int }
-type BadgeEasyAs123 struct{ ID int }
-type BadgeCloseToPerfect struct{ ID int }
-
-func (b BadgeDoubleDouble) GetID() int {
-	return b.ID
-}
-func (b BadgeDoubleDouble) Validate(leg *Leg) (bool, *int) {
-	visit := leg.GetLastVisit()
-	doubles := 0
-	if visit.ThirdDart.IsDouble() {
-		doubles++
+func (badge *BadgeDefinition) compile() error {
+	facts, ok := BadgeScopeFacts[badge.Scope]
+	if !ok {
+		return fmt.Errorf("unknown scope '%s'", badge.Scope)
 	}
-	if visit.SecondDart.IsDouble() {
-		doubles++
+	if badge.Scope == BadgeScopeVisit {
+		if len(badge.Levels) == 0 {
+			return errors.New("visit badges must have levels")
+		}
+		for i := 1; i < len(badge.Levels); i++ {
+			if badge.Levels[i] <= badge.Levels[i-1] {
+				return errors.New("levels must be in ascending order")
+			}
+		}
+		if _, ok := badgeStatistics[badge.Statistic]; !ok && badge.Statistic != "" {
+			return fmt.Errorf("unknown statistic '%s'", badge.Statistic)
+		}
+	} else if len(badge.Levels) > 0 || badge.Statistic != "" {
+		return errors.New("only visit badges can have levels and statistic")
 	}
-	if visit.FirstDart.IsDouble() {
-		doubles++
+	rule, err := CompileBadgeRule(badge.Rule, facts)
+	if err != nil {
+		return err
 	}
-	return doubles == 2, &visit.PlayerID
-}
-
-func (b BadgeTripleDouble) GetID() int {
-	return b.ID
-}
-func (b BadgeTripleDouble) Validate(leg *Leg) (bool, *int) {
-	visit := leg.GetLastVisit()
-	return visit.FirstDart.IsDouble() && visit.SecondDart.IsDouble() && visit.ThirdDart.IsDouble(), &visit.PlayerID
-}
-
-func (b BadgeMadHouse) GetID() int {
-	return b.ID
-}
-func (b BadgeMadHouse) Validate(leg *Leg) (bool, *int) {
-	visit := leg.GetLastVisit()
-	last := visit.GetLastDart()
-	return last.IsDouble() && last.ValueRaw() == 1, &visit.PlayerID
-}
-
-func (b BadgeMerryChristmas) GetID() int {
-	return b.ID
-}
-func (b BadgeMerryChristmas) Validate(leg *Leg) (bool, *int) {
-	d := leg.Endtime.Time
-	return d.Day() == 25 && d.Month() == 12, nil
-}
-
-func (b BadgeHappyNewYear) GetID() int {
-	return b.ID
-}
-func (b BadgeHappyNewYear) Validate(leg *Leg) (bool, *int) {
-	d := leg.Endtime.Time
-	return d.Day() == 31 && d.Month() == 12, nil
-}
-
-func (b BadgeBigFish) GetID() int {
-	return b.ID
-}
-func (b BadgeBigFish) Validate(leg *Leg) (bool, *int) {
-	visit := leg.GetLastVisit()
-	return visit.FirstDart.IsTriple() && visit.FirstDart.ValueRaw() == 20 &&
-		visit.SecondDart.IsTriple() && visit.SecondDart.ValueRaw() == 20 &&
-		visit.ThirdDart.IsDouble() && visit.ThirdDart.IsBull(), &visit.PlayerID
-}
-
-func (b BadgeGettingCrowded) GetID() int {
-	return b.ID
-}
-func (b BadgeGettingCrowded) Validate(leg *Leg) (bool, *int) {
-	return len(leg.Players) > 4, nil
-}
-
-func (b BadgeBullseye) GetID() int {
-	return b.ID
-}
-func (b BadgeBullseye) Validate(leg *Leg) (bool, *int) {
-	visit := leg.GetLastVisit()
-	last := visit.GetLastDart()
-	return last.ValueRaw() == BULLSEYE && last.Multiplier == DOUBLE, &visit.PlayerID
-}
-
-func (b BadgeEasyAs123) GetID() int {
-	return b.ID
-}
-func (b BadgeEasyAs123) Validate(leg *Leg) (bool, *int) {
-	visit := leg.GetLastVisit()
-	last := visit.GetLastDart()
-	return visit.GetScore() == 123 && last.IsDouble(), &visit.PlayerID
-}
-
-func (b BadgeCloseToPerfect) GetID() int {
-	return b.ID
-}
-func (b BadgeCloseToPerfect) Validate(leg *Leg) (bool, *int) {
-	visit := leg.GetLastVisit()
-	return leg.StartingScore == 501 && visit.DartsThrown < 15 && visit.DartsThrown > 9, &visit.PlayerID
+	badge.rule = rule
+	return nil
 }
 
-var LegPlayerBadges = []LegPlayerBadge{
-	BadgeImpersonator{ID: 22},
-	BadgeBotBeaterEasy{ID: 22},
-	BadgeBotBeaterMedium{ID: 23},
-	BadgeBotBeaterHard{ID: 24},
+// Evaluate will return true if the rule of the badge holds for the given facts
+func (badge *BadgeDefinition) Evaluate(facts BadgeFacts) bool {
+	return badge.rule != nil && badge.rule(facts) != 0
 }
 
-type LegPlayerBadge interface {
-	GetID() int
-	Validate(*Leg, []*Player2Leg) (bool, *int)
+// GetLevel will return the level reached by the given statistics with count additional matching visits
+func (badge *BadgeDefinition) GetLevel(stats *BadgeStatistics, count int) int {
+	value := count
+	if badge.Statistic != "" {
+		value += badgeStatistics[badge.Statistic](stats)
+	}
+	return getLevel(value, badge.Levels)
 }
 
-type BadgeImpersonator struct{ ID int }
-type BadgeBotBeaterEasy struct{ ID int }
-type BadgeBotBeaterMedium struct{ ID int }
-type BadgeBotBeaterHard struct{ ID int }
-
-func (b BadgeImpersonator) GetID() int {
-	return b.ID
-}
-func (b BadgeImpersonator) Validate(leg *Leg, players []*Player2Leg) (bool, *int) {
-	var bot *Player2Leg
-	for _, p2l := range players {
-		if p2l.Player.IsBot && p2l.BotConfig.PlayerID.Valid {
-			bot = p2l
+// GetBadgeDefinitions will return the loaded badge definitions of the given scope, optionally limited to the given badge IDs
+func GetBadgeDefinitions(scope string, ids ...int) []*BadgeDefinition {
+	badges := make([]*BadgeDefinition, 0)
+	for _, badge := range BadgeDefinitions {
+		if badge.Scope == scope && (len(ids) == 0 || containsInt(ids, badge.ID)) {
+			badges = append(badges, badge)
 		}
 	}
-	winner := int(leg.WinnerPlayerID.Int64)
-	if bot != nil && bot.PlayerID != winner {
-		return true, &winner
+	return badges
+}
+
+// badgeStatistics are the statistics visit badge levels can be based on
+var badgeStatistics = map[string]func(*BadgeStatistics) int{
+	"score_100s_plus": func(stats *BadgeStatistics) int { return stats.Score100sPlus },
+	"score_140s_plus": func(stats *BadgeStatistics) int { return stats.Score140sPlus },
+	"score_180s":      func(stats *BadgeStatistics) int { return stats.Score180s },
+}
+
+// GetLegBadgeFacts will return the facts of the given finished leg, as seen by the given player.
+// Statistics contains the finished leg statistics facts of each player, see SetOpponentBadgeFacts
+func GetLegBadgeFacts(leg *Leg, match *Match, players []*Player2Leg, statistics map[int]BadgeFacts, playerID int) BadgeFacts {
+	end := leg.Endtime.Time
+	legType := GetBadgeLegType(leg, match)
+	facts := BadgeFacts{
+		"leg.players":        len(leg.Players),
+		"leg.starting_score": leg.StartingScore,
+		"leg.type":           legType,
+		"leg.x01":            boolToInt(legType == X01 || legType == X01HANDICAP),
+		"match.type":         match.MatchType.ID,
+		"match.official":     boolToInt(match.TournamentID.Valid),
+		"match.practice":     boolToInt(match.IsPractice),
+		"time.month":         int(end.Month()),
+		"time.day":           end.Day(),
+		"time.weekday":       int(end.Weekday()),
+		"time.hour":          end.Hour(),
+		"player.winner":      boolToInt(leg.WinnerPlayerID.Valid && int(leg.WinnerPlayerID.Int64) == playerID),
 	}
-	return false, nil
-}
-
-func (b BadgeBotBeaterEasy) GetID() int {
-	return b.ID
-}
-func (b BadgeBotBeaterEasy) Validate(leg *Leg, players []*Player2Leg) (bool, *int) {
-	bot := getBot(BOT_EASY, players)
-	winner := int(leg.WinnerPlayerID.Int64)
-	if bot != nil && bot.PlayerID != winner {
-		return true, &winner
+	for fact, value := range statistics[playerID] {
+		facts[fact] = value
 	}
-	return false, nil
-}
-
-func (b BadgeBotBeaterMedium) GetID() int {
-	return b.ID
-}
-func (b BadgeBotBeaterMedium) Validate(leg *Leg, players []*Player2Leg) (bool, *int) {
-	bot := getBot(BOT_MEDIUM, players)
-	winner := int(leg.WinnerPlayerID.Int64)
-	if bot != nil && bot.PlayerID != winner {
-		return true, &winner
+	if len(leg.Visits) > 0 {
+		visit := leg.GetLastVisit()
+		last := visit.GetLastDart()
+		facts["player.checkout"] = boolToInt(visit.PlayerID == playerID)
+		facts["checkout.score"] = visit.GetScore()
+		facts["checkout.darts"] = visit.DartsThrown
+		for _, dart := range visit.GetDarts() {
+			facts["checkout.doubles"] += boolToInt(dart.IsDouble())
+			facts["checkout.triples"] += boolToInt(dart.IsTriple())
+		}
+		addDartFacts(facts, visit)
+		facts["last_dart.value"] = last.ValueRaw()
+		facts["last_dart.multiplier"] = int(last.Multiplier)
 	}
-	return false, nil
-}
-
-func (b BadgeBotBeaterHard) GetID() int {
-	return b.ID
-}
-func (b BadgeBotBeaterHard) Validate(leg *Leg, players []*Player2Leg) (bool, *int) {
-	bot := getBot(BOT_HARD, players)
-	winner := int(leg.WinnerPlayerID.Int64)
-	if bot != nil && bot.PlayerID != winner {
-		return true, &winner
+	for _, p2l := range players {
+		if p2l.PlayerID == playerID || p2l.Player == nil || !p2l.Player.IsBot {
+			continue
+		}
+		facts["opponent.bot"] = 1
+		if p2l.BotConfig != nil {
+			facts["opponent.bot_skill"] = int(p2l.BotConfig.Skill.Int64)
+			facts["opponent.bot_mocking"] = boolToInt(p2l.BotConfig.PlayerID.Valid)
+		}
 	}
-	return false, nil
-}
-
-var VisitBadges = []VisitBadge{
-	BadgeHighScore{ID: 1},
-	BadgeHigherScore{ID: 2},
-	BadgeTheMaximum{ID: 3},
-}
-
-type VisitBadge interface {
-	GetID() int
-	Validate(stats *BadgeStatistics, visits []*Visit) (bool, *int)
-	Levels() []int
+	return facts
 }
 
-type BadgeHighScore struct{ ID int }
-type BadgeHigherScore struct{ ID int }
-type BadgeTheMaximum struct{ ID int }
-
-func (b BadgeHighScore) GetID() int {
-	return b.ID
+// GetVisitBadgeFacts will return the facts of the given visit, thrown in a leg of the given type
+func GetVisitBadgeFacts(visit *Visit, legType int) BadgeFacts {
+	facts := BadgeFacts{
+		"leg.type":    legType,
+		"leg.x01":     boolToInt(legType == X01 || legType == X01HANDICAP),
+		"visit.score": visit.Score,
+		"visit.bust":  boolToInt(visit.IsBust),
+		"visit.darts": visit.GetDartsThrown(),
+	}
+	addDartFacts(facts, visit)
+	return facts
 }
 
-func (b BadgeHighScore) Levels() []int {
-	return []int{1, 10, 100, 1000}
+// GetBadgeLegType will return the type of the given leg, which is the match type unless the leg has a different type
+func GetBadgeLegType(leg *Leg, match *Match) int {
+	if leg.LegType != nil {
+		return leg.LegType.ID
+	}
+	return match.MatchType.ID
+}
+
+// SetOpponentBadgeFacts will set the 'opponents.<name>' facts of each player to the highest value of 'stats.<name>' among the other players
+func SetOpponentBadgeFacts(statistics map[int]BadgeFacts) {
+	for playerID, facts := range statistics {
+		for _, name := range BadgeLegStatistics {
+			best := 0
+			for otherID, other := range statistics {
+				if otherID != playerID && other["stats."+name] > best {
+					best = other["stats."+name]
+				}
+			}
+			facts["opponents."+name] = best
+		}
+	}
 }
 
-func (b BadgeHighScore) Validate(stats *BadgeStatistics, visits []*Visit) (bool, *int) {
-	count := 0
-	for _, visit := range visits {
-		if visit.PlayerID != stats.PlayerID {
+// GetMatchBadgeFacts will return the facts of the given finished match, including its legs and Elo change, as seen by the given player.
+// Darts contains the number of darts thrown by each player in each leg
+func GetMatchBadgeFacts(match *Match, darts map[int]map[int]int, playerID int) BadgeFacts {
+	end := match.EndTime
+	facts := BadgeFacts{
+		"match.type":     match.MatchType.ID,
+		"match.official": boolToInt(match.TournamentID.Valid),
+		"match.practice": boolToInt(match.IsPractice),
+		"match.players":  len(match.Players),
+		"match.legs":     len(match.Legs),
+		"match.rated":    1,
+		"time.month":     int(end.Month()),
+		"time.day":       end.Day(),
+		"time.weekday":   int(end.Weekday()),
+		"time.hour":      end.Hour(),
+		"player.winner":  boolToInt(match.WinnerID.Valid && int(match.WinnerID.Int64) == playerID),
+	}
+	elos := make(map[int]int)
+	for id, elo := range match.EloChange {
+		elos[id] = elo.CurrentElo
+	}
+	facts["player.elo"] = elos[playerID]
+
+	won := make(map[int]int)
+	for i, leg := range match.Legs {
+		if i == len(match.Legs)-1 {
+			// The last leg is a decider if the player was level with the best opponent before it
+			facts["player.won_decider"] = boolToInt(leg.WinnerPlayerID.Valid && int(leg.WinnerPlayerID.Int64) == playerID &&
+				won[playerID] == getBestOpponentValue(won, match.Players, playerID))
+		}
+		if !leg.WinnerPlayerID.Valid {
 			continue
 		}
-		if visit.Score == 100 {
-			count++
+		winnerID := int(leg.WinnerPlayerID.Int64)
+		won[winnerID]++
+		if winnerID == playerID && darts[leg.ID][playerID] > facts["player.max_winning_darts"] {
+			facts["player.max_winning_darts"] = darts[leg.ID][playerID]
+		}
+		deficit := getBestOpponentValue(won, match.Players, playerID) - won[playerID]
+		if deficit > facts["player.max_deficit"] {
+			facts["player.max_deficit"] = deficit
 		}
 	}
-	if count > 0 {
-		level := getLevel(stats.Score100sPlus+count, b.Levels())
-		return true, &level
+	facts["player.legs_won"] = won[playerID]
+	facts["opponents.legs_won"] = getBestOpponentValue(won, match.Players, playerID)
+	facts["opponents.elo"] = getBestOpponentValue(elos, match.Players, playerID)
+	for _, id := range match.Players {
+		if _, ok := elos[id]; !ok {
+			facts["match.rated"] = 0
+		}
 	}
-	return false, nil
+	return facts
 }
 
-func (b BadgeHigherScore) GetID() int {
-	return b.ID
+// GetPlayerBadgeFacts will return the facts of the given player
+func GetPlayerBadgeFacts(player *Player) BadgeFacts {
+	return BadgeFacts{
+		"player.supporter":  boolToInt(player.IsSupporter),
+		"player.vocal_name": boolToInt(player.VocalName.Valid && strings.HasSuffix(player.VocalName.String, ".wav")),
+	}
 }
 
-func (b BadgeHigherScore) Levels() []int {
-	return []int{1, 10, 100, 1000}
+// GetTournamentBadgeFacts will return the facts of the given tournament standing
+func GetTournamentBadgeFacts(standing *PlayerTournamentStanding, undefeated bool) BadgeFacts {
+	return BadgeFacts{
+		"tournament.position":   standing.FinalStanding,
+		"tournament.undefeated": boolToInt(undefeated),
+	}
 }
 
-func (b BadgeHigherScore) Validate(stats *BadgeStatistics, visits []*Visit) (bool, *int) {
-	count := 0
-	for _, visit := range visits {
-		if visit.PlayerID != stats.PlayerID {
-			continue
-		}
-		if visit.Score == 140 {
-			count++
+// getBestOpponentValue will return the highest value among the other players
+func getBestOpponentValue(values map[int]int, players []int, playerID int) int {
+	best := 0
+	for _, id := range players {
+		if id != playerID && values[id] > best {
+			best = values[id]
 		}
 	}
-	if count > 0 {
-		level := getLevel(stats.Score140sPlus+count, b.Levels())
-		return true, &level
+	return best
+}
+
+func getBadgeLegStatisticsFacts() []string {
+	facts := make([]string, 0)
+	for _, name := range BadgeLegStatistics {
+		facts = append(facts, "stats."+name, "opponents."+name)
 	}
-	return false, nil
+	return facts
 }
 
-func (b BadgeTheMaximum) GetID() int {
-	return b.ID
+// getBadgeHitRate will return the given hit rate as a percentage
+func getBadgeHitRate(rate float64) int {
+	return int(math.Round(rate * 100))
 }
 
-func (b BadgeTheMaximum) Levels() []int {
-	return []int{1, 10, 50, 100}
+// getBadgeMaxRoundMarks will return the highest number of marks in a single round, given the number of rounds with
+// each number of marks starting at the given minimum
+func getBadgeMaxRoundMarks(min int, rounds ...int) int {
+	marks := 0
+	for i, count := range rounds {
+		if count > 0 {
+			marks = min + i
+		}
+	}
+	return marks
 }
 
-func (b BadgeTheMaximum) Validate(stats *BadgeStatistics, visits []*Visit) (bool, *int) {
-	count := 0
-	for _, visit := range visits {
-		if visit.PlayerID != stats.PlayerID {
+func addDartFacts(facts BadgeFacts, visit *Visit) {
+	for i, dart := range []*Dart{visit.FirstDart, visit.SecondDart, visit.ThirdDart} {
+		if dart == nil {
 			continue
 		}
-		if visit.Score == 180 {
-			count++
-		}
-	}
-	if count > 0 {
-		level := getLevel(stats.Score180s+count, b.Levels())
-		return true, &level
+		facts[fmt.Sprintf("dart%d.value", i+1)] = dart.ValueRaw()
+		facts[fmt.Sprintf("dart%d.multiplier", i+1)] = int(dart.Multiplier)
 	}
-	return false, nil
 }
 
 func getLevel(value int, levels []int) int {
@@ -378,12 +420,3 @@ func getLevel(value int, levels []int) int {
 	}
 	return level
 }
-
-func getBot(skill int64, players []*Player2Leg) *Player2Leg {
-	for _, p2l := range players {
-		if p2l.Player.IsBot && p2l.BotConfig.Skill.Int64 == skill {
-			return p2l
-		}
-	}
-	return nil
-}
@@ -0,0 +1,256 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// BadgeFacts contains the facts a badge rule is evaluated against. Boolean facts are 1 for true and 0 for false
+type BadgeFacts map[string]int
+
+// BadgeRule is a compiled badge rule
+type BadgeRule func(facts BadgeFacts) int
+
+// badgeRuleConstants are named values which can be used in all badge rules
+var badgeRuleConstants = map[string]int{
+	"true":   1,
+	"false":  0,
+	"single": SINGLE,
+	"double": DOUBLE,
+	"triple": TRIPLE,
+	"bull":   BULLSEYE,
+	// Match types
+	"x01":              X01,
+	"shootout":         SHOOTOUT,
+	"x01_handicap":     X01HANDICAP,
+	"cricket":          CRICKET,
+	"darts_at_x":       DARTSATX,
+	"around_the_world": AROUNDTHEWORLD,
+	"shanghai":         SHANGHAI,
+	"around_the_clock": AROUNDTHECLOCK,
+	"tic_tac_toe":      TICTACTOE,
+	"bermuda_triangle": BERMUDATRIANGLE,
+	"four_twenty":      FOURTWENTY,
+	"kill_bull":        KILLBULL,
+	"gotcha":           GOTCHA,
+	"jdc_practice":     JDCPRACTICE,
+	"knockout":         KNOCKOUT,
+	"scam":             SCAM,
+}
+
+// CompileBadgeRule will compile the given rule, which may only reference the given facts.
+//
+// Rules are expressions over integer facts, supporting parentheses, the logical operators '!', '&&' and '||',
+// the comparisons '==', '!=', '<', '<=', '>' and '>=', and '+' and '-'. Any non-zero value is true.
+// For example "checkout.score == 123 && last_dart.multiplier == double"
+func CompileBadgeRule(rule string, facts []string) (BadgeRule, error) {
+	tokens, err := tokenizeBadgeRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool)
+	for _, fact := range facts {
+		known[fact] = true
+	}
+	parser := &badgeRuleParser{tokens: tokens, facts: known}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected '%s' in rule '%s'", tokens[parser.pos], rule)
+	}
+	return expr, nil
+}
+
+func tokenizeBadgeRule(rule string) ([]string, error) {
+	tokens := make([]string, 0)
+	runes := []rune(rule)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case i+1 < len(runes) && (string(runes[i:i+2]) == "&&" || string(runes[i:i+2]) == "||" || string(runes[i:i+2]) == "==" ||
+			string(runes[i:i+2]) == "!=" || string(runes[i:i+2]) == "<=" || string(runes[i:i+2]) == ">="):
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case r == '(' || r == ')' || r == '!' || r == '<' || r == '>' || r == '+' || r == '-':
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' in rule '%s'", r, rule)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("rule cannot be empty")
+	}
+	return tokens, nil
+}
+
+type badgeRuleParser struct {
+	tokens []string
+	pos    int
+	facts  map[string]bool
+}
+
+func (p *badgeRuleParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *badgeRuleParser) parseOr() (BadgeRule, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(facts BadgeFacts) int { return boolToInt(l(facts) != 0 || right(facts) != 0) }
+	}
+	return left, nil
+}
+
+func (p *badgeRuleParser) parseAnd() (BadgeRule, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(facts BadgeFacts) int { return boolToInt(l(facts) != 0 && right(facts) != 0) }
+	}
+	return left, nil
+}
+
+func (p *badgeRuleParser) parseNot() (BadgeRule, error) {
+	if p.peek() == "!" {
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(facts BadgeFacts) int { return boolToInt(expr(facts) == 0) }, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *badgeRuleParser) parseComparison() (BadgeRule, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	var compare func(a int, b int) bool
+	switch op {
+	case "==":
+		compare = func(a int, b int) bool { return a == b }
+	case "!=":
+		compare = func(a int, b int) bool { return a != b }
+	case "<":
+		compare = func(a int, b int) bool { return a < b }
+	case "<=":
+		compare = func(a int, b int) bool { return a <= b }
+	case ">":
+		compare = func(a int, b int) bool { return a > b }
+	case ">=":
+		compare = func(a int, b int) bool { return a >= b }
+	default:
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return func(facts BadgeFacts) int { return boolToInt(compare(left(facts), right(facts))) }, nil
+}
+
+func (p *badgeRuleParser) parseSum() (BadgeRule, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.peek()
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "+" {
+			left = func(facts BadgeFacts) int { return l(facts) + right(facts) }
+		} else {
+			left = func(facts BadgeFacts) int { return l(facts) - right(facts) }
+		}
+	}
+	return left, nil
+}
+
+func (p *badgeRuleParser) parseUnary() (BadgeRule, error) {
+	if p.peek() == "-" {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(facts BadgeFacts) int { return -expr(facts) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *badgeRuleParser) parsePrimary() (BadgeRule, error) {
+	token := p.peek()
+	if token == "" {
+		return nil, fmt.Errorf("unexpected end of rule")
+	}
+	p.pos++
+	if token == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return expr, nil
+	}
+	if value, err := strconv.Atoi(token); err == nil {
+		return func(facts BadgeFacts) int { return value }, nil
+	}
+	if value, ok := badgeRuleConstants[token]; ok {
+		return func(facts BadgeFacts) int { return value }, nil
+	}
+	if p.facts[token] {
+		return func(facts BadgeFacts) int { return facts[token] }, nil
+	}
+	return nil, fmt.Errorf("unknown fact '%s'", token)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadBadgeDefinitions will check that the bundled badge definitions are valid
+func TestLoadBadgeDefinitions(t *testing.T) {
+	badges, err := LoadBadgeDefinitions("../config/badges.yaml")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, badges)
+}
+
+// TestParseBadgeDefinitions will check that invalid definitions are rejected
+func TestParseBadgeDefinitions(t *testing.T) {
+	_, err := ParseBadgeDefinitions([]byte("- {id: 1, scope: leg, rule: 'checkout.score >= 100 && (time.hour < 6 || !player.winner)'}"))
+	assert.NoError(t, err)
+
+	_, err = ParseBadgeDefinitions([]byte("- {id: 1, scope: leg, rule: visit.score == 180}"))
+	assert.Error(t, err, "unknown fact")
+	_, err = ParseBadgeDefinitions([]byte("- {id: 1, scope: leg, rule: 'player.winner &&'}"))
+	assert.Error(t, err, "incomplete rule")
+	_, err = ParseBadgeDefinitions([]byte("- {id: 1, scope: unknown, rule: 'true'}"))
+	assert.Error(t, err, "unknown scope")
+	_, err = ParseBadgeDefinitions([]byte("- {id: 1, scope: leg, rule: 'true'}\n- {id: 1, scope: leg, rule: 'false'}"))
+	assert.Error(t, err, "duplicate id")
+	_, err = ParseBadgeDefinitions([]byte("- {id: 1, scope: visit, rule: 'true', levels: [10, 1]}"))
+	assert.Error(t, err, "levels not ascending")
+}
+
+// TestEvaluateBadgeDefinitions will check that badges are evaluated against the facts of a leg and its visits
+func TestEvaluateBadgeDefinitions(t *testing.T) {
+	badges, err := ParseBadgeDefinitions([]byte(`
+- {id: 1, scope: leg, rule: 'player.checkout && checkout.score == 123 && last_dart.multiplier == double'}
+- {id: 2, scope: leg, rule: 'time.month == 12 && time.day == 25'}
+- {id: 3, scope: leg, rule: 'player.winner && opponent.bot_skill == 2'}
+- {id: 4, scope: visit, rule: 'visit.score == 180', levels: [1, 10, 50], statistic: score_180s}`))
+	assert.NoError(t, err)
+
+	dart := func(value int64, multiplier int64) *Dart {
+		return &Dart{Value: null.IntFrom(value), Multiplier: multiplier}
+	}
+	leg := &Leg{Players: []int{1, 2}, StartingScore: 501, WinnerPlayerID: null.IntFrom(1),
+		Endtime: null.TimeFrom(time.Date(2020, 12, 25, 20, 0, 0, 0, time.UTC)),
+		Visits:  []*Visit{{PlayerID: 1, FirstDart: dart(20, TRIPLE), SecondDart: dart(13, SINGLE), ThirdDart: dart(25, DOUBLE)}}}
+	match := &Match{MatchType: &MatchType{ID: X01}}
+	players := []*Player2Leg{{PlayerID: 1, Player: &Player{}}, {PlayerID: 2, Player: &Player{IsBot: true}, BotConfig: &BotConfig{Skill: null.IntFrom(BOT_MEDIUM)}}}
+
+	winner := GetLegBadgeFacts(leg, match, players, nil, 1)
+	loser := GetLegBadgeFacts(leg, match, players, nil, 2)
+	assert.True(t, badges[0].Evaluate(winner))
+	assert.False(t, badges[0].Evaluate(loser))
+	assert.True(t, badges[1].Evaluate(winner))
+	assert.True(t, badges[1].Evaluate(loser))
+	assert.True(t, badges[2].Evaluate(winner))
+	assert.False(t, badges[2].Evaluate(loser))
+
+	assert.True(t, badges[3].Evaluate(GetVisitBadgeFacts(&Visit{Score: 180, FirstDart: dart(20, TRIPLE), SecondDart: dart(20, TRIPLE), ThirdDart: dart(20, TRIPLE)}, X01)))
+	assert.Equal(t, 2, badges[3].GetLevel(&BadgeStatistics{Score180s: 9}, 2))
+}
+
+// TestEvaluateStatisticsBadgeDefinitions will check that badges can be evaluated against finished leg statistics of other game types
+func TestEvaluateStatisticsBadgeDefinitions(t *testing.T) {
+	badges, err := ParseBadgeDefinitions([]byte(`
+- {id: 1, scope: leg, rule: 'leg.type == cricket && stats.max_round_marks == 9'}
+- {id: 2, scope: leg, rule: 'leg.type == tic_tac_toe && player.winner && opponents.numbers_closed == 0'}`))
+	assert.NoError(t, err)
+
+	leg := &Leg{Players: []int{1, 2}, WinnerPlayerID: null.IntFrom(1)}
+	cricket := &Match{MatchType: &MatchType{ID: CRICKET}}
+	statistics := map[int]BadgeFacts{
+		1: (&StatisticsCricket{Marks5: 2, Marks9: 1}).GetBadgeFacts(),
+		2: (&StatisticsCricket{Marks7: 1}).GetBadgeFacts(),
+	}
+	SetOpponentBadgeFacts(statistics)
+	assert.Equal(t, 7, statistics[1]["opponents.max_round_marks"])
+	assert.True(t, badges[0].Evaluate(GetLegBadgeFacts(leg, cricket, nil, statistics, 1)))
+	assert.False(t, badges[0].Evaluate(GetLegBadgeFacts(leg, cricket, nil, statistics, 2)))
+
+	ticTacToe := &Match{MatchType: &MatchType{ID: TICTACTOE}}
+	statistics = map[int]BadgeFacts{
+		1: (&StatisticsTicTacToe{NumbersClosed: 3}).GetBadgeFacts(),
+		2: (&StatisticsTicTacToe{NumbersClosed: 0}).GetBadgeFacts(),
+	}
+	SetOpponentBadgeFacts(statistics)
+	assert.True(t, badges[1].Evaluate(GetLegBadgeFacts(leg, ticTacToe, nil, statistics, 1)))
+	statistics[2]["stats.numbers_closed"] = 1
+	SetOpponentBadgeFacts(statistics)
+	assert.False(t, badges[1].Evaluate(GetLegBadgeFacts(leg, ticTacToe, nil, statistics, 1)))
+}
+
+// TestGetMatchBadgeFacts will check the facts of a match won from behind in a deciding leg
+func TestGetMatchBadgeFacts(t *testing.T) {
+	winner := func(legID int, playerID int64) *Leg {
+		return &Leg{ID: legID, WinnerPlayerID: null.IntFrom(playerID)}
+	}
+	match := &Match{MatchType: &MatchType{ID: X01}, Players: []int{1, 2}, WinnerID: null.IntFrom(1),
+		Legs:      []*Leg{winner(1, 2), winner(2, 2), winner(3, 1), winner(4, 1), winner(5, 1)},
+		EloChange: map[int]*PlayerElo{1: {CurrentElo: 1300}, 2: {CurrentElo: 1550}}}
+	darts := map[int]map[int]int{3: {1: 15}, 4: {1: 21}, 5: {1: 18}}
+
+	facts := GetMatchBadgeFacts(match, darts, 1)
+	assert.Equal(t, 1, facts["player.winner"])
+	assert.Equal(t, 3, facts["player.legs_won"])
+	assert.Equal(t, 2, facts["opponents.legs_won"])
+	assert.Equal(t, 2, facts["player.max_deficit"])
+	assert.Equal(t, 1, facts["player.won_decider"])
+	assert.Equal(t, 21, facts["player.max_winning_darts"])
+	assert.Equal(t, 1, facts["match.rated"])
+	assert.Equal(t, 250, facts["opponents.elo"]-facts["player.elo"])
+
+	facts = GetMatchBadgeFacts(match, darts, 2)
+	assert.Equal(t, 0, facts["player.winner"])
+	assert.Equal(t, 1, facts["player.max_deficit"])
+	assert.Equal(t, 0, facts["player.won_decider"])
+}
+
+// TestLegBadgeStatistics will check that the finished leg statistics of each leg type with leg badges provide badge facts per player
+func TestLegBadgeStatistics(t *testing.T) {
+	statistics := []LegBadgeStatistics{&StatisticsCricket{PlayerID: 1}, &StatisticsAroundThe{PlayerID: 2}, &StatisticsTicTacToe{PlayerID: 3},
+		&StatisticsBermudaTriangle{PlayerID: 4}, &Statistics420{PlayerID: 5}, &StatisticsDartsAtX{PlayerID: 6}, &StatisticsKillBull{PlayerID: 7},
+		&StatisticsGotcha{PlayerID: 8}, &StatisticsJDCPractice{PlayerID: 9}, &StatisticsKnockout{PlayerID: 10}, &StatisticsScam{PlayerID: 11},
+		&StatisticsShootout{PlayerID: 12}}
+	for i, stats := range statistics {
+		assert.Equal(t, i+1, stats.GetPlayerID())
+		assert.NotNil(t, stats.GetBadgeFacts())
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+// BoardNumbers contains the numbers of the dartboard in clockwise order, starting from the top
+var BoardNumbers = []int{20, 1, 18, 4, 13, 6, 10, 15, 2, 17, 3, 19, 7, 16, 8, 11, 14, 9, 12, 5}
+
+// GetBoardPosition will return the clockwise position (0-19) of the given number on the board, or -1 if the number is not a segment
+func GetBoardPosition(number int) int {
+	for i, num := range BoardNumbers {
+		if num == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetSegmentOffset will return how many segments the hit number is from the target number.
+// Positive values are clockwise, negative values are anticlockwise
+func GetSegmentOffset(target int, hit int) int {
+	targetPos := GetBoardPosition(target)
+	hitPos := GetBoardPosition(hit)
+	if targetPos == -1 || hitPos == -1 {
+		return 0
+	}
+	offset := (hitPos - targetPos + len(BoardNumbers)) % len(BoardNumbers)
+	if offset > len(BoardNumbers)/2 {
+		offset -= len(BoardNumbers)
+	}
+	return offset
+}
+
+// GetRadialDirection will return the direction of a miss from the target ring towards (-1) or away from (1) the center of the board.
+// 0 is returned if the dart hit the target ring, or if the direction cannot be determined, e.g. a single when aiming for a triple
+func GetRadialDirection(target *AccuracyTarget, dart *Dart) int {
+	if target.Multiplier == 0 {
+		return 0
+	}
+	if dart.IsMiss() {
+		return 1
+	}
+	if target.Value == BULLSEYE {
+		if !dart.IsBull() || dart.Multiplier < target.Multiplier {
+			return 1
+		}
+		return 0
+	}
+	if dart.IsBull() {
+		return -1
+	}
+	switch target.Multiplier {
+	case SINGLE:
+		if dart.IsDouble() {
+			return 1
+		}
+	case DOUBLE:
+		if !dart.IsDouble() {
+			return -1
+		}
+	case TRIPLE:
+		if dart.IsDouble() {
+			return 1
+		}
+	}
+	return 0
+}
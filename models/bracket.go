@@ -0,0 +1,319 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Bracket types
+const (
+	BracketSingleElimination = "single"
+	BracketDoubleElimination = "double"
+)
+
+// Bracket seeding methods
+const (
+	BracketSeedingElo    = "elo"
+	BracketSeedingGroup  = "group"
+	BracketSeedingManual = "manual"
+)
+
+// Brackets a match can be part of
+const (
+	BracketWinners    = "winners"
+	BracketLosers     = "losers"
+	BracketFinal      = "final"
+	BracketThirdPlace = "third_place"
+)
+
+// BracketOptions struct used for configuring generation of an elimination bracket
+type BracketOptions struct {
+	Type            string `json:"type"`
+	Seeding         string `json:"seeding"`
+	ThirdPlaceMatch bool   `json:"third_place_match"`
+	Players         []int  `json:"players"`
+}
+
+// BracketSource struct used for describing where a player in a bracket match comes from.
+// Either a seeded player, a bye, or the winner or looser of another match
+type BracketSource struct {
+	PlayerID int  `json:"player_id,omitempty"`
+	IsBye    bool `json:"is_bye,omitempty"`
+	MatchIdx int  `json:"match_idx"`
+	IsWinner bool `json:"is_winner,omitempty"`
+}
+
+// BracketMatch struct used for storing a single match in an elimination bracket
+type BracketMatch struct {
+	Idx            int            `json:"idx"`
+	Bracket        string         `json:"bracket"`
+	Round          int            `json:"round"`
+	RoundSize      int            `json:"round_size"`
+	Number         int            `json:"number"`
+	Level          int            `json:"level"`
+	Name           string         `json:"name"`
+	Home           *BracketSource `json:"home"`
+	Away           *BracketSource `json:"away"`
+	WinnerTo       int            `json:"winner_to"`
+	IsWinnerToHome bool           `json:"is_winner_to_home"`
+	LooserTo       int            `json:"looser_to"`
+	IsLooserToHome bool           `json:"is_looser_to_home"`
+	IsSemiFinal    bool           `json:"is_semi_final"`
+	IsGrandFinal   bool           `json:"is_grand_final"`
+	// forward is the outcome used in place of this match when it is removed because of a bye
+	forward map[bool]*BracketSource
+}
+
+// Bracket struct used for storing a generated elimination bracket
+type Bracket struct {
+	Type    string          `json:"type"`
+	Size    int             `json:"size"`
+	Matches []*BracketMatch `json:"matches"`
+}
+
+// GetSeedOrder will return the standard seed order for a bracket of the given size, such that
+// the top seeds can only meet in the latest possible round. Size must be a power of two
+func GetSeedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0)
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+func winnerOf(idx int) *BracketSource {
+	return &BracketSource{MatchIdx: idx, IsWinner: true}
+}
+
+func looserOf(idx int) *BracketSource {
+	return &BracketSource{MatchIdx: idx, IsWinner: false}
+}
+
+func (bracket *Bracket) add(name string, round int, roundSize int, home *BracketSource, away *BracketSource) int {
+	match := &BracketMatch{Idx: len(bracket.Matches), Bracket: name, Round: round, RoundSize: roundSize, Home: home, Away: away, WinnerTo: -1, LooserTo: -1}
+	bracket.Matches = append(bracket.Matches, match)
+	return match.Idx
+}
+
+// NewBracket will generate a new elimination bracket for the given players, ordered by seed.
+// Top seeds are given byes when the number of players is not a power of two, and matches against byes are removed
+func NewBracket(seeds []int, options *BracketOptions) (*Bracket, error) {
+	if len(seeds) < 2 {
+		return nil, errors.New("at least two players are required to generate a bracket")
+	}
+	if options.Type != BracketSingleElimination && options.Type != BracketDoubleElimination {
+		return nil, fmt.Errorf("unknown bracket type '%s'", options.Type)
+	}
+	size := 2
+	rounds := 1
+	for size < len(seeds) {
+		size *= 2
+		rounds++
+	}
+	bracket := &Bracket{Type: options.Type, Size: size, Matches: make([]*BracketMatch, 0)}
+
+	seed := func(num int) *BracketSource {
+		if num > len(seeds) {
+			return &BracketSource{IsBye: true, MatchIdx: -1}
+		}
+		return &BracketSource{PlayerID: seeds[num-1], MatchIdx: -1}
+	}
+
+	// Winners bracket
+	order := GetSeedOrder(size)
+	winnerRounds := make([][]int, 0)
+	previous := make([]int, 0)
+	for i := 0; i < size/2; i++ {
+		previous = append(previous, bracket.add(BracketWinners, 1, size/2, seed(order[2*i]), seed(order[2*i+1])))
+	}
+	winnerRounds = append(winnerRounds, previous)
+	for round := 2; round <= rounds; round++ {
+		current := make([]int, 0)
+		for i := 0; i < len(previous)/2; i++ {
+			current = append(current, bracket.add(BracketWinners, round, len(previous)/2, winnerOf(previous[2*i]), winnerOf(previous[2*i+1])))
+		}
+		winnerRounds = append(winnerRounds, current)
+		previous = current
+	}
+	final := previous[0]
+
+	if options.Type == BracketDoubleElimination && rounds > 1 {
+		// Loosers of the first round play each other, then each following round of the losers bracket
+		// is played against the loosers dropping down from the winners bracket
+		round := 1
+		losers := make([]int, 0)
+		first := winnerRounds[0]
+		for i := 0; i < len(first)/2; i++ {
+			losers = append(losers, bracket.add(BracketLosers, round, len(first)/2, looserOf(first[2*i]), looserOf(first[2*i+1])))
+		}
+		for r := 2; r <= rounds; r++ {
+			round++
+			dropping := winnerRounds[r-1]
+			current := make([]int, 0)
+			for i := range losers {
+				j := i
+				if r%2 == 0 {
+					// Reverse the order of players dropping down, to avoid early rematches
+					j = len(dropping) - 1 - i
+				}
+				current = append(current, bracket.add(BracketLosers, round, len(losers), winnerOf(losers[i]), looserOf(dropping[j])))
+			}
+			losers = current
+			if len(losers) > 1 {
+				round++
+				current := make([]int, 0)
+				for i := 0; i < len(losers)/2; i++ {
+					current = append(current, bracket.add(BracketLosers, round, len(losers)/2, winnerOf(losers[2*i]), winnerOf(losers[2*i+1])))
+				}
+				losers = current
+			}
+		}
+		bracket.add(BracketFinal, 1, 1, winnerOf(final), winnerOf(losers[0]))
+	} else if options.ThirdPlaceMatch && rounds > 1 {
+		semis := winnerRounds[rounds-2]
+		bracket.add(BracketThirdPlace, 1, 1, looserOf(semis[0]), looserOf(semis[1]))
+	}
+
+	bracket.removeByes()
+	bracket.wire()
+	return bracket, nil
+}
+
+// resolve will return the source to use in place of the given source, if it refers to a removed match
+func (bracket *Bracket) resolve(source *BracketSource) *BracketSource {
+	if source.MatchIdx == -1 {
+		return source
+	}
+	match := bracket.Matches[source.MatchIdx]
+	if match.forward != nil {
+		return match.forward[source.IsWinner]
+	}
+	return source
+}
+
+// removeByes will remove all matches with a bye, forwarding the other player (or outcome) to the next match
+func (bracket *Bracket) removeByes() {
+	for _, match := range bracket.Matches {
+		match.Home = bracket.resolve(match.Home)
+		match.Away = bracket.resolve(match.Away)
+		bye := &BracketSource{IsBye: true, MatchIdx: -1}
+		if match.Home.IsBye {
+			match.forward = map[bool]*BracketSource{true: match.Away, false: bye}
+		} else if match.Away.IsBye {
+			match.forward = map[bool]*BracketSource{true: match.Home, false: bye}
+		}
+	}
+
+	matches := make([]*BracketMatch, 0)
+	indices := make(map[int]int)
+	for _, match := range bracket.Matches {
+		if match.forward != nil {
+			continue
+		}
+		indices[match.Idx] = len(matches)
+		match.Idx = len(matches)
+		matches = append(matches, match)
+	}
+	for _, match := range matches {
+		for _, source := range []*BracketSource{match.Home, match.Away} {
+			if source.MatchIdx != -1 {
+				source.MatchIdx = indices[source.MatchIdx]
+			}
+		}
+	}
+	bracket.Matches = matches
+}
+
+// wire will set the outcome of each match, and give each match a name, number and level based on when it can be played
+func (bracket *Bracket) wire() {
+	for _, match := range bracket.Matches {
+		match.Level = 1
+		for i, source := range []*BracketSource{match.Home, match.Away} {
+			if source.MatchIdx == -1 {
+				continue
+			}
+			from := bracket.Matches[source.MatchIdx]
+			if source.IsWinner {
+				from.WinnerTo = match.Idx
+				from.IsWinnerToHome = i == 0
+			} else {
+				from.LooserTo = match.Idx
+				from.IsLooserToHome = i == 0
+			}
+			if from.Level+1 > match.Level {
+				match.Level = from.Level + 1
+			}
+		}
+	}
+
+	numbers := make(map[string]int)
+	for _, match := range bracket.Matches {
+		key := fmt.Sprintf("%s-%d", match.Bracket, match.Round)
+		numbers[key]++
+		match.Number = numbers[key]
+		match.Name = getBracketMatchName(bracket.Type, match)
+	}
+	for _, match := range bracket.Matches {
+		if numbers[fmt.Sprintf("%s-%d", match.Bracket, match.Round)] == 1 {
+			// Only match in round, so no need to number it
+			match.Number = 0
+			match.Name = getBracketMatchName(bracket.Type, match)
+		}
+	}
+}
+
+// GetOrderOfPlay will return the matches of the bracket in the order they can be played
+func (bracket *Bracket) GetOrderOfPlay() []*BracketMatch {
+	matches := make([]*BracketMatch, len(bracket.Matches))
+	copy(matches, bracket.Matches)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Level < matches[j].Level
+	})
+	return matches
+}
+
+func getBracketMatchName(bracketType string, match *BracketMatch) string {
+	name := ""
+	switch match.Bracket {
+	case BracketFinal:
+		match.IsGrandFinal = true
+		return "Grand Final"
+	case BracketThirdPlace:
+		return "Third Place"
+	case BracketLosers:
+		name = fmt.Sprintf("Losers Round %d", match.Round)
+		if match.RoundSize == 1 && match.Number == 0 {
+			return "Losers Final"
+		}
+	case BracketWinners:
+		switch match.RoundSize {
+		case 1:
+			if bracketType == BracketDoubleElimination {
+				return "Winners Final"
+			}
+			match.IsGrandFinal = true
+			return "Grand Final"
+		case 2:
+			match.IsSemiFinal = bracketType == BracketSingleElimination
+			name = "Semi Final"
+		case 4:
+			name = "Quarter Final"
+		case 8:
+			name = "Last 16"
+		default:
+			name = fmt.Sprintf("Round %d", match.Round)
+		}
+	}
+	if match.Number > 0 {
+		if match.Bracket == BracketLosers || match.RoundSize > 8 {
+			return fmt.Sprintf("%s - Match %d", name, match.Number)
+		}
+		return fmt.Sprintf("%s %d", name, match.Number)
+	}
+	return name
+}
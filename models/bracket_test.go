@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetSeedOrder will check that top seeds are placed as far apart as possible
+func TestGetSeedOrder(t *testing.T) {
+	assert.Equal(t, []int{1, 2}, GetSeedOrder(2))
+	assert.Equal(t, []int{1, 8, 4, 5, 2, 7, 3, 6}, GetSeedOrder(8))
+}
+
+// TestNewBracketSingleElimination will check that top seeds get byes, and that outcomes are wired
+func TestNewBracketSingleElimination(t *testing.T) {
+	bracket, err := NewBracket([]int{11, 12, 13, 14, 15, 16}, &BracketOptions{Type: BracketSingleElimination, ThirdPlaceMatch: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 8, bracket.Size)
+	// 5 matches to find a winner, and a third place match
+	assert.Len(t, bracket.Matches, 6)
+
+	// Seeds 1 and 2 have byes, so they go directly to the semi finals
+	semi1 := bracket.Matches[2]
+	assert.Equal(t, "Semi Final 1", semi1.Name)
+	assert.True(t, semi1.IsSemiFinal)
+	assert.Equal(t, 11, semi1.Home.PlayerID)
+	assert.Equal(t, 0, semi1.Away.MatchIdx)
+	assert.Equal(t, 2, bracket.Matches[0].WinnerTo)
+	assert.False(t, bracket.Matches[0].IsWinnerToHome)
+
+	final := bracket.Matches[4]
+	assert.True(t, final.IsGrandFinal)
+	assert.Equal(t, "Third Place", bracket.Matches[5].Name)
+	assert.Equal(t, 5, semi1.LooserTo)
+	assert.Equal(t, 3, final.Level)
+}
+
+// TestNewBracketDoubleElimination will check that all loosers drop down to the losers bracket
+func TestNewBracketDoubleElimination(t *testing.T) {
+	bracket, err := NewBracket([]int{1, 2, 3, 4, 5, 6, 7, 8}, &BracketOptions{Type: BracketDoubleElimination})
+	assert.Nil(t, err)
+	assert.Len(t, bracket.Matches, 14)
+	for _, match := range bracket.Matches {
+		if match.Bracket == BracketWinners {
+			assert.NotEqual(t, -1, match.LooserTo, "looser of %s should drop down", match.Name)
+		}
+	}
+	grandFinal := bracket.Matches[len(bracket.Matches)-1]
+	assert.Equal(t, "Grand Final", grandFinal.Name)
+	assert.Equal(t, "Losers Final", bracket.Matches[grandFinal.Away.MatchIdx].Name)
+
+	_, err = NewBracket([]int{1}, &BracketOptions{Type: BracketSingleElimination})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,150 @@
+package models
+
+// MaxCheckout is the highest score which can be checked out with three darts
+const MaxCheckout = 170
+
+// PreferredDoubles are the scores players are assumed to prefer leaving for a checkout, most preferred first
+var PreferredDoubles = []int{32, 40, 16, 24, 36, 20, 8, 12, 28, 4, 18, 10, 14, 26, 30, 34, 38, 22, 6, 2, 50}
+
+// checkoutRoute is a route for checking out a score, with the number of non-single setup darts to choose between equal routes
+type checkoutRoute struct {
+	targets []*AccuracyTarget
+	penalty int
+}
+
+// checkoutRoutes is the checkout table holding the preferred route for each score, indexed by number of darts and score
+var checkoutRoutes [4][MaxCheckout + 1]*checkoutRoute
+
+// setupTargets are the targets which can be used to set up a checkout, in order of preference when routes are equal
+var setupTargets []*AccuracyTarget
+
+func init() {
+	for _, multiplier := range []int64{TRIPLE, SINGLE} {
+		for value := 20; value > 0; value-- {
+			setupTargets = append(setupTargets, &AccuracyTarget{Value: value, Multiplier: multiplier})
+		}
+	}
+	setupTargets = append(setupTargets, &AccuracyTarget{Value: BULLSEYE, Multiplier: SINGLE})
+	for value := 20; value > 0; value-- {
+		setupTargets = append(setupTargets, &AccuracyTarget{Value: value, Multiplier: DOUBLE})
+	}
+	setupTargets = append(setupTargets, &AccuracyTarget{Value: BULLSEYE, Multiplier: DOUBLE})
+
+	for remaining := 2; remaining <= MaxCheckout; remaining++ {
+		if target := getFinishTarget(remaining); target != nil {
+			checkoutRoutes[1][remaining] = &checkoutRoute{targets: []*AccuracyTarget{target}}
+		}
+	}
+	for darts := 2; darts <= 3; darts++ {
+		for remaining := 2; remaining <= MaxCheckout; remaining++ {
+			best := checkoutRoutes[darts-1][remaining]
+			if best == nil {
+				for _, target := range setupTargets {
+					left := remaining - target.GetScore()
+					if left < 2 || checkoutRoutes[darts-1][left] == nil {
+						continue
+					}
+					next := checkoutRoutes[darts-1][left]
+					route := &checkoutRoute{
+						targets: append([]*AccuracyTarget{target}, next.targets...),
+						penalty: next.penalty + getSetupPenalty(target),
+					}
+					if best == nil || route.isBetter(best) {
+						best = route
+					}
+				}
+			}
+			checkoutRoutes[darts][remaining] = best
+		}
+	}
+}
+
+// GetCheckoutRoute will return the preferred route for checking out the given score with the given number of darts,
+// or nil if the score cannot be checked out
+func GetCheckoutRoute(remaining int, darts int) []*AccuracyTarget {
+	if darts > 3 {
+		darts = 3
+	}
+	if darts < 1 || remaining < 2 || remaining > MaxCheckout || checkoutRoutes[darts][remaining] == nil {
+		return nil
+	}
+	return checkoutRoutes[darts][remaining].targets
+}
+
+// GetSetupTarget will return the preferred target for setting up a checkout in the next visit for the given score,
+// or nil if no checkout can be set up
+func GetSetupTarget(remaining int) *AccuracyTarget {
+	var best *checkoutRoute
+	var bestTarget *AccuracyTarget
+	for darts := 1; darts <= 3 && best == nil; darts++ {
+		for _, target := range setupTargets {
+			left := remaining - target.GetScore()
+			if left < 2 || left > MaxCheckout || checkoutRoutes[darts][left] == nil {
+				continue
+			}
+			next := checkoutRoutes[darts][left]
+			route := &checkoutRoute{targets: next.targets, penalty: next.penalty + getSetupPenalty(target)}
+			if best == nil || route.isBetter(best) {
+				best = route
+				bestTarget = target
+			}
+		}
+	}
+	return bestTarget
+}
+
+// GetScore will return the score of hitting this target
+func (target *AccuracyTarget) GetScore() int {
+	multiplier := int(target.Multiplier)
+	if multiplier == 0 {
+		multiplier = SINGLE
+	}
+	return target.Value * multiplier
+}
+
+// isBetter will check if this route is preferred over the given route, by number of darts, setup darts and double left
+func (route *checkoutRoute) isBetter(other *checkoutRoute) bool {
+	if len(route.targets) != len(other.targets) {
+		return len(route.targets) < len(other.targets)
+	}
+	if route.penalty != other.penalty {
+		return route.penalty < other.penalty
+	}
+	return getDoubleRank(route.finish()) < getDoubleRank(other.finish())
+}
+
+func (route *checkoutRoute) finish() *AccuracyTarget {
+	return route.targets[len(route.targets)-1]
+}
+
+// getFinishTarget will return the double which checks out the given score with a single dart, if any
+func getFinishTarget(remaining int) *AccuracyTarget {
+	if remaining == 50 {
+		return &AccuracyTarget{Value: BULLSEYE, Multiplier: DOUBLE}
+	}
+	if remaining > 1 && remaining <= 40 && remaining%2 == 0 {
+		return &AccuracyTarget{Value: remaining / 2, Multiplier: DOUBLE}
+	}
+	return nil
+}
+
+// getSetupPenalty will return how much the given setup target is avoided, singles being the safest
+func getSetupPenalty(target *AccuracyTarget) int {
+	switch {
+	case target.Multiplier == DOUBLE:
+		return 2
+	case target.Multiplier == TRIPLE || target.Value == BULLSEYE:
+		return 1
+	}
+	return 0
+}
+
+func getDoubleRank(target *AccuracyTarget) int {
+	score := target.GetScore()
+	for i, preferred := range PreferredDoubles {
+		if preferred == score {
+			return i
+		}
+	}
+	return len(PreferredDoubles)
+}
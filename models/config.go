@@ -21,10 +21,20 @@ type APIConfig struct {
 	Port int `yaml:"port"`
 }
 
+// EloConfig struct config
+type EloConfig struct {
+	DecayAfterDays     int     `yaml:"decay_after_days"`
+	DecayRate          float64 `yaml:"decay_rate"`
+	ProvisionalMatches int     `yaml:"provisional_matches"`
+}
+
 // Config type
 type Config struct {
 	DBConfig  DBConfig  `yaml:"db"`
 	APIConfig APIConfig `yaml:"api"`
+	EloConfig EloConfig `yaml:"elo"`
+	// BadgesFile is the yaml file containing badge definitions
+	BadgesFile string `yaml:"badges_file"`
 }
 
 // GetConfig loads configuration from yaml file
@@ -38,7 +48,7 @@ func GetConfig(configFileParam string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	config := new(Config)
+	config := &Config{EloConfig: *EloSettings, BadgesFile: "config/badges.yaml"}
 	err = yaml.Unmarshal(yamlFile, config)
 	if err != nil {
 		return nil, err
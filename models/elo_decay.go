@@ -0,0 +1,37 @@
+package models
+
+import (
+	"math"
+
+	"github.com/guregu/null"
+)
+
+// EloSettings holds the Elo decay and provisional settings, and can be overridden from the config file
+var EloSettings = &EloConfig{DecayAfterDays: 180, DecayRate: 0.1, ProvisionalMatches: 6}
+
+// PlayerEloDecay struct used for storing the decay of an inactive player
+type PlayerEloDecay struct {
+	PlayerID     int      `json:"player_id"`
+	PlayerName   string   `json:"player_name"`
+	OfficeID     null.Int `json:"office_id"`
+	Elo          int      `json:"elo"`
+	EloNew       int      `json:"elo_new"`
+	OfficeMean   int      `json:"office_mean"`
+	InactiveDays int      `json:"inactive_days"`
+}
+
+// IsProvisional will check if a rating based on the given number of matches is still provisional
+func (config *EloConfig) IsProvisional(matches int) bool {
+	return matches < config.ProvisionalMatches
+}
+
+// IsInactive will check if a player without activity for the given number of days should have their Elo decayed
+func (config *EloConfig) IsInactive(days int) bool {
+	return config.DecayAfterDays > 0 && days >= config.DecayAfterDays
+}
+
+// CalculateEloDecay will return the new Elo after moving the given Elo toward the office mean by the decay rate
+func (config *EloConfig) CalculateEloDecay(elo int, mean int) int {
+	rate := math.Max(0, math.Min(1, config.DecayRate))
+	return elo - int(math.Round(float64(elo-mean)*rate))
+}
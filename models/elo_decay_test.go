@@ -0,0 +1,23 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculateEloDecay will check that ratings move toward the office mean, and provisional and inactive thresholds
+func TestCalculateEloDecay(t *testing.T) {
+	config := &EloConfig{DecayAfterDays: 180, DecayRate: 0.1, ProvisionalMatches: 6}
+	assert.Equal(t, 1580, config.CalculateEloDecay(1600, 1400))
+	assert.Equal(t, 1420, config.CalculateEloDecay(1400, 1600))
+	assert.Equal(t, 1500, config.CalculateEloDecay(1500, 1500))
+
+	assert.True(t, config.IsProvisional(5))
+	assert.False(t, config.IsProvisional(6))
+	assert.False(t, config.IsInactive(179))
+	assert.True(t, config.IsInactive(180))
+
+	config.DecayAfterDays = 0
+	assert.False(t, config.IsInactive(365))
+}
@@ -0,0 +1,341 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Export formats
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatJSONL = "jsonl"
+)
+
+// Export column types
+const (
+	ExportTypeInt      = "int"
+	ExportTypeFloat    = "float"
+	ExportTypeBool     = "bool"
+	ExportTypeString   = "string"
+	ExportTypeDatetime = "datetime"
+)
+
+// ExportColumn struct used for describing a single column of an export table
+type ExportColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// ExportTable struct used for describing the schema of an export table
+type ExportTable struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Columns     []*ExportColumn `json:"columns"`
+}
+
+// ExportFilter struct used for filtering exported rows. From is inclusive and To is exclusive, as for statistics
+type ExportFilter struct {
+	OfficeID    null.Int    `json:"office_id"`
+	PlayerID    null.Int    `json:"player_id"`
+	MatchTypeID null.Int    `json:"match_type_id"`
+	From        null.String `json:"from"`
+	To          null.String `json:"to"`
+}
+
+// Validate will check that the dates of the filter are valid
+func (filter *ExportFilter) Validate() error {
+	for _, date := range []null.String{filter.From, filter.To} {
+		if !date.Valid {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date.String); err != nil {
+			return fmt.Errorf("invalid date '%s', expected format is yyyy-MM-dd", date.String)
+		}
+	}
+	return nil
+}
+
+func exportColumn(name string, typ string, description string) *ExportColumn {
+	return &ExportColumn{Name: name, Type: typ, Description: description}
+}
+
+func exportColumns(typ string, names ...string) []*ExportColumn {
+	cols := make([]*ExportColumn, 0)
+	for _, name := range names {
+		cols = append(cols, exportColumn(name, typ, ""))
+	}
+	return cols
+}
+
+func exportHitRateColumns(nums ...int) []*ExportColumn {
+	cols := make([]*ExportColumn, 0)
+	for _, num := range nums {
+		name := fmt.Sprintf("hit_rate_%d", num)
+		if num == BULLSEYE {
+			name = "hit_rate_bull"
+		}
+		cols = append(cols, exportColumn(name, ExportTypeFloat, ""))
+	}
+	return cols
+}
+
+func exportStatisticsTable(name string, description string, cols ...[]*ExportColumn) *ExportTable {
+	table := &ExportTable{Name: name, Description: description, Columns: []*ExportColumn{
+		exportColumn("leg_id", ExportTypeInt, "Leg the statistics belong to"),
+		exportColumn("player_id", ExportTypeInt, "Player the statistics belong to"),
+	}}
+	for _, c := range cols {
+		table.Columns = append(table.Columns, c...)
+	}
+	return table
+}
+
+func exportNumberRange(from int, to int, extra ...int) []int {
+	nums := make([]int, 0)
+	for i := from; i <= to; i++ {
+		nums = append(nums, i)
+	}
+	return append(nums, extra...)
+}
+
+// ExportTables contains the schema of all tables which can be exported. Columns are only ever added to the end of a table,
+// never removed or reordered, so that the schema stays stable for consumers
+var ExportTables = []*ExportTable{
+	{Name: "matches", Description: "All matches", Columns: []*ExportColumn{
+		exportColumn("id", ExportTypeInt, "Match ID"),
+		exportColumn("match_type_id", ExportTypeInt, "Match type"),
+		exportColumn("match_mode_id", ExportTypeInt, "Match mode"),
+		exportColumn("office_id", ExportTypeInt, "Office the match was played in"),
+		exportColumn("venue_id", ExportTypeInt, "Venue the match was played at"),
+		exportColumn("tournament_id", ExportTypeInt, "Tournament the match was part of"),
+		exportColumn("winner_id", ExportTypeInt, "Winning player, null for draws and unfinished matches"),
+		exportColumn("is_finished", ExportTypeBool, ""),
+		exportColumn("is_abandoned", ExportTypeBool, ""),
+		exportColumn("is_walkover", ExportTypeBool, ""),
+		exportColumn("is_practice", ExportTypeBool, ""),
+		exportColumn("players", ExportTypeString, "Comma separated list of player IDs in throwing order of the current leg"),
+		exportColumn("created_at", ExportTypeDatetime, ""),
+		exportColumn("updated_at", ExportTypeDatetime, ""),
+	}},
+	{Name: "legs", Description: "All legs", Columns: []*ExportColumn{
+		exportColumn("id", ExportTypeInt, "Leg ID"),
+		exportColumn("match_id", ExportTypeInt, "Match the leg belongs to"),
+		exportColumn("match_type_id", ExportTypeInt, "Match type of the leg, which can differ from the match for tiebreak legs"),
+		exportColumn("starting_score", ExportTypeInt, ""),
+		exportColumn("winner_id", ExportTypeInt, "Winning player"),
+		exportColumn("is_finished", ExportTypeBool, ""),
+		exportColumn("num_players", ExportTypeInt, ""),
+		exportColumn("players", ExportTypeString, "Comma separated list of player IDs in throwing order"),
+		exportColumn("created_at", ExportTypeDatetime, ""),
+		exportColumn("end_time", ExportTypeDatetime, ""),
+	}},
+	{Name: "visits", Description: "All visits (three darts thrown by a player)", Columns: []*ExportColumn{
+		exportColumn("id", ExportTypeInt, "Visit ID"),
+		exportColumn("leg_id", ExportTypeInt, "Leg the visit belongs to"),
+		exportColumn("player_id", ExportTypeInt, "Player throwing the visit"),
+		exportColumn("first_dart", ExportTypeInt, "Value of the first dart, 0 for miss and null if not thrown"),
+		exportColumn("first_dart_multiplier", ExportTypeInt, "Multiplier of the first dart (1 = single, 2 = double, 3 = triple)"),
+		exportColumn("second_dart", ExportTypeInt, ""),
+		exportColumn("second_dart_multiplier", ExportTypeInt, ""),
+		exportColumn("third_dart", ExportTypeInt, ""),
+		exportColumn("third_dart_multiplier", ExportTypeInt, ""),
+		exportColumn("is_bust", ExportTypeBool, ""),
+		exportColumn("created_at", ExportTypeDatetime, ""),
+	}},
+	{Name: "elo_changelog", Description: "Elo changes for each player after each match", Columns: []*ExportColumn{
+		exportColumn("id", ExportTypeInt, ""),
+		exportColumn("match_id", ExportTypeInt, ""),
+		exportColumn("player_id", ExportTypeInt, ""),
+		exportColumn("old_elo", ExportTypeInt, ""),
+		exportColumn("new_elo", ExportTypeInt, ""),
+		exportColumn("old_tournament_elo", ExportTypeInt, "Only set for tournament matches"),
+		exportColumn("new_tournament_elo", ExportTypeInt, "Only set for tournament matches"),
+		exportColumn("created_at", ExportTypeDatetime, "Time the match was last updated"),
+	}},
+	{Name: "badges", Description: "Badges awarded to players", Columns: []*ExportColumn{
+		exportColumn("player_id", ExportTypeInt, ""),
+		exportColumn("badge_id", ExportTypeInt, ""),
+		exportColumn("level", ExportTypeInt, "Level of the badge, null for badges without levels"),
+		exportColumn("value", ExportTypeInt, "Value required to reach the level"),
+		exportColumn("leg_id", ExportTypeInt, "Leg where the badge was achieved"),
+		exportColumn("created_at", ExportTypeDatetime, ""),
+		exportColumn("match_id", ExportTypeInt, "Match where the badge was achieved, also set for leg badges"),
+	}},
+	exportStatisticsTable("statistics_x01", "Statistics for X01 legs",
+		exportColumns(ExportTypeFloat, "ppd", "first_nine_ppd"),
+		exportColumns(ExportTypeInt, "ppd_score", "first_nine_ppd_score"),
+		exportColumns(ExportTypeFloat, "checkout_percentage"),
+		exportColumns(ExportTypeInt, "checkout_attempts", "checkout", "darts_thrown", "60s_plus", "100s_plus", "140s_plus", "180s"),
+		exportColumns(ExportTypeFloat, "accuracy_20", "accuracy_19", "overall_accuracy")),
+	exportStatisticsTable("statistics_shootout", "Statistics for 9 Dart Shootout legs",
+		exportColumns(ExportTypeInt, "score"),
+		exportColumns(ExportTypeFloat, "ppd"),
+		exportColumns(ExportTypeInt, "60s_plus", "100s_plus", "140s_plus", "180s")),
+	exportStatisticsTable("statistics_cricket", "Statistics for Cricket legs",
+		exportColumns(ExportTypeInt, "total_marks", "rounds", "score", "first_nine_marks"),
+		exportColumns(ExportTypeFloat, "mpr", "first_nine_mpr"),
+		exportColumns(ExportTypeInt, "marks5", "marks6", "marks7", "marks8", "marks9")),
+	exportStatisticsTable("statistics_darts_at_x", "Statistics for 99 Darts at X legs",
+		exportColumns(ExportTypeInt, "score", "singles", "doubles", "triples"),
+		exportColumns(ExportTypeFloat, "hit_rate"),
+		exportColumns(ExportTypeInt, "hits5", "hits6", "hits7", "hits8", "hits9")),
+	exportStatisticsTable("statistics_around_the", "Statistics for Around the Clock, Around the World and Shanghai legs",
+		exportColumns(ExportTypeInt, "darts_thrown", "score", "longest_streak", "shanghai"),
+		exportColumns(ExportTypeFloat, "mpr", "total_hit_rate"),
+		exportHitRateColumns(exportNumberRange(1, 20, BULLSEYE)...)),
+	exportStatisticsTable("statistics_tic_tac_toe", "Statistics for Tic-Tac-Toe legs",
+		exportColumns(ExportTypeInt, "darts_thrown", "score", "numbers_closed", "highest_closed")),
+	exportStatisticsTable("statistics_bermuda_triangle", "Statistics for Bermuda Triangle legs",
+		exportColumns(ExportTypeInt, "darts_thrown", "score"),
+		exportColumns(ExportTypeFloat, "mpr"),
+		exportColumns(ExportTypeInt, "total_marks", "highest_score_reached"),
+		exportColumns(ExportTypeFloat, "total_hit_rate"),
+		exportHitRateColumns(exportNumberRange(1, 13)...),
+		exportColumns(ExportTypeInt, "hit_count")),
+	exportStatisticsTable("statistics_420", "Statistics for 420 legs",
+		exportColumns(ExportTypeInt, "score"),
+		exportColumns(ExportTypeFloat, "total_hit_rate"),
+		exportHitRateColumns(exportNumberRange(1, 20, BULLSEYE)...)),
+	exportStatisticsTable("statistics_kill_bull", "Statistics for Kill Bull legs",
+		exportColumns(ExportTypeInt, "darts_thrown", "score", "marks3", "marks4", "marks5", "marks6", "longest_streak", "times_busted"),
+		exportColumns(ExportTypeFloat, "total_hit_rate")),
+	exportStatisticsTable("statistics_gotcha", "Statistics for Gotcha legs",
+		exportColumns(ExportTypeInt, "darts_thrown", "highest_score", "times_reset", "others_reset", "score")),
+	exportStatisticsTable("statistics_jdc_practice", "Statistics for JDC Practice legs",
+		exportColumns(ExportTypeInt, "darts_thrown", "score"),
+		exportColumns(ExportTypeFloat, "mpr"),
+		exportColumns(ExportTypeInt, "shanghai_count"),
+		exportColumns(ExportTypeFloat, "doubles_hitrate")),
+	exportStatisticsTable("statistics_knockout", "Statistics for Knockout legs",
+		exportColumns(ExportTypeInt, "darts_thrown"),
+		exportColumns(ExportTypeFloat, "avg_score"),
+		exportColumns(ExportTypeInt, "lives_lost", "lives_taken", "final_position")),
+	exportStatisticsTable("statistics_scam", "Statistics for Scam legs",
+		exportColumns(ExportTypeInt, "darts_thrown_stopper", "darts_thrown_scorer"),
+		exportColumns(ExportTypeFloat, "mpr", "ppd"),
+		exportColumns(ExportTypeInt, "score")),
+	exportStatisticsTable("statistics_accuracy", "Accuracy statistics for X01, Around the Clock, Bermuda Triangle and JDC Practice legs",
+		exportColumns(ExportTypeInt, "darts", "hits", "segment_hits", "misses", "clockwise", "anticlockwise", "angular_offset",
+			"radial_inside", "radial_outside")),
+}
+
+// GetExportTable will return the export table with the given name, or nil if it does not exist
+func GetExportTable(name string) *ExportTable {
+	for _, table := range ExportTables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+// NewScanDestinations will return a slice of pointers which can be used to scan a row of the table
+func (table *ExportTable) NewScanDestinations() []interface{} {
+	values := make([]interface{}, len(table.Columns))
+	for i, col := range table.Columns {
+		switch col.Type {
+		case ExportTypeInt:
+			values[i] = new(null.Int)
+		case ExportTypeFloat:
+			values[i] = new(null.Float)
+		case ExportTypeBool:
+			values[i] = new(null.Bool)
+		case ExportTypeDatetime:
+			values[i] = new(null.Time)
+		default:
+			values[i] = new(null.String)
+		}
+	}
+	return values
+}
+
+// ExportWriter interface used for writing rows of an export table in a given format
+type ExportWriter interface {
+	Write(values []interface{}) error
+	Flush() error
+}
+
+// NewExportWriter will return a new writer for the given format and table
+func NewExportWriter(w io.Writer, format string, table *ExportTable) (ExportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		writer := &csvExportWriter{writer: csv.NewWriter(w)}
+		header := make([]string, len(table.Columns))
+		for i, col := range table.Columns {
+			header[i] = col.Name
+		}
+		err := writer.writer.Write(header)
+		if err != nil {
+			return nil, err
+		}
+		return writer, nil
+	case ExportFormatJSONL:
+		return &jsonlExportWriter{encoder: json.NewEncoder(w), table: table}, nil
+	}
+	return nil, errors.New("unsupported export format: " + format)
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+// Write will write the given values as a single CSV record. Null values are written as empty fields
+func (w *csvExportWriter) Write(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case *null.Int:
+			if v.Valid {
+				record[i] = strconv.FormatInt(v.Int64, 10)
+			}
+		case *null.Float:
+			if v.Valid {
+				record[i] = strconv.FormatFloat(v.Float64, 'f', -1, 64)
+			}
+		case *null.Bool:
+			if v.Valid {
+				record[i] = strconv.FormatBool(v.Bool)
+			}
+		case *null.Time:
+			if v.Valid {
+				record[i] = v.Time.Format(time.RFC3339)
+			}
+		case *null.String:
+			record[i] = v.String
+		}
+	}
+	return w.writer.Write(record)
+}
+
+// Flush will flush any buffered records
+func (w *csvExportWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+	table   *ExportTable
+}
+
+// Write will write the given values as a single JSON object on its own line
+func (w *jsonlExportWriter) Write(values []interface{}) error {
+	row := make(map[string]interface{})
+	for i, col := range w.table.Columns {
+		row[col.Name] = values[i]
+	}
+	return w.encoder.Encode(row)
+}
+
+// Flush is a no-op, since rows are written as they are encoded
+func (w *jsonlExportWriter) Flush() error {
+	return nil
+}
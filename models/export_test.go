@@ -0,0 +1,38 @@
+package models
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExportWriterCSV will check that a header is written, and null values are written as empty fields
+func TestExportWriterCSV(t *testing.T) {
+	table := &ExportTable{Name: "test", Columns: []*ExportColumn{
+		{Name: "id", Type: ExportTypeInt}, {Name: "ppd", Type: ExportTypeFloat}, {Name: "is_bust", Type: ExportTypeBool}}}
+	buf := new(bytes.Buffer)
+	writer, err := NewExportWriter(buf, ExportFormatCSV, table)
+	assert.Nil(t, err)
+	ppd := null.FloatFrom(20.5)
+	isBust := null.BoolFrom(true)
+	assert.Nil(t, writer.Write([]interface{}{&null.Int{}, &ppd, &isBust}))
+	assert.Nil(t, writer.Flush())
+	assert.Equal(t, "id,ppd,is_bust\n,20.5,true\n", buf.String())
+}
+
+// TestExportWriterJSONL will check that each row is written as a JSON object on its own line
+func TestExportWriterJSONL(t *testing.T) {
+	table := GetExportTable("elo_changelog")
+	buf := new(bytes.Buffer)
+	writer, err := NewExportWriter(buf, ExportFormatJSONL, table)
+	assert.Nil(t, err)
+	values := table.NewScanDestinations()
+	*values[0].(*null.Int) = null.IntFrom(1)
+	assert.Nil(t, writer.Write(values))
+	assert.Equal(t, `{"created_at":null,"id":1,"match_id":null,"new_elo":null,"new_tournament_elo":null,"old_elo":null,"old_tournament_elo":null,"player_id":null}`+"\n", buf.String())
+
+	_, err = NewExportWriter(buf, "xml", table)
+	assert.NotNil(t, err)
+}
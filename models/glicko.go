@@ -0,0 +1,199 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Rating systems which can be used to calculate probabilities
+const (
+	RatingSystemElo    = "elo"
+	RatingSystemGlicko = "glicko"
+)
+
+// Initial values and system constant for Glicko-2 ratings
+const (
+	GlickoDefaultRating     = 1500.0
+	GlickoDefaultDeviation  = 350.0
+	GlickoDefaultVolatility = 0.06
+	// GlickoTau constrains the change in volatility over time
+	GlickoTau = 0.5
+	// GlickoRatingPeriodDays is the length of a rating period, used to increase the deviation of inactive players
+	GlickoRatingPeriodDays = 30
+
+	glickoScale     = 173.7178
+	glickoTolerance = 0.000001
+)
+
+// PlayerGlicko struct used for storing the Glicko-2 rating of a player
+type PlayerGlicko struct {
+	PlayerID      int       `json:"player_id"`
+	Rating        float64   `json:"rating"`
+	Deviation     float64   `json:"deviation"`
+	Volatility    float64   `json:"volatility"`
+	Matches       int       `json:"matches"`
+	LastMatchAt   null.Time `json:"last_match_at"`
+	RatingNew     float64   `json:"rating_new,omitempty"`
+	DeviationNew  float64   `json:"deviation_new,omitempty"`
+	VolatilityNew float64   `json:"volatility_new,omitempty"`
+}
+
+// PlayerGlickoChangelogs struct used for storing Glicko-2 changelog information
+type PlayerGlickoChangelogs struct {
+	Total     int                      `json:"total"`
+	Changelog []*PlayerGlickoChangelog `json:"changelog"`
+}
+
+// PlayerGlickoChangelog struct used for storing the Glicko-2 change of a player in a single match
+type PlayerGlickoChangelog struct {
+	ID         int           `json:"id"`
+	MatchID    int           `json:"match_id"`
+	FinishedAt string        `json:"finished_at"`
+	MatchMode  string        `json:"match_mode"`
+	MatchType  string        `json:"match_type"`
+	IsOfficial bool          `json:"is_official"`
+	WinnerID   null.Int      `json:"winner_id"`
+	Player     *PlayerGlicko `json:"player"`
+	Opponents  []int         `json:"opponents"`
+}
+
+// GlickoResult struct used for storing the result of a game against a single opponent, where score is 1 for a win, 0.5 for a draw and 0 for a loss
+type GlickoResult struct {
+	OpponentRating    float64
+	OpponentDeviation float64
+	Score             float64
+}
+
+// NewPlayerGlicko will return the initial rating for the given player
+func NewPlayerGlicko(playerID int) *PlayerGlicko {
+	return &PlayerGlicko{PlayerID: playerID, Rating: GlickoDefaultRating, Deviation: GlickoDefaultDeviation, Volatility: GlickoDefaultVolatility}
+}
+
+// IsValidRatingSystem will check if the given rating system is supported
+func IsValidRatingSystem(system string) bool {
+	return system == RatingSystemElo || system == RatingSystemGlicko
+}
+
+// GetGlickoResults will decompose a match between any number of players into pairwise results.
+// Players are ranked by their score, so each player beats every player with a lower score, and draws with players on the same score
+func GetGlickoResults(scores map[int]int, ratings map[int]*PlayerGlicko) map[int][]*GlickoResult {
+	players := make([]int, 0)
+	for playerID := range scores {
+		players = append(players, playerID)
+	}
+	sort.Ints(players)
+
+	results := make(map[int][]*GlickoResult)
+	for _, player := range players {
+		for _, opponent := range players {
+			if player == opponent {
+				continue
+			}
+			score := 0.5
+			if scores[player] > scores[opponent] {
+				score = 1
+			} else if scores[player] < scores[opponent] {
+				score = 0
+			}
+			results[player] = append(results[player], &GlickoResult{OpponentRating: ratings[opponent].Rating,
+				OpponentDeviation: ratings[opponent].Deviation, Score: score})
+		}
+	}
+	return results
+}
+
+// CalculateGlicko will calculate the new rating, deviation and volatility of the given player after the given results, treated
+// as a single rating period. If there are no results, only the deviation is increased
+func CalculateGlicko(player *PlayerGlicko, results []*GlickoResult) (float64, float64, float64) {
+	mu := (player.Rating - GlickoDefaultRating) / glickoScale
+	phi := player.Deviation / glickoScale
+	sigma := player.Volatility
+	if len(results) == 0 {
+		return player.Rating, math.Sqrt(phi*phi+sigma*sigma) * glickoScale, sigma
+	}
+
+	variance := 0.0
+	improvement := 0.0
+	for _, result := range results {
+		muJ := (result.OpponentRating - GlickoDefaultRating) / glickoScale
+		g := glickoG(result.OpponentDeviation / glickoScale)
+		e := glickoE(mu, muJ, g)
+		variance += g * g * e * (1 - e)
+		improvement += g * (result.Score - e)
+	}
+	v := 1 / variance
+	delta := v * improvement
+
+	// Find the new volatility using the Illinois algorithm
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta*delta-phi*phi-v-ex)/(2*math.Pow(phi*phi+v+ex, 2)) - (x-a)/(GlickoTau*GlickoTau)
+	}
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*GlickoTau) < 0 {
+			k++
+		}
+		B = a - k*GlickoTau
+	}
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glickoTolerance {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA = fA / 2
+		}
+		B, fB = C, fC
+	}
+	sigmaNew := math.Exp(A / 2)
+
+	phiStar := math.Sqrt(phi*phi + sigmaNew*sigmaNew)
+	phiNew := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	muNew := mu + phiNew*phiNew*improvement
+	return muNew*glickoScale + GlickoDefaultRating, phiNew * glickoScale, sigmaNew
+}
+
+// GetGlickoRatingPeriods will return the number of whole rating periods between the given times
+func GetGlickoRatingPeriods(from time.Time, to time.Time) int {
+	if to.Before(from) {
+		return 0
+	}
+	return int(to.Sub(from).Hours() / 24 / GlickoRatingPeriodDays)
+}
+
+// InflateDeviation will increase the deviation of the player for the given number of rating periods without any matches,
+// limited by the deviation of a new player
+func (player *PlayerGlicko) InflateDeviation(periods int) {
+	if periods <= 0 {
+		return
+	}
+	phi := player.Deviation / glickoScale
+	phi = math.Sqrt(phi*phi + player.Volatility*player.Volatility*float64(periods))
+	player.Deviation = math.Min(phi*glickoScale, GlickoDefaultDeviation)
+}
+
+// GetGlickoWinProbability will return the expected score of player1 against player2, taking the uncertainty of both ratings into account
+func GetGlickoWinProbability(player1 *PlayerGlicko, player2 *PlayerGlicko) float64 {
+	phi1 := player1.Deviation / glickoScale
+	phi2 := player2.Deviation / glickoScale
+	g := glickoG(math.Sqrt(phi1*phi1 + phi2*phi2))
+	return glickoE((player1.Rating-GlickoDefaultRating)/glickoScale, (player2.Rating-GlickoDefaultRating)/glickoScale, g)
+}
+
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func glickoE(mu float64, muJ float64, g float64) float64 {
+	return 1 / (1 + math.Exp(-g*(mu-muJ)))
+}
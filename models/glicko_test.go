@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculateGlicko will check the calculation against the example from the Glicko-2 paper
+func TestCalculateGlicko(t *testing.T) {
+	player := &PlayerGlicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	rating, deviation, volatility := CalculateGlicko(player, []*GlickoResult{
+		{OpponentRating: 1400, OpponentDeviation: 30, Score: 1},
+		{OpponentRating: 1550, OpponentDeviation: 100, Score: 0},
+		{OpponentRating: 1700, OpponentDeviation: 300, Score: 0},
+	})
+	assert.InDelta(t, 1464.06, rating, 0.01)
+	assert.InDelta(t, 151.52, deviation, 0.01)
+	assert.InDelta(t, 0.05999, volatility, 0.00001)
+
+	// Deviation increases when no games are played
+	_, deviation, _ = CalculateGlicko(player, nil)
+	assert.Greater(t, deviation, 200.0)
+}
+
+// TestGetGlickoResults will check that multi-player matches are decomposed into pairwise results
+func TestGetGlickoResults(t *testing.T) {
+	ratings := map[int]*PlayerGlicko{1: NewPlayerGlicko(1), 2: NewPlayerGlicko(2), 3: NewPlayerGlicko(3)}
+	results := GetGlickoResults(map[int]int{1: 3, 2: 1, 3: 1}, ratings)
+	assert.Len(t, results[1], 2)
+	assert.Equal(t, 1.0, results[1][0].Score)
+	assert.Equal(t, 1.0, results[1][1].Score)
+	assert.Equal(t, 0.0, results[2][0].Score)
+	assert.Equal(t, 0.5, results[2][1].Score)
+	assert.InDelta(t, 0.5, GetGlickoWinProbability(ratings[1], ratings[2]), 0.0001)
+}
+
+// TestInflateDeviation will check that the deviation grows with the rating periods a player has been inactive, up to the initial deviation
+func TestInflateDeviation(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0, GetGlickoRatingPeriods(from, from.AddDate(0, 0, GlickoRatingPeriodDays-1)))
+	assert.Equal(t, 12, GetGlickoRatingPeriods(from, from.AddDate(0, 0, 12*GlickoRatingPeriodDays)))
+	assert.Equal(t, 0, GetGlickoRatingPeriods(from, from.AddDate(0, 0, -60)))
+
+	player := &PlayerGlicko{Rating: 1500, Deviation: 50, Volatility: 0.06}
+	player.InflateDeviation(0)
+	assert.Equal(t, 50.0, player.Deviation)
+	player.InflateDeviation(12)
+	assert.InDelta(t, 61.67, player.Deviation, 0.01)
+
+	player.Deviation = 300
+	player.InflateDeviation(400)
+	assert.Equal(t, GlickoDefaultDeviation, player.Deviation)
+}
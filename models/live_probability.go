@@ -0,0 +1,293 @@
+package models
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/guregu/null"
+)
+
+// LiveProbabilityIterations is the number of times the remainder of a match is simulated to calculate live win probabilities
+const LiveProbabilityIterations = 1000
+
+// LiveProbabilityCacheSize is the number of calculated live probabilities kept in memory
+const LiveProbabilityCacheSize = 10000
+
+// LiveCheckoutRanges are the upper bounds of the remaining score ranges used to group checkout attempts
+var LiveCheckoutRanges = []int{40, 100, 170}
+
+// liveCheckoutDefaults are the checkout rates for each range in LiveCheckoutRanges, used for players with few attempts
+var liveCheckoutDefaults = []float64{0.25, 0.1, 0.02}
+
+const (
+	// liveCheckoutPrior is the number of attempts at the default checkout rate added to the attempts of each player
+	liveCheckoutPrior = 10.0
+	// liveMaxVisits is the number of visits after which a simulated leg is given to the player with the lowest remaining score
+	liveMaxVisits = 500
+)
+
+// LivePlayerModel struct used for storing the historical scoring and checkout distributions of a player
+type LivePlayerModel struct {
+	PlayerID int
+	// Scores contains the scores of historical visits made while not on a finish
+	Scores []int
+	// CheckoutAttempts and Checkouts contains visits made on a finish and successful checkouts for each range in LiveCheckoutRanges
+	CheckoutAttempts []int
+	Checkouts        []int
+}
+
+// LiveState struct used for storing the current state of a leg and match for which live win probabilities should be calculated
+type LiveState struct {
+	// Players contains the players of the current leg in throwing order
+	Players         []int
+	Scores          map[int]int
+	CurrentPlayerID int
+	// StartingScores contains the starting score of each player in the remaining legs of the match
+	StartingScores map[int]int
+	LegsWon        map[int]int
+	WinsRequired   int
+	LegsRequired   null.Int
+}
+
+// LiveProbability struct used for storing the leg and match win probabilities at a given point in a leg
+type LiveProbability struct {
+	LegID                     int             `json:"leg_id"`
+	VisitID                   int             `json:"visit_id,omitempty"`
+	CurrentPlayerID           int             `json:"current_player_id"`
+	Scores                    map[int]int     `json:"scores"`
+	LegsWon                   map[int]int     `json:"legs_won"`
+	LegWinningProbabilities   map[int]float64 `json:"leg_winning_probabilities"`
+	MatchWinningProbabilities map[int]float64 `json:"match_winning_probabilities"`
+	DrawProbability           float64         `json:"draw_probability"`
+}
+
+// LiveProbabilities struct used for storing the live win probabilities after each visit of a leg
+type LiveProbabilities struct {
+	LegID   int                `json:"leg_id"`
+	MatchID int                `json:"match_id"`
+	Current *LiveProbability   `json:"current"`
+	Visits  []*LiveProbability `json:"visits"`
+}
+
+// LiveProbabilityCache struct used for storing calculated live probabilities by leg and number of visits, so polling the same
+// leg state does not run the simulation again. The cache is cleared when it holds more than the given number of probabilities
+type LiveProbabilityCache struct {
+	mutex         sync.Mutex
+	size          int
+	probabilities map[[2]int]*LiveProbability
+}
+
+// NewLiveProbabilityCache will return an empty cache holding at most the given number of probabilities
+func NewLiveProbabilityCache(size int) *LiveProbabilityCache {
+	return &LiveProbabilityCache{size: size, probabilities: make(map[[2]int]*LiveProbability)}
+}
+
+// Get will return the cached probability after the given number of visits of the given leg, if it is still for the given state
+func (cache *LiveProbabilityCache) Get(legID int, visits int, state *LiveState) (*LiveProbability, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	probability, ok := cache.probabilities[[2]int{legID, visits}]
+	if !ok || !probability.isState(state) {
+		return nil, false
+	}
+	return probability, true
+}
+
+// Set will store the probability after the given number of visits of the given leg
+func (cache *LiveProbabilityCache) Set(legID int, visits int, probability *LiveProbability) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if len(cache.probabilities) >= cache.size {
+		cache.probabilities = make(map[[2]int]*LiveProbability)
+	}
+	cache.probabilities[[2]int{legID, visits}] = probability
+}
+
+// GetLiveProbabilitySeed will return the seed used to simulate the given leg after the given number of visits, so the same
+// leg state always gives the same probabilities
+func GetLiveProbabilitySeed(legID int, visits int) int64 {
+	return int64(legID)<<20 + int64(visits)
+}
+
+// isState will check if the probability was calculated for the given state, as visits can be edited after they are thrown
+func (probability *LiveProbability) isState(state *LiveState) bool {
+	if probability.CurrentPlayerID != state.CurrentPlayerID || len(probability.Scores) != len(state.Players) {
+		return false
+	}
+	for _, playerID := range state.Players {
+		if probability.Scores[playerID] != state.Scores[playerID] || probability.LegsWon[playerID] != state.LegsWon[playerID] {
+			return false
+		}
+	}
+	return true
+}
+
+// NewLivePlayerModel will return an empty model for the given player
+func NewLivePlayerModel(playerID int) *LivePlayerModel {
+	return &LivePlayerModel{PlayerID: playerID, Scores: make([]int, 0), CheckoutAttempts: make([]int, len(LiveCheckoutRanges)),
+		Checkouts: make([]int, len(LiveCheckoutRanges))}
+}
+
+// AddVisit will add a historical visit made with the given remaining score to the model
+func (model *LivePlayerModel) AddVisit(remaining int, score int, isBust bool) {
+	if IsOnFinish(remaining) {
+		idx := getLiveCheckoutRange(remaining)
+		model.CheckoutAttempts[idx]++
+		if !isBust && score == remaining {
+			model.Checkouts[idx]++
+		}
+		return
+	}
+	if isBust {
+		score = 0
+	}
+	model.Scores = append(model.Scores, score)
+}
+
+// CheckoutRate will return the probability of the player checking out the given remaining score in a single visit
+func (model *LivePlayerModel) CheckoutRate(remaining int) float64 {
+	if !IsOnFinish(remaining) {
+		return 0
+	}
+	idx := getLiveCheckoutRange(remaining)
+	return (float64(model.Checkouts[idx]) + liveCheckoutPrior*liveCheckoutDefaults[idx]) / (float64(model.CheckoutAttempts[idx]) + liveCheckoutPrior)
+}
+
+// sampleScore will return the score of a random historical visit, or a random score between 20 and 80 if there are none
+func (model *LivePlayerModel) sampleScore(rng *rand.Rand) int {
+	if len(model.Scores) == 0 {
+		return 20 + rng.Intn(61)
+	}
+	return model.Scores[rng.Intn(len(model.Scores))]
+}
+
+// CalculateLiveProbability will simulate the remainder of the current leg and match the given number of times, and return the
+// probability of each player winning the leg and the match
+func CalculateLiveProbability(state *LiveState, players map[int]*LivePlayerModel, iterations int, rng *rand.Rand) *LiveProbability {
+	probability := &LiveProbability{CurrentPlayerID: state.CurrentPlayerID, Scores: make(map[int]int), LegsWon: make(map[int]int),
+		LegWinningProbabilities: make(map[int]float64), MatchWinningProbabilities: make(map[int]float64)}
+	for _, playerID := range state.Players {
+		probability.Scores[playerID] = state.Scores[playerID]
+		probability.LegsWon[playerID] = state.LegsWon[playerID]
+		probability.LegWinningProbabilities[playerID] = 0
+		probability.MatchWinningProbabilities[playerID] = 0
+	}
+	if iterations <= 0 || len(state.Players) == 0 {
+		return probability
+	}
+
+	start := 0
+	for i, playerID := range state.Players {
+		if playerID == state.CurrentPlayerID {
+			start = i
+		}
+	}
+	draws := 0
+	for i := 0; i < iterations; i++ {
+		scores := make(map[int]int)
+		for playerID, score := range state.Scores {
+			scores[playerID] = score
+		}
+		legs := make(map[int]int)
+		for playerID, won := range state.LegsWon {
+			legs[playerID] = won
+		}
+		winner := simulateLiveLeg(state.Players, scores, start, players, rng)
+		probability.LegWinningProbabilities[winner]++
+		legs[winner]++
+
+		order := state.Players
+		for !isLiveMatchFinished(state, legs) {
+			// Players are shifted for each new leg, so the next player starts
+			order = append(append([]int{}, order[1:]...), order[0])
+			for playerID, score := range state.StartingScores {
+				scores[playerID] = score
+			}
+			legs[simulateLiveLeg(order, scores, 0, players, rng)]++
+		}
+		matchWinner := getLiveMatchWinner(state.Players, legs)
+		if matchWinner == 0 {
+			draws++
+		} else {
+			probability.MatchWinningProbabilities[matchWinner]++
+		}
+	}
+	for _, playerID := range state.Players {
+		probability.LegWinningProbabilities[playerID] /= float64(iterations)
+		probability.MatchWinningProbabilities[playerID] /= float64(iterations)
+	}
+	probability.DrawProbability = float64(draws) / float64(iterations)
+	return probability
+}
+
+// simulateLiveLeg will play out the given leg from the given scores, starting with the player at the given index, and return the winner
+func simulateLiveLeg(order []int, scores map[int]int, start int, players map[int]*LivePlayerModel, rng *rand.Rand) int {
+	for _, playerID := range order {
+		if scores[playerID] == 0 {
+			return playerID
+		}
+	}
+	idx := start
+	for visit := 0; visit < liveMaxVisits; visit++ {
+		playerID := order[idx]
+		remaining := scores[playerID]
+		model, ok := players[playerID]
+		if !ok {
+			model = NewLivePlayerModel(playerID)
+		}
+		if rng.Float64() < model.CheckoutRate(remaining) {
+			return playerID
+		}
+		score := model.sampleScore(rng)
+		if remaining-score >= 2 {
+			scores[playerID] = remaining - score
+		}
+		idx = (idx + 1) % len(order)
+	}
+	winner := order[0]
+	for _, playerID := range order {
+		if scores[playerID] < scores[winner] {
+			winner = playerID
+		}
+	}
+	return winner
+}
+
+// isLiveMatchFinished will check if a match is finished with the given number of legs won by each player
+func isLiveMatchFinished(state *LiveState, legs map[int]int) bool {
+	if state.WinsRequired <= 0 && !state.LegsRequired.Valid {
+		return true
+	}
+	played := 0
+	for _, won := range legs {
+		played += won
+		if state.WinsRequired > 0 && won >= state.WinsRequired {
+			return true
+		}
+	}
+	return state.LegsRequired.Valid && played >= int(state.LegsRequired.Int64)
+}
+
+// getLiveMatchWinner will return the player who has won the most legs, or 0 if the match is a draw
+func getLiveMatchWinner(players []int, legs map[int]int) int {
+	winner := 0
+	most := -1
+	for _, playerID := range players {
+		if legs[playerID] > most {
+			winner = playerID
+			most = legs[playerID]
+		} else if legs[playerID] == most {
+			winner = 0
+		}
+	}
+	return winner
+}
+
+func getLiveCheckoutRange(remaining int) int {
+	for i, max := range LiveCheckoutRanges {
+		if remaining <= max {
+			return i
+		}
+	}
+	return len(LiveCheckoutRanges) - 1
+}
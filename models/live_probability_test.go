@@ -0,0 +1,69 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLivePlayerModel will check that visits on a finish are counted as checkout attempts, and other visits as scoring
+func TestLivePlayerModel(t *testing.T) {
+	model := NewLivePlayerModel(1)
+	model.AddVisit(501, 60, false)
+	model.AddVisit(200, 100, true)
+	model.AddVisit(40, 20, false)
+	model.AddVisit(20, 20, false)
+	assert.Equal(t, []int{60, 0}, model.Scores)
+	assert.Equal(t, 2, model.CheckoutAttempts[0])
+	assert.Equal(t, 1, model.Checkouts[0])
+	assert.InDelta(t, 3.5/12, model.CheckoutRate(32), 0.0001)
+	assert.Equal(t, 0.0, model.CheckoutRate(169))
+}
+
+// TestCalculateLiveProbability will check that the player closest to finishing is favoured, and that a won match is certain
+func TestCalculateLiveProbability(t *testing.T) {
+	players := map[int]*LivePlayerModel{1: NewLivePlayerModel(1), 2: NewLivePlayerModel(2)}
+	state := &LiveState{Players: []int{1, 2}, Scores: map[int]int{1: 40, 2: 501}, CurrentPlayerID: 1,
+		StartingScores: map[int]int{1: 501, 2: 501}, LegsWon: map[int]int{1: 1, 2: 1}, WinsRequired: 2}
+	probability := CalculateLiveProbability(state, players, 500, rand.New(rand.NewSource(1)))
+	assert.Greater(t, probability.LegWinningProbabilities[1], 0.9)
+	assert.Equal(t, probability.LegWinningProbabilities[1], probability.MatchWinningProbabilities[1])
+
+	state.Scores = map[int]int{1: 0, 2: 501}
+	state.LegsWon = map[int]int{1: 0, 2: 0}
+	state.WinsRequired = 0
+	state.LegsRequired = null.IntFrom(2)
+	probability = CalculateLiveProbability(state, players, 500, rand.New(rand.NewSource(1)))
+	assert.Equal(t, 1.0, probability.LegWinningProbabilities[1])
+	assert.Equal(t, 0.0, probability.MatchWinningProbabilities[2])
+	assert.InDelta(t, 1.0, probability.MatchWinningProbabilities[1]+probability.DrawProbability, 0.0001)
+}
+
+// TestLiveProbabilityCache will check that the same leg state gives the same probabilities, and that edited visits are not cached
+func TestLiveProbabilityCache(t *testing.T) {
+	players := map[int]*LivePlayerModel{1: NewLivePlayerModel(1), 2: NewLivePlayerModel(2)}
+	state := &LiveState{Players: []int{1, 2}, Scores: map[int]int{1: 301, 2: 281}, CurrentPlayerID: 1,
+		StartingScores: map[int]int{1: 501, 2: 501}, LegsWon: map[int]int{1: 0, 2: 0}, WinsRequired: 1}
+	seed := GetLiveProbabilitySeed(1, 4)
+	probability := CalculateLiveProbability(state, players, 200, rand.New(rand.NewSource(seed)))
+	assert.Equal(t, probability, CalculateLiveProbability(state, players, 200, rand.New(rand.NewSource(seed))))
+
+	cache := NewLiveProbabilityCache(1)
+	cache.Set(1, 4, probability)
+	cached, ok := cache.Get(1, 4, state)
+	assert.True(t, ok)
+	assert.Equal(t, probability, cached)
+	_, ok = cache.Get(1, 5, state)
+	assert.False(t, ok)
+
+	state.Scores[2] = 321
+	_, ok = cache.Get(1, 4, state)
+	assert.False(t, ok)
+
+	cache.Set(2, 0, probability)
+	_, ok = cache.Get(1, 4, &LiveState{Players: []int{1, 2}, Scores: map[int]int{1: 301, 2: 281}, CurrentPlayerID: 1,
+		LegsWon: map[int]int{1: 0, 2: 0}})
+	assert.False(t, ok)
+}
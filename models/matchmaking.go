@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Weights of each part of the matchmaking score, which is between 0 and 1 where a higher score is a better suggestion
+const (
+	MatchmakingWeightBalance     = 0.6
+	MatchmakingWeightRecency     = 0.3
+	MatchmakingWeightNeverPlayed = 0.1
+	// MatchmakingRecencyDays is the number of days after which a previous meeting no longer lowers the score
+	MatchmakingRecencyDays = 90
+	// MatchmakingHandicapPerElo is the number of handicap points suggested per Elo point of difference
+	MatchmakingHandicapPerElo = 0.25
+)
+
+// matchmakingBotSkills are the bot skill levels suggested for players with an Elo below each threshold
+var matchmakingBotSkills = []struct {
+	elo   int
+	skill int
+}{{1300, BOT_VERYEASY}, {1400, BOT_EASY}, {1500, BOT_MEDIUM}, {1600, BOT_CHALLENGING}, {1700, BOT_HARD}}
+
+// MatchmakingInput struct used for requesting matchmaking suggestions
+type MatchmakingInput struct {
+	// PlayerID is set to get a ranked list of opponents for the given player
+	PlayerID null.Int `json:"player_id"`
+	// Players contains the present players. If empty, players recently at VenueID are used
+	Players     []int    `json:"players"`
+	VenueID     null.Int `json:"venue_id"`
+	MatchTypeID int      `json:"match_type_id"`
+}
+
+// MatchmakingCandidate struct used for storing a suggested pairing of two players
+type MatchmakingCandidate struct {
+	Players        []int       `json:"players"`
+	Ratings        map[int]int `json:"ratings"`
+	WinProbability float64     `json:"win_probability"`
+	LastMet        null.Time   `json:"last_met"`
+	NeverPlayed    bool        `json:"never_played"`
+	Score          float64     `json:"score"`
+}
+
+// MatchmakingFill struct used for storing suggestions for a player left over when there is an odd number of players
+type MatchmakingFill struct {
+	PlayerID int `json:"player_id"`
+	BotSkill int `json:"bot_skill"`
+	// HandicapOpponentID is the best suited opponent for a handicap match, where HandicapPlayerID gets Handicap added to their starting score
+	HandicapOpponentID int `json:"handicap_opponent_id"`
+	HandicapPlayerID   int `json:"handicap_player_id"`
+	Handicap           int `json:"handicap"`
+}
+
+// Matchmaking struct used for storing matchmaking suggestions
+type Matchmaking struct {
+	MatchTypeID int                     `json:"match_type_id"`
+	Players     []int                   `json:"players"`
+	Opponents   []*MatchmakingCandidate `json:"opponents,omitempty"`
+	Pairings    []*MatchmakingCandidate `json:"pairings"`
+	Fill        *MatchmakingFill        `json:"fill,omitempty"`
+}
+
+// Validate will validate the input
+func (input MatchmakingInput) Validate() error {
+	if len(input.Players) == 0 && !input.VenueID.Valid {
+		return errors.New("players or venue_id must be specified")
+	}
+	if input.PlayerID.Valid && len(input.Players) > 0 && !containsInt(input.Players, int(input.PlayerID.Int64)) {
+		return errors.New("player_id must be one of the given players")
+	}
+	return nil
+}
+
+// SetScore will calculate the score of the candidate, based on rating closeness, how recently the players last met and
+// if they have never played each other
+func (candidate *MatchmakingCandidate) SetScore(now time.Time) {
+	balance := 1 - math.Abs(candidate.WinProbability-0.5)*2
+	recency := 1.0
+	neverPlayed := 0.0
+	if candidate.LastMet.Valid {
+		days := now.Sub(candidate.LastMet.Time).Hours() / 24
+		recency = math.Max(0, math.Min(days, MatchmakingRecencyDays)) / MatchmakingRecencyDays
+	}
+	candidate.NeverPlayed = !candidate.LastMet.Valid
+	if candidate.NeverPlayed {
+		neverPlayed = 1
+	}
+	candidate.Score = MatchmakingWeightBalance*balance + MatchmakingWeightRecency*recency + MatchmakingWeightNeverPlayed*neverPlayed
+}
+
+// GetMatchmakingPairings will pair up the given players by picking the candidates with the highest score first.
+// Returns the pairings, and the players who could not be paired
+func GetMatchmakingPairings(players []int, candidates []*MatchmakingCandidate) ([]*MatchmakingCandidate, []int) {
+	sorted := SortMatchmakingCandidates(candidates)
+	paired := make(map[int]bool)
+	pairings := make([]*MatchmakingCandidate, 0)
+	for _, candidate := range sorted {
+		if paired[candidate.Players[0]] || paired[candidate.Players[1]] {
+			continue
+		}
+		paired[candidate.Players[0]] = true
+		paired[candidate.Players[1]] = true
+		pairings = append(pairings, candidate)
+	}
+	unpaired := make([]int, 0)
+	for _, playerID := range players {
+		if !paired[playerID] {
+			unpaired = append(unpaired, playerID)
+		}
+	}
+	return pairings, unpaired
+}
+
+// SortMatchmakingCandidates will return the given candidates ordered by score, highest first
+func SortMatchmakingCandidates(candidates []*MatchmakingCandidate) []*MatchmakingCandidate {
+	sorted := append([]*MatchmakingCandidate{}, candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+	return sorted
+}
+
+// GetMatchmakingFill will suggest a bot skill level and a handicap match for the given player, using the best scored candidate
+// containing the player as the handicap opponent
+func GetMatchmakingFill(playerID int, elo int, candidates []*MatchmakingCandidate) *MatchmakingFill {
+	fill := &MatchmakingFill{PlayerID: playerID, BotSkill: GetMatchmakingBotSkill(elo)}
+	for _, candidate := range SortMatchmakingCandidates(candidates) {
+		if candidate.Players[0] != playerID && candidate.Players[1] != playerID {
+			continue
+		}
+		opponentID := candidate.Players[0]
+		if opponentID == playerID {
+			opponentID = candidate.Players[1]
+		}
+		diff := candidate.Ratings[playerID] - candidate.Ratings[opponentID]
+		fill.HandicapOpponentID = opponentID
+		fill.HandicapPlayerID = playerID
+		if diff > 0 {
+			fill.HandicapPlayerID = opponentID
+		}
+		// Round to the nearest 5 points
+		fill.Handicap = int(math.Round(math.Abs(float64(diff))*MatchmakingHandicapPerElo/5)) * 5
+		break
+	}
+	return fill
+}
+
+// GetMatchmakingBotSkill will return the bot skill level best suited for a player with the given Elo
+func GetMatchmakingBotSkill(elo int) int {
+	for _, level := range matchmakingBotSkills {
+		if elo < level.elo {
+			return level.skill
+		}
+	}
+	return BOT_MVG
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatchmakingPairings will check that players who just met are not paired again, and that odd players get a fill suggestion
+func TestMatchmakingPairings(t *testing.T) {
+	now := time.Now()
+	ratings := map[int]int{1: 1600, 2: 1590, 3: 1400}
+	candidates := []*MatchmakingCandidate{
+		{Players: []int{1, 2}, Ratings: ratings, WinProbability: 0.51, LastMet: null.TimeFrom(now.AddDate(0, 0, -1))},
+		{Players: []int{1, 3}, Ratings: ratings, WinProbability: 0.76},
+		{Players: []int{2, 3}, Ratings: ratings, WinProbability: 0.75},
+	}
+	for _, candidate := range candidates {
+		candidate.SetScore(now)
+	}
+	assert.False(t, candidates[0].NeverPlayed)
+	assert.True(t, candidates[1].NeverPlayed)
+	assert.Greater(t, candidates[2].Score, candidates[1].Score)
+
+	pairings, unpaired := GetMatchmakingPairings([]int{1, 2, 3}, candidates)
+	assert.Len(t, pairings, 1)
+	assert.Equal(t, []int{2, 3}, pairings[0].Players)
+	assert.Equal(t, []int{1}, unpaired)
+
+	fill := GetMatchmakingFill(1, ratings[1], candidates)
+	assert.Equal(t, BOT_HARD, fill.BotSkill)
+	assert.Equal(t, 3, fill.HandicapOpponentID)
+	assert.Equal(t, 3, fill.HandicapPlayerID)
+	assert.Equal(t, 50, fill.Handicap)
+	assert.Equal(t, BOT_EASY, GetMatchmakingBotSkill(1350))
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// ComparisonIgnoredMetrics are metrics which are not compared between players
+var ComparisonIgnoredMetrics = map[string]bool{
+	"id": true, "leg_id": true, "player_id": true, "office_id": true, "winner_id": true,
+	"matches_played": true, "legs_played": true,
+}
+
+// ComparisonLowerIsBetter are metrics where the lowest value is the best
+var ComparisonLowerIsBetter = map[string]bool{
+	"darts_thrown": true, "darts_thrown_stopper": true, "darts_thrown_scorer": true, "rounds": true,
+	"times_busted": true, "times_reset": true, "lives_lost": true, "final_position": true,
+}
+
+// PlayerRecord struct used for storing match and leg results for a player
+type PlayerRecord struct {
+	MatchesPlayed int `json:"matches_played"`
+	MatchesWon    int `json:"matches_won"`
+	LegsPlayed    int `json:"legs_played"`
+	LegsWon       int `json:"legs_won"`
+}
+
+// PlayerComparison struct used for storing a comparison of multiple players across all match types
+type PlayerComparison struct {
+	Players         []int                         `json:"players"`
+	Statistics      map[int]map[int]interface{}   `json:"statistics"`
+	Winners         map[int]map[string][]int      `json:"winners"`
+	SharedOpponents map[int]map[int]*PlayerRecord `json:"shared_opponents"`
+	Versus          map[int]map[int]*PlayerRecord `json:"versus,omitempty"`
+}
+
+// GetMetricWinners will return the player(s) with the best value for each numeric metric in the given statistics.
+// Players who have not played any legs are not considered, since their empty statistics would win lower is better metrics
+func GetMetricWinners(statistics map[int]interface{}, legsPlayed map[int]int) (map[string][]int, error) {
+	best := make(map[string]float64)
+	winners := make(map[string][]int)
+	for playerID, stats := range statistics {
+		if legsPlayed[playerID] == 0 {
+			continue
+		}
+		b, err := json.Marshal(stats)
+		if err != nil {
+			return nil, err
+		}
+		metrics := make(map[string]interface{})
+		err = json.Unmarshal(b, &metrics)
+		if err != nil {
+			return nil, err
+		}
+		for metric, val := range metrics {
+			value, ok := val.(float64)
+			if !ok || ComparisonIgnoredMetrics[metric] {
+				continue
+			}
+			current, exists := best[metric]
+			isBetter := value > current
+			if ComparisonLowerIsBetter[metric] {
+				isBetter = value < current
+			}
+			if !exists || isBetter {
+				best[metric] = value
+				winners[metric] = []int{playerID}
+			} else if value == current {
+				winners[metric] = append(winners[metric], playerID)
+			}
+		}
+	}
+	return winners, nil
+}
+
+// GetSharedOpponents will return records against opponents which all the given players have played, keyed by opponent
+func GetSharedOpponents(records map[int]map[int]*PlayerRecord, players []int) map[int]map[int]*PlayerRecord {
+	shared := make(map[int]map[int]*PlayerRecord)
+	for opponentID, playerRecords := range records {
+		isShared := true
+		for _, playerID := range players {
+			if _, ok := playerRecords[playerID]; !ok {
+				isShared = false
+				break
+			}
+		}
+		if isShared {
+			shared[opponentID] = playerRecords
+		}
+	}
+	return shared
+}
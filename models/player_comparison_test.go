@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetMetricWinners will check that the best player is picked for each metric
+func TestGetMetricWinners(t *testing.T) {
+	statistics := map[int]interface{}{
+		1: &StatisticsCricket{PlayerID: 1, LegsPlayed: 3, MPR: 2.5, Rounds: 12},
+		2: &StatisticsCricket{PlayerID: 2, LegsPlayed: 5, MPR: 1.5, Rounds: 15},
+	}
+	winners, err := GetMetricWinners(statistics, map[int]int{1: 3, 2: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, winners["mpr"], []int{1})
+	assert.Equal(t, winners["rounds"], []int{1}, "lower should be better for rounds")
+	assert.NotContains(t, winners, "legs_played")
+	assert.NotContains(t, winners, "player_id")
+}
+
+// TestGetMetricWinnersNoLegs will check that players without legs played do not win lower is better metrics
+func TestGetMetricWinnersNoLegs(t *testing.T) {
+	statistics := map[int]interface{}{
+		1: &StatisticsCricket{PlayerID: 1, LegsPlayed: 3, MPR: 2.5, Rounds: 12},
+		2: &StatisticsCricket{PlayerID: 2},
+	}
+	winners, err := GetMetricWinners(statistics, map[int]int{1: 3, 2: 0})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, winners["rounds"])
+	assert.Equal(t, []int{1}, winners["mpr"])
+}
+
+// TestGetSharedOpponents will check that only opponents played by all players are returned
+func TestGetSharedOpponents(t *testing.T) {
+	records := map[int]map[int]*PlayerRecord{
+		3: {1: &PlayerRecord{MatchesPlayed: 1}, 2: &PlayerRecord{MatchesPlayed: 2}},
+		4: {1: &PlayerRecord{MatchesPlayed: 1}},
+	}
+	shared := GetSharedOpponents(records, []int{1, 2})
+	assert.Len(t, shared, 1)
+	assert.Contains(t, shared, 3)
+}
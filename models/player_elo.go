@@ -2,6 +2,9 @@ package models
 
 import "github.com/guregu/null"
 
+// EloDefault is the Elo given to players before their first rated match
+const EloDefault = 1500
+
 // PlayerElo struct used for storing elo information
 type PlayerElo struct {
 	PlayerID             int      `json:"player_id"`
@@ -12,6 +15,7 @@ type PlayerElo struct {
 	TournamentEloMatches int      `json:"tournament_elo_matches"`
 	TournamentEloNew     null.Int `json:"tournament_elo_new,omitempty"`
 	WinProbability       float64  `json:"win_probability,omitempty"`
+	IsProvisional        bool     `json:"is_provisional"`
 }
 
 // PlayerEloChangelogs struct used for storing elo changelog information
@@ -32,3 +36,39 @@ type PlayerEloChangelog struct {
 	HomePlayer *PlayerElo `json:"home_player"`
 	AwayPlayer *PlayerElo `json:"away_player"`
 }
+
+// PlayerMatchTypeElo struct used for storing the Elo of a player for a single match type
+type PlayerMatchTypeElo struct {
+	PlayerID    int    `json:"player_id"`
+	MatchTypeID int    `json:"match_type_id"`
+	MatchType   string `json:"match_type,omitempty"`
+	Elo         int    `json:"elo"`
+	EloNew      int    `json:"elo_new,omitempty"`
+	Matches     int    `json:"matches"`
+}
+
+// PlayerEloBreakdown struct used for storing all ratings of a player
+type PlayerEloBreakdown struct {
+	PlayerID   int                   `json:"player_id"`
+	Elo        *PlayerElo            `json:"elo"`
+	Glicko     *PlayerGlicko         `json:"glicko"`
+	MatchTypes []*PlayerMatchTypeElo `json:"match_types"`
+}
+
+// PlayerMatchTypeEloChangelogs struct used for storing match type Elo changelog information
+type PlayerMatchTypeEloChangelogs struct {
+	Total     int                            `json:"total"`
+	Changelog []*PlayerMatchTypeEloChangelog `json:"changelog"`
+}
+
+// PlayerMatchTypeEloChangelog struct used for storing the match type Elo change of a player in a single match
+type PlayerMatchTypeEloChangelog struct {
+	ID         int                 `json:"id"`
+	MatchID    int                 `json:"match_id"`
+	FinishedAt string              `json:"finished_at"`
+	MatchMode  string              `json:"match_mode"`
+	IsOfficial bool                `json:"is_official"`
+	WinnerID   null.Int            `json:"winner_id"`
+	Player     *PlayerMatchTypeElo `json:"player"`
+	Opponents  []int               `json:"opponents"`
+}
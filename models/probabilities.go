@@ -15,6 +15,8 @@ type Probability struct {
 	WinnerID                   null.Int        `json:"winner_id"`
 	Players                    []int           `json:"players"`
 	Elos                       map[int]int     `json:"player_elo"`
+	RatingSystem               string          `json:"rating_system"`
+	Ratings                    map[int]float64 `json:"player_rating,omitempty"`
 	PlayerWinningProbabilities map[int]float64 `json:"player_winning_probabilities"`
 	PlayerOdds                 map[int]float64 `json:"player_odds"`
 }
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/guregu/null"
+)
+
+// Ratings which can be used by the rating simulator
+const (
+	SimulationRatingCurrent    = "current"
+	SimulationRatingTournament = "tournament"
+)
+
+// EloCalculator is a function returning the new Elo of two players after a match with the given scores
+type EloCalculator func(player1Elo int, player1Matches int, player1Score int, player2Elo int, player2Matches int, player2Score int) (int, int)
+
+// RatingSimulationInput struct used for storing a batch of hypothetical results to simulate
+type RatingSimulationInput struct {
+	Rating  string                    `json:"rating"`
+	Results []*RatingSimulationResult `json:"results"`
+}
+
+// RatingSimulationResult struct used for storing a single hypothetical result, and the Elo change it gives
+type RatingSimulationResult struct {
+	HomePlayerID  int `json:"home_player_id"`
+	AwayPlayerID  int `json:"away_player_id"`
+	HomeScore     int `json:"home_score"`
+	AwayScore     int `json:"away_score"`
+	HomeEloChange int `json:"home_elo_change"`
+	AwayEloChange int `json:"away_elo_change"`
+}
+
+// SimulatedRating struct used for storing the projected rating and ranking of a player
+type SimulatedRating struct {
+	PlayerID   int      `json:"player_id"`
+	Elo        int      `json:"elo"`
+	EloNew     int      `json:"elo_new"`
+	Matches    int      `json:"matches"`
+	MatchesNew int      `json:"matches_new"`
+	Rank       null.Int `json:"rank"`
+	RankNew    null.Int `json:"rank_new"`
+}
+
+// RatingSimulation struct used for storing the result of simulating a batch of results
+type RatingSimulation struct {
+	Rating  string                    `json:"rating"`
+	Results []*RatingSimulationResult `json:"results"`
+	Players []*SimulatedRating        `json:"players"`
+}
+
+// Validate will validate the input
+func (input *RatingSimulationInput) Validate() error {
+	if input.Rating == "" {
+		input.Rating = SimulationRatingTournament
+	}
+	if input.Rating != SimulationRatingCurrent && input.Rating != SimulationRatingTournament {
+		return fmt.Errorf("rating must be '%s' or '%s'", SimulationRatingCurrent, SimulationRatingTournament)
+	}
+	if len(input.Results) == 0 {
+		return errors.New("at least one result must be given")
+	}
+	for i, result := range input.Results {
+		if result.HomePlayerID == 0 || result.AwayPlayerID == 0 || result.HomePlayerID == result.AwayPlayerID {
+			return fmt.Errorf("result %d must have two different players", i+1)
+		}
+		if result.HomeScore < 0 || result.AwayScore < 0 {
+			return fmt.Errorf("result %d cannot have negative scores", i+1)
+		}
+	}
+	return nil
+}
+
+// GetPlayers will return the IDs of all players affected by the simulation, in order of first appearance
+func (input *RatingSimulationInput) GetPlayers() []int {
+	players := make([]int, 0)
+	for _, result := range input.Results {
+		for _, playerID := range []int{result.HomePlayerID, result.AwayPlayerID} {
+			if !containsInt(players, playerID) {
+				players = append(players, playerID)
+			}
+		}
+	}
+	return players
+}
+
+// SimulateRatings will apply the given results in order to the current or tournament Elo of each player
+func SimulateRatings(input *RatingSimulationInput, elos map[int]*PlayerElo, calculate EloCalculator) *RatingSimulation {
+	simulation := &RatingSimulation{Rating: input.Rating, Results: input.Results, Players: make([]*SimulatedRating, 0)}
+	ratings := make(map[int]*SimulatedRating)
+	for _, playerID := range input.GetPlayers() {
+		rating := &SimulatedRating{PlayerID: playerID, Elo: EloDefault}
+		if elo, ok := elos[playerID]; ok {
+			rating.Elo, rating.Matches = elo.CurrentElo, elo.CurrentEloMatches
+			if input.Rating == SimulationRatingTournament {
+				rating.Elo, rating.Matches = int(elo.TournamentElo.ValueOrZero()), elo.TournamentEloMatches
+				if !elo.TournamentElo.Valid {
+					rating.Elo = EloDefault
+				}
+			}
+		}
+		rating.EloNew, rating.MatchesNew = rating.Elo, rating.Matches
+		ratings[playerID] = rating
+		simulation.Players = append(simulation.Players, rating)
+	}
+
+	for _, result := range input.Results {
+		home := ratings[result.HomePlayerID]
+		away := ratings[result.AwayPlayerID]
+		homeElo, awayElo := calculate(home.EloNew, home.MatchesNew, result.HomeScore, away.EloNew, away.MatchesNew, result.AwayScore)
+		result.HomeEloChange, result.AwayEloChange = homeElo-home.EloNew, awayElo-away.EloNew
+		home.EloNew, away.EloNew = homeElo, awayElo
+		home.MatchesNew++
+		away.MatchesNew++
+	}
+	return simulation
+}
+
+// SetRanks will set the rank of each simulated player before and after the simulation, given the ratings of all ranked players.
+// Players not ranked before are ranked after the simulation if they no longer have a provisional rating
+func (simulation *RatingSimulation) SetRanks(leaderboard map[int]int) {
+	projected := make(map[int]int)
+	for playerID, elo := range leaderboard {
+		projected[playerID] = elo
+	}
+	for _, player := range simulation.Players {
+		if _, ok := leaderboard[player.PlayerID]; ok || !EloSettings.IsProvisional(player.MatchesNew) {
+			projected[player.PlayerID] = player.EloNew
+		}
+	}
+	for _, player := range simulation.Players {
+		player.Rank = getSimulatedRank(player.PlayerID, leaderboard)
+		player.RankNew = getSimulatedRank(player.PlayerID, projected)
+	}
+}
+
+// getSimulatedRank will return the rank of the given player, where players with the same rating share the same rank
+func getSimulatedRank(playerID int, ratings map[int]int) null.Int {
+	elo, ok := ratings[playerID]
+	if !ok {
+		return null.Int{}
+	}
+	rank := 1
+	for _, other := range ratings {
+		if other > elo {
+			rank++
+		}
+	}
+	return null.IntFrom(int64(rank))
+}
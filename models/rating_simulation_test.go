@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSimulateRatings will check that results are applied in order, and that rankings are projected
+func TestSimulateRatings(t *testing.T) {
+	calculate := func(player1Elo int, player1Matches int, player1Score int, player2Elo int, player2Matches int, player2Score int) (int, int) {
+		if player1Score > player2Score {
+			return player1Elo + 10, player2Elo - 10
+		}
+		return player1Elo - 10, player2Elo + 10
+	}
+	input := &RatingSimulationInput{Results: []*RatingSimulationResult{
+		{HomePlayerID: 1, AwayPlayerID: 2, HomeScore: 3, AwayScore: 1},
+		{HomePlayerID: 3, AwayPlayerID: 1, HomeScore: 0, AwayScore: 3},
+	}}
+	assert.NoError(t, input.Validate())
+	assert.Equal(t, SimulationRatingTournament, input.Rating)
+
+	elos := map[int]*PlayerElo{
+		1: {PlayerID: 1, TournamentElo: null.IntFrom(1500), TournamentEloMatches: 10},
+		2: {PlayerID: 2, TournamentElo: null.IntFrom(1510), TournamentEloMatches: 10},
+		3: {PlayerID: 3, TournamentElo: null.IntFrom(1400), TournamentEloMatches: 1},
+	}
+	simulation := SimulateRatings(input, elos, calculate)
+	assert.Equal(t, []int{1, 2, 3}, input.GetPlayers())
+	assert.Equal(t, 1520, simulation.Players[0].EloNew)
+	assert.Equal(t, 12, simulation.Players[0].MatchesNew)
+	assert.Equal(t, -10, simulation.Results[1].HomeEloChange)
+
+	simulation.SetRanks(map[int]int{1: 1500, 2: 1510})
+	assert.Equal(t, null.IntFrom(2), simulation.Players[0].Rank)
+	assert.Equal(t, null.IntFrom(1), simulation.Players[0].RankNew)
+	assert.False(t, simulation.Players[2].RankNew.Valid)
+
+	invalid := &RatingSimulationInput{Results: []*RatingSimulationResult{{HomePlayerID: 1, AwayPlayerID: 1}}}
+	assert.Error(t, invalid.Validate())
+}
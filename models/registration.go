@@ -0,0 +1,225 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Registration statuses
+const (
+	RegistrationRegistered = "registered"
+	RegistrationWaitlisted = "waitlisted"
+	RegistrationCheckedIn  = "checked_in"
+	RegistrationWithdrawn  = "withdrawn"
+)
+
+// Rules for handling players withdrawing from a tournament which has started
+const (
+	// WithdrawalWalkover will give all remaining matches of the player to the opponent as walkovers
+	WithdrawalWalkover = "walkover"
+	// WithdrawalAnnul will annul all matches of the player. Remaining matches are abandoned, and played matches no longer count
+	// toward standings or tournament Elo, so no one gets points from them
+	WithdrawalAnnul = "annul"
+	// WithdrawalAuto will annul all matches if the player has played less than half of the matches, otherwise give walkovers
+	WithdrawalAuto = "auto"
+)
+
+// TournamentRegistration struct used for storing the open registration phase of a tournament, before it is generated
+type TournamentRegistration struct {
+	ID           int                 `json:"id"`
+	Name         string              `json:"name"`
+	ShortName    string              `json:"short_name"`
+	OfficeID     int                 `json:"office_id"`
+	PresetID     null.Int            `json:"preset_id"`
+	ManualAdmin  bool                `json:"manual_admin"`
+	Deadline     time.Time           `json:"deadline"`
+	Capacity     int                 `json:"capacity"`
+	TournamentID null.Int            `json:"tournament_id"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Players      []*RegisteredPlayer `json:"players"`
+}
+
+// RegisteredPlayer struct used for storing the registration of a single player
+type RegisteredPlayer struct {
+	PlayerID          int       `json:"player_id"`
+	TournamentGroupID null.Int  `json:"tournament_group_id"`
+	Status            string    `json:"status"`
+	WaitlistPosition  null.Int  `json:"waitlist_position"`
+	RegisteredAt      time.Time `json:"registered_at"`
+	CheckedInAt       null.Time `json:"checked_in_at"`
+	WithdrawnAt       null.Time `json:"withdrawn_at"`
+}
+
+// WithdrawalResult struct used for describing how a withdrawal from a started tournament was handled
+type WithdrawalResult struct {
+	TournamentID int    `json:"tournament_id"`
+	PlayerID     int    `json:"player_id"`
+	Rule         string `json:"rule"`
+	Walkovers    []int  `json:"walkovers"`
+	Annulled     []int  `json:"annulled"`
+}
+
+// Validate will check that the given registration can be opened
+func (registration *TournamentRegistration) Validate() error {
+	if registration.Name == "" {
+		return errors.New("name is required")
+	}
+	if !registration.PresetID.Valid {
+		return errors.New("preset_id is required")
+	}
+	if registration.Deadline.IsZero() {
+		return errors.New("deadline is required")
+	}
+	if registration.Capacity < 0 {
+		return errors.New("capacity can not be negative")
+	}
+	return nil
+}
+
+// IsOpen will check if players can still register
+func (registration *TournamentRegistration) IsOpen(now time.Time) bool {
+	return !registration.TournamentID.Valid && now.Before(registration.Deadline)
+}
+
+// GetPlayer will return the registration for the given player, or nil if not registered
+func (registration *TournamentRegistration) GetPlayer(playerID int) *RegisteredPlayer {
+	for _, player := range registration.Players {
+		if player.PlayerID == playerID {
+			return player
+		}
+	}
+	return nil
+}
+
+// countConfirmed will return the number of players holding a place in the tournament
+func (registration *TournamentRegistration) countConfirmed() int {
+	count := 0
+	for _, player := range registration.Players {
+		if player.Status == RegistrationRegistered || player.Status == RegistrationCheckedIn {
+			count++
+		}
+	}
+	return count
+}
+
+// Register will register the given player, putting the player on the waitlist if the tournament is full
+func (registration *TournamentRegistration) Register(playerID int, groupID null.Int, now time.Time) (*RegisteredPlayer, error) {
+	if !registration.IsOpen(now) {
+		return nil, errors.New("registration is closed")
+	}
+	if player := registration.GetPlayer(playerID); player != nil && player.Status != RegistrationWithdrawn {
+		return nil, fmt.Errorf("player %d is already registered", playerID)
+	}
+	player := registration.GetPlayer(playerID)
+	if player == nil {
+		player = &RegisteredPlayer{PlayerID: playerID}
+		registration.Players = append(registration.Players, player)
+	}
+	player.TournamentGroupID = groupID
+	player.RegisteredAt = now
+	player.WithdrawnAt = null.Time{}
+	player.Status = RegistrationRegistered
+	if registration.Capacity > 0 && registration.countConfirmed() > registration.Capacity {
+		player.Status = RegistrationWaitlisted
+	}
+	registration.UpdateWaitlist()
+	return player, nil
+}
+
+// CheckIn will check in the given player. Only players with a place in the tournament can check in
+func (registration *TournamentRegistration) CheckIn(playerID int, now time.Time) error {
+	if registration.TournamentID.Valid {
+		return errors.New("tournament has already started")
+	}
+	player := registration.GetPlayer(playerID)
+	if player == nil {
+		return fmt.Errorf("player %d is not registered", playerID)
+	}
+	if player.Status != RegistrationRegistered {
+		return fmt.Errorf("player %d can not check in while %s", playerID, player.Status)
+	}
+	player.Status = RegistrationCheckedIn
+	player.CheckedInAt = null.TimeFrom(now)
+	return nil
+}
+
+// Withdraw will withdraw the given player before the tournament starts, and give the place to the first player on the waitlist.
+// The promoted player is returned, if any
+func (registration *TournamentRegistration) Withdraw(playerID int, now time.Time) (*RegisteredPlayer, error) {
+	if registration.TournamentID.Valid {
+		return nil, errors.New("tournament has already started")
+	}
+	player := registration.GetPlayer(playerID)
+	if player == nil || player.Status == RegistrationWithdrawn {
+		return nil, fmt.Errorf("player %d is not registered", playerID)
+	}
+	hadPlace := player.Status != RegistrationWaitlisted
+	player.Status = RegistrationWithdrawn
+	player.WithdrawnAt = null.TimeFrom(now)
+	player.CheckedInAt = null.Time{}
+
+	var promoted *RegisteredPlayer
+	if hadPlace {
+		for _, waiting := range registration.getWaitlist() {
+			waiting.Status = RegistrationRegistered
+			promoted = waiting
+			break
+		}
+	}
+	registration.UpdateWaitlist()
+	return promoted, nil
+}
+
+// GetCheckedInPlayers will return all checked in players, placed in the given group unless they registered for a specific group
+func (registration *TournamentRegistration) GetCheckedInPlayers(defaultGroupID int) []*Player2Tournament {
+	players := make([]*Player2Tournament, 0)
+	for _, player := range registration.Players {
+		if player.Status != RegistrationCheckedIn {
+			continue
+		}
+		groupID := defaultGroupID
+		if player.TournamentGroupID.Valid {
+			groupID = int(player.TournamentGroupID.Int64)
+		}
+		players = append(players, &Player2Tournament{PlayerID: player.PlayerID, TournamentGroupID: groupID})
+	}
+	return players
+}
+
+// getWaitlist will return all waitlisted players, in the order they registered
+func (registration *TournamentRegistration) getWaitlist() []*RegisteredPlayer {
+	waitlist := make([]*RegisteredPlayer, 0)
+	for _, player := range registration.Players {
+		if player.Status == RegistrationWaitlisted {
+			waitlist = append(waitlist, player)
+		}
+	}
+	sort.SliceStable(waitlist, func(i, j int) bool { return waitlist[i].RegisteredAt.Before(waitlist[j].RegisteredAt) })
+	return waitlist
+}
+
+// UpdateWaitlist will set the waitlist position of all waitlisted players
+func (registration *TournamentRegistration) UpdateWaitlist() {
+	for _, player := range registration.Players {
+		player.WaitlistPosition = null.Int{}
+	}
+	for i, player := range registration.getWaitlist() {
+		player.WaitlistPosition = null.IntFrom(int64(i + 1))
+	}
+}
+
+// GetWithdrawalRule will return the rule to apply when a player withdraws after playing the given number of matches
+func GetWithdrawalRule(rule string, played int, total int) string {
+	switch rule {
+	case WithdrawalWalkover, WithdrawalAnnul:
+		return rule
+	}
+	if played*2 < total {
+		return WithdrawalAnnul
+	}
+	return WithdrawalWalkover
+}
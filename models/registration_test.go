@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistrationWaitlist will check that players are waitlisted when the tournament is full, and promoted when a place opens up
+func TestRegistrationWaitlist(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	registration := &TournamentRegistration{Capacity: 2, Deadline: now.Add(time.Hour)}
+	for i := 1; i <= 4; i++ {
+		_, err := registration.Register(i, null.Int{}, now.Add(time.Duration(i)*time.Minute))
+		assert.NoError(t, err)
+	}
+	_, err := registration.Register(1, null.Int{}, now)
+	assert.Error(t, err)
+	assert.Equal(t, RegistrationWaitlisted, registration.GetPlayer(3).Status)
+	assert.Equal(t, null.IntFrom(2), registration.GetPlayer(4).WaitlistPosition)
+
+	assert.Error(t, registration.CheckIn(3, now))
+	assert.NoError(t, registration.CheckIn(1, now))
+
+	promoted, err := registration.Withdraw(1, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, promoted.PlayerID)
+	assert.Equal(t, RegistrationRegistered, promoted.Status)
+	assert.Equal(t, null.IntFrom(1), registration.GetPlayer(4).WaitlistPosition)
+	assert.Len(t, registration.GetCheckedInPlayers(1), 0)
+
+	_, err = registration.Register(5, null.Int{}, now.Add(2*time.Hour))
+	assert.Error(t, err)
+}
+
+// TestGetWithdrawalRule will check that auto annuls remaining matches only when less than half of the matches are played
+func TestGetWithdrawalRule(t *testing.T) {
+	assert.Equal(t, WithdrawalAnnul, GetWithdrawalRule(WithdrawalAuto, 1, 4))
+	assert.Equal(t, WithdrawalWalkover, GetWithdrawalRule(WithdrawalAuto, 2, 4))
+	assert.Equal(t, WithdrawalWalkover, GetWithdrawalRule(WithdrawalWalkover, 0, 4))
+	assert.Equal(t, WithdrawalAnnul, GetWithdrawalRule(WithdrawalAnnul, 4, 4))
+}
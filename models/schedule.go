@@ -0,0 +1,211 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// ScheduleOptions struct used for configuring generation of a tournament schedule
+type ScheduleOptions struct {
+	Venues       []int                 `json:"venues"`
+	StartTime    time.Time             `json:"start_time"`
+	SlotMinutes  int                   `json:"slot_minutes"`
+	RestSlots    int                   `json:"rest_slots"`
+	Availability []*PlayerAvailability `json:"availability,omitempty"`
+}
+
+// PlayerAvailability struct used for storing a window where a player is available to play tournament matches
+type PlayerAvailability struct {
+	PlayerID      int       `json:"player_id"`
+	AvailableFrom time.Time `json:"available_from"`
+	AvailableTo   time.Time `json:"available_to"`
+}
+
+// ScheduleMatch struct used for describing a match which should be scheduled
+type ScheduleMatch struct {
+	MatchID     int   `json:"match_id"`
+	OrderOfPlay int   `json:"order_of_play"`
+	Players     []int `json:"players"`
+	DependsOn   []int `json:"depends_on"`
+}
+
+// ScheduledMatch struct used for storing the venue and time slot a tournament match is scheduled for
+type ScheduledMatch struct {
+	TournamentID     int         `json:"tournament_id"`
+	MatchID          int         `json:"match_id"`
+	VenueID          int         `json:"venue_id"`
+	VenueName        null.String `json:"venue_name,omitempty"`
+	StartTime        time.Time   `json:"start_time"`
+	EndTime          time.Time   `json:"end_time"`
+	IsManual         bool        `json:"is_manual"`
+	IsPlayed         bool        `json:"is_played"`
+	Players          []int       `json:"players,omitempty"`
+	MatchDisplayname null.String `json:"match_displayname,omitempty"`
+	TournamentName   null.String `json:"tournament_name,omitempty"`
+}
+
+// Validate will check that the given options can be used to generate a schedule
+func (options *ScheduleOptions) Validate() error {
+	if len(options.Venues) == 0 {
+		return errors.New("at least one venue is required to generate a schedule")
+	}
+	if options.SlotMinutes <= 0 {
+		return errors.New("slot_minutes must be greater than zero")
+	}
+	if options.RestSlots < 0 {
+		return errors.New("rest_slots can not be negative")
+	}
+	if options.StartTime.IsZero() {
+		return errors.New("start_time is required to generate a schedule")
+	}
+	for _, availability := range options.Availability {
+		if !availability.AvailableTo.After(availability.AvailableFrom) {
+			return fmt.Errorf("availability for player %d must end after it starts", availability.PlayerID)
+		}
+	}
+	return nil
+}
+
+// IsAvailable will check if the given player is available for the whole period. Players without any availability windows are always available
+func IsAvailable(availability []*PlayerAvailability, playerID int, start time.Time, end time.Time) bool {
+	hasWindow := false
+	for _, window := range availability {
+		if window.PlayerID != playerID {
+			continue
+		}
+		hasWindow = true
+		if !start.Before(window.AvailableFrom) && !end.After(window.AvailableTo) {
+			return true
+		}
+	}
+	return !hasWindow
+}
+
+// Overlaps will check if the two scheduled matches are played at the same time
+func (match *ScheduledMatch) Overlaps(other *ScheduledMatch) bool {
+	return match.StartTime.Before(other.EndTime) && other.StartTime.Before(match.EndTime)
+}
+
+// CheckScheduleConflicts will check if the given match can be played at its venue and time, without conflicting with
+// any other match in the schedule for the same venue or any of the same players
+func CheckScheduleConflicts(schedule []*ScheduledMatch, match *ScheduledMatch) error {
+	if !match.EndTime.After(match.StartTime) {
+		return errors.New("end_time must be after start_time")
+	}
+	for _, other := range schedule {
+		if other.MatchID == match.MatchID || !other.Overlaps(match) {
+			continue
+		}
+		if other.VenueID == match.VenueID {
+			return fmt.Errorf("venue %d is already used by match %d at that time", match.VenueID, other.MatchID)
+		}
+		for _, playerID := range match.Players {
+			if containsInt(other.Players, playerID) {
+				return fmt.Errorf("player %d is already playing match %d at that time", playerID, other.MatchID)
+			}
+		}
+	}
+	return nil
+}
+
+// NewSchedule will assign the given matches to venues and time slots in order of play. A match is only scheduled once all
+// matches it depends on are finished, and all of its players are available and have rested for the configured number of slots.
+// Matches in the fixed schedule, such as manually rescheduled matches, are kept as they are
+func NewSchedule(tournamentID int, matches []*ScheduleMatch, fixed []*ScheduledMatch, options *ScheduleOptions) ([]*ScheduledMatch, error) {
+	err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+	slot := time.Duration(options.SlotMinutes) * time.Minute
+
+	remaining := make([]*ScheduleMatch, 0)
+	scheduled := make(map[int]*ScheduledMatch)
+	for _, match := range fixed {
+		scheduled[match.MatchID] = match
+	}
+	for _, match := range matches {
+		if _, ok := scheduled[match.MatchID]; !ok {
+			remaining = append(remaining, match)
+		}
+	}
+	sort.SliceStable(remaining, func(i, j int) bool { return remaining[i].OrderOfPlay < remaining[j].OrderOfPlay })
+
+	// Make sure we don't keep looking for slots forever if some matches can never be scheduled
+	horizon := options.StartTime.Add(slot * time.Duration((len(remaining)+1)*(options.RestSlots+2)))
+	for _, match := range fixed {
+		if match.EndTime.After(horizon) {
+			horizon = match.EndTime
+		}
+	}
+	for _, window := range options.Availability {
+		if window.AvailableTo.After(horizon) {
+			horizon = window.AvailableTo
+		}
+	}
+
+	schedule := make([]*ScheduledMatch, 0)
+	schedule = append(schedule, fixed...)
+	rest := slot * time.Duration(options.RestSlots)
+	for start := options.StartTime; len(remaining) > 0 && start.Before(horizon); start = start.Add(slot) {
+		end := start.Add(slot)
+		for _, venueID := range options.Venues {
+			for i, match := range remaining {
+				candidate := &ScheduledMatch{TournamentID: tournamentID, MatchID: match.MatchID, VenueID: venueID, StartTime: start, EndTime: end, Players: match.Players}
+				if !isSchedulable(schedule, scheduled, remaining, match, candidate, rest, options.Availability) {
+					continue
+				}
+				schedule = append(schedule, candidate)
+				scheduled[match.MatchID] = candidate
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf("unable to schedule %d matches with the given venues and availability", len(remaining))
+	}
+	sort.SliceStable(schedule, func(i, j int) bool {
+		if !schedule[i].StartTime.Equal(schedule[j].StartTime) {
+			return schedule[i].StartTime.Before(schedule[j].StartTime)
+		}
+		return schedule[i].VenueID < schedule[j].VenueID
+	})
+	return schedule, nil
+}
+
+// isSchedulable will check if the given match can be played in the candidate slot
+func isSchedulable(schedule []*ScheduledMatch, scheduled map[int]*ScheduledMatch, remaining []*ScheduleMatch, match *ScheduleMatch,
+	candidate *ScheduledMatch, rest time.Duration, availability []*PlayerAvailability) bool {
+	for _, dependency := range match.DependsOn {
+		if previous, ok := scheduled[dependency]; ok {
+			if previous.EndTime.After(candidate.StartTime) {
+				return false
+			}
+			continue
+		}
+		// Dependencies which are not part of the schedule are already finished
+		for _, pending := range remaining {
+			if pending.MatchID == dependency {
+				return false
+			}
+		}
+	}
+	for _, playerID := range match.Players {
+		if !IsAvailable(availability, playerID, candidate.StartTime, candidate.EndTime) {
+			return false
+		}
+	}
+	// Extend the candidate by the rest period, to avoid players playing back-to-back matches
+	rested := &ScheduledMatch{MatchID: candidate.MatchID, VenueID: -1, StartTime: candidate.StartTime.Add(-rest),
+		EndTime: candidate.EndTime.Add(rest), Players: candidate.Players}
+	for _, other := range schedule {
+		if other.VenueID == candidate.VenueID && other.Overlaps(candidate) {
+			return false
+		}
+	}
+	return CheckScheduleConflicts(schedule, rested) == nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewScheduleAvoidsBackToBack will check that players get a rest slot between matches, and dependencies are played first
+func TestNewScheduleAvoidsBackToBack(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	matches := []*ScheduleMatch{
+		{MatchID: 1, OrderOfPlay: 1, Players: []int{1, 2}},
+		{MatchID: 2, OrderOfPlay: 2, Players: []int{1, 3}},
+		{MatchID: 3, OrderOfPlay: 3, Players: []int{4, 5}},
+		{MatchID: 4, OrderOfPlay: 4, Players: []int{}, DependsOn: []int{1, 2}},
+	}
+	schedule, err := NewSchedule(1, matches, nil, &ScheduleOptions{Venues: []int{1, 2}, StartTime: start, SlotMinutes: 30, RestSlots: 1})
+	assert.Nil(t, err)
+	assert.Len(t, schedule, 4)
+
+	starts := make(map[int]time.Time)
+	for _, match := range schedule {
+		starts[match.MatchID] = match.StartTime
+	}
+	assert.Equal(t, start, starts[1])
+	assert.Equal(t, start, starts[3])
+	assert.Equal(t, start.Add(60*time.Minute), starts[2])
+	assert.Equal(t, start.Add(90*time.Minute), starts[4])
+}
+
+// TestNewScheduleAvailability will check that matches are only scheduled when all players are available
+func TestNewScheduleAvailability(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	matches := []*ScheduleMatch{{MatchID: 1, OrderOfPlay: 1, Players: []int{1, 2}}}
+	availability := []*PlayerAvailability{{PlayerID: 2, AvailableFrom: start.Add(time.Hour), AvailableTo: start.Add(2 * time.Hour)}}
+	schedule, err := NewSchedule(1, matches, nil, &ScheduleOptions{Venues: []int{1}, StartTime: start, SlotMinutes: 30, Availability: availability})
+	assert.Nil(t, err)
+	assert.Equal(t, start.Add(time.Hour), schedule[0].StartTime)
+
+	availability[0].AvailableTo = start.Add(70 * time.Minute)
+	_, err = NewSchedule(1, matches, nil, &ScheduleOptions{Venues: []int{1}, StartTime: start, SlotMinutes: 30, Availability: availability})
+	assert.NotNil(t, err)
+}
+
+// TestCheckScheduleConflicts will check that a match can not be moved to a busy venue or when a player is already playing
+func TestCheckScheduleConflicts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	schedule := []*ScheduledMatch{{MatchID: 1, VenueID: 1, StartTime: start, EndTime: start.Add(30 * time.Minute), Players: []int{1, 2}}}
+
+	assert.NotNil(t, CheckScheduleConflicts(schedule, &ScheduledMatch{MatchID: 2, VenueID: 1, StartTime: start.Add(15 * time.Minute), EndTime: start.Add(45 * time.Minute), Players: []int{3, 4}}))
+	assert.NotNil(t, CheckScheduleConflicts(schedule, &ScheduledMatch{MatchID: 2, VenueID: 2, StartTime: start, EndTime: start.Add(30 * time.Minute), Players: []int{2, 4}}))
+	assert.Nil(t, CheckScheduleConflicts(schedule, &ScheduledMatch{MatchID: 2, VenueID: 1, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(60 * time.Minute), Players: []int{1, 4}}))
+}
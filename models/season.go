@@ -0,0 +1,99 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// Season struct used for storing a season, which is a divisional tournament linked to the previous and next seasons
+type Season struct {
+	ID               int               `json:"id"`
+	Name             string            `json:"name"`
+	ShortName        string            `json:"short_name"`
+	OfficeID         int               `json:"office_id"`
+	TournamentID     int               `json:"tournament_id"`
+	PreviousSeasonID null.Int          `json:"previous_season_id"`
+	NextSeasonID     null.Int          `json:"next_season_id"`
+	IsFinished       bool              `json:"is_finished"`
+	Divisions        []*SeasonDivision `json:"divisions"`
+}
+
+// SeasonDivision struct used for storing the group used for a division in a season, and how many players move up and down
+type SeasonDivision struct {
+	Division          int `json:"division"`
+	TournamentGroupID int `json:"tournament_group_id"`
+	PromotionCount    int `json:"promotion_count"`
+	RelegationCount   int `json:"relegation_count"`
+}
+
+// SeasonMovements struct used for storing promotions and relegations at the end of a season, and the groups for the next season
+type SeasonMovements struct {
+	Promoted  map[int]bool  `json:"promoted"`
+	Relegated map[int]bool  `json:"relegated"`
+	NextGroup map[int][]int `json:"next_groups"`
+}
+
+// DivisionHistory struct used for storing which division a player played in for a season
+type DivisionHistory struct {
+	PlayerID          int         `json:"player_id"`
+	SeasonID          int         `json:"season_id"`
+	SeasonName        string      `json:"season_name"`
+	TournamentID      int         `json:"tournament_id"`
+	Division          null.Int    `json:"division"`
+	TournamentGroupID int         `json:"tournament_group_id"`
+	GroupName         null.String `json:"tournament_group_name"`
+	IsPromoted        bool        `json:"is_promoted"`
+	IsRelegated       bool        `json:"is_relegated"`
+}
+
+// GetSortedDivisions will return the divisions of the season, with the top division (lowest number) first
+func (season *Season) GetSortedDivisions() []*SeasonDivision {
+	divisions := make([]*SeasonDivision, len(season.Divisions))
+	copy(divisions, season.Divisions)
+	sort.Slice(divisions, func(i, j int) bool { return divisions[i].Division < divisions[j].Division })
+	return divisions
+}
+
+// CalculateSeasonMovements will calculate promotions and relegations based on the final standings of each group, ordered by position.
+// Players can not be promoted from the top division, or relegated from the bottom division.
+// The groups of the next season are returned keyed by tournament group, with players ordered by their previous standing
+func CalculateSeasonMovements(season *Season, standings map[int][]int) *SeasonMovements {
+	movements := &SeasonMovements{Promoted: make(map[int]bool), Relegated: make(map[int]bool), NextGroup: make(map[int][]int)}
+
+	divisions := season.GetSortedDivisions()
+	staying := make(map[int][]int)
+	up := make(map[int][]int)
+	down := make(map[int][]int)
+	for i, division := range divisions {
+		players := standings[division.TournamentGroupID]
+		promoted := division.PromotionCount
+		if i == 0 {
+			promoted = 0
+		}
+		relegated := division.RelegationCount
+		if i == len(divisions)-1 {
+			relegated = 0
+		}
+		for pos, playerID := range players {
+			if pos < promoted {
+				movements.Promoted[playerID] = true
+				up[i-1] = append(up[i-1], playerID)
+			} else if pos >= len(players)-relegated && pos >= promoted {
+				movements.Relegated[playerID] = true
+				down[i+1] = append(down[i+1], playerID)
+			} else {
+				staying[i] = append(staying[i], playerID)
+			}
+		}
+	}
+	for i, division := range divisions {
+		// Relegated players are seeded above promoted players
+		group := make([]int, 0)
+		group = append(group, down[i]...)
+		group = append(group, staying[i]...)
+		group = append(group, up[i]...)
+		movements.NextGroup[division.TournamentGroupID] = group
+	}
+	return movements
+}
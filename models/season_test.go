@@ -0,0 +1,23 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculateSeasonMovements will check that players move between divisions, but not out of the top or bottom division
+func TestCalculateSeasonMovements(t *testing.T) {
+	season := &Season{Divisions: []*SeasonDivision{
+		{Division: 2, TournamentGroupID: 20, PromotionCount: 1, RelegationCount: 1},
+		{Division: 1, TournamentGroupID: 10, PromotionCount: 1, RelegationCount: 1},
+	}}
+	movements := CalculateSeasonMovements(season, map[int][]int{
+		10: {1, 2, 3},
+		20: {4, 5, 6},
+	})
+	assert.Equal(t, map[int]bool{4: true}, movements.Promoted)
+	assert.Equal(t, map[int]bool{3: true}, movements.Relegated)
+	assert.Equal(t, []int{1, 2, 4}, movements.NextGroup[10])
+	assert.Equal(t, []int{3, 5, 6}, movements.NextGroup[20])
+}
@@ -0,0 +1,455 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// SimulationMatch struct used for storing a match which is part of a tournament simulation.
+// Players which are not yet decided are set to 0
+type SimulationMatch struct {
+	MatchID        int         `json:"match_id"`
+	GroupID        int         `json:"tournament_group_id"`
+	Players        []int       `json:"players"`
+	IsFinished     bool        `json:"is_finished"`
+	WinnerID       null.Int    `json:"winner_id"`
+	LegsWon        map[int]int `json:"legs_won"`
+	WinsRequired   int         `json:"wins_required"`
+	LegsRequired   null.Int    `json:"legs_required"`
+	IsDrawPossible bool        `json:"is_draw_possible"`
+	WinnerTo       null.Int    `json:"winner_outcome_match_id"`
+	IsWinnerToHome bool        `json:"is_winner_outcome_home"`
+	LooserTo       null.Int    `json:"looser_outcome_match_id"`
+	IsLooserToHome bool        `json:"is_looser_outcome_home"`
+}
+
+// SimulationModel struct used for storing the ratings and probability functions used to simulate matches
+type SimulationModel struct {
+	Elo             map[int]int
+	WinProbability  func(player1Elo int, player2Elo int) float64
+	DrawProbability func(player1Elo int, player2Elo int) float64
+}
+
+// TournamentSimulationInput struct used for storing the current state of a tournament which should be simulated
+type TournamentSimulationInput struct {
+	TournamentID int
+	// Groups contains the players of each group, ordered by current standing
+	Groups       map[int][]int
+	GroupMatches []*SimulationMatch
+	// PlayoffMatches contains generated playoff matches, ordered by order of play. If no playoffs are generated,
+	// a single elimination bracket is simulated with the top PlayoffsSize players (all players if 0)
+	PlayoffMatches []*SimulationMatch
+	PlayoffsSize   int
+	// PlayoffsWinsRequired contains the number of wins required in a simulated playoff round, by number of matches in the round
+	PlayoffsWinsRequired map[int]int
+	// TiebreakRules are used to order the simulated group standings, with ThreeDartAvg containing the current average of each player
+	TiebreakRules []string
+	ThreeDartAvg  map[int]float32
+}
+
+// TournamentSimulation struct used for storing the result of simulating the remaining matches of a tournament
+type TournamentSimulation struct {
+	TournamentID int                `json:"tournament_id"`
+	Iterations   int                `json:"iterations"`
+	Players      []*SimulatedPlayer `json:"players"`
+}
+
+// SimulatedPlayer struct used for storing the finishing position distribution of a player in a tournament simulation
+type SimulatedPlayer struct {
+	PlayerID          int             `json:"player_id"`
+	TournamentGroupID int             `json:"tournament_group_id"`
+	ExpectedPoints    float64         `json:"expected_points"`
+	GroupPositions    map[int]float64 `json:"group_positions"`
+	TopGroup          float64         `json:"top_group"`
+	MakePlayoffs      float64         `json:"make_playoffs"`
+	WinTournament     float64         `json:"win_tournament"`
+	FinalPositions    map[int]float64 `json:"final_positions"`
+}
+
+// SimulateTournament will play out all remaining group and playoff matches the given number of times,
+// and return the distribution of finishing positions for each player
+func SimulateTournament(input *TournamentSimulationInput, model *SimulationModel, iterations int, rng *rand.Rand) *TournamentSimulation {
+	players := make(map[int]*SimulatedPlayer)
+	getPlayer := func(playerID int, groupID int) *SimulatedPlayer {
+		if _, ok := players[playerID]; !ok {
+			players[playerID] = &SimulatedPlayer{PlayerID: playerID, TournamentGroupID: groupID,
+				GroupPositions: make(map[int]float64), FinalPositions: make(map[int]float64)}
+		}
+		return players[playerID]
+	}
+	for groupID, group := range input.Groups {
+		for _, playerID := range group {
+			getPlayer(playerID, groupID)
+		}
+	}
+
+	for i := 0; i < iterations; i++ {
+		standings := simulateGroups(input, model, rng)
+		for groupID, group := range standings {
+			for pos, standing := range group {
+				player := getPlayer(standing.PlayerID, groupID)
+				player.GroupPositions[pos+1]++
+				player.ExpectedPoints += float64(standing.Points)
+				if pos == 0 {
+					player.TopGroup++
+				}
+			}
+		}
+
+		playoffs := input.PlayoffMatches
+		if len(playoffs) == 0 {
+			seeds := getSimulationPlayoffSeeds(standings, input.PlayoffsSize)
+			bracket, err := NewBracket(seeds, &BracketOptions{Type: BracketSingleElimination})
+			if err != nil {
+				// Not enough players for playoffs
+				continue
+			}
+			playoffs = getSimulationBracketMatches(bracket, input.PlayoffsWinsRequired)
+		}
+		qualified, positions := simulatePlayoffs(playoffs, model, rng)
+		for _, playerID := range qualified {
+			getPlayer(playerID, 0).MakePlayoffs++
+		}
+		for playerID, pos := range positions {
+			player := getPlayer(playerID, 0)
+			player.FinalPositions[pos]++
+			if pos == 1 {
+				player.WinTournament++
+			}
+		}
+	}
+
+	simulation := &TournamentSimulation{TournamentID: input.TournamentID, Iterations: iterations, Players: make([]*SimulatedPlayer, 0)}
+	if iterations == 0 {
+		return simulation
+	}
+	n := float64(iterations)
+	for _, player := range players {
+		player.ExpectedPoints /= n
+		player.TopGroup /= n
+		player.MakePlayoffs /= n
+		player.WinTournament /= n
+		for pos := range player.GroupPositions {
+			player.GroupPositions[pos] /= n
+		}
+		for pos := range player.FinalPositions {
+			player.FinalPositions[pos] /= n
+		}
+		simulation.Players = append(simulation.Players, player)
+	}
+	sort.Slice(simulation.Players, func(i, j int) bool {
+		a, b := simulation.Players[i], simulation.Players[j]
+		if a.WinTournament != b.WinTournament {
+			return a.WinTournament > b.WinTournament
+		}
+		if a.ExpectedPoints != b.ExpectedPoints {
+			return a.ExpectedPoints > b.ExpectedPoints
+		}
+		return a.PlayerID < b.PlayerID
+	})
+	return simulation
+}
+
+// simulateGroups will simulate all unfinished group matches, and return the final standings of each group, ordered by the tiebreak rules
+func simulateGroups(input *TournamentSimulationInput, model *SimulationModel, rng *rand.Rand) map[int][]*TournamentOverview {
+	standings := make(map[int]map[int]*TournamentOverview)
+	for groupID, group := range input.Groups {
+		standings[groupID] = make(map[int]*TournamentOverview)
+		for _, playerID := range group {
+			standings[groupID][playerID] = &TournamentOverview{PlayerID: playerID, ThreeDartAvg: input.ThreeDartAvg[playerID]}
+		}
+	}
+	results := make([]*TiebreakMatch, 0)
+	for _, match := range input.GroupMatches {
+		group, ok := standings[match.GroupID]
+		if !ok || len(match.Players) != 2 {
+			continue
+		}
+		winner, legs := int(match.WinnerID.Int64), match.LegsWon
+		if !match.IsFinished {
+			winner, legs = simulateMatch(match, match.Players, model, rng)
+		}
+		result := &TiebreakMatch{HomePlayerID: match.Players[0], AwayPlayerID: match.Players[1], IsFinished: true}
+		if winner != 0 {
+			result.WinnerID = null.IntFrom(int64(winner))
+		}
+		results = append(results, result)
+		for _, playerID := range match.Players {
+			standing, ok := group[playerID]
+			if !ok {
+				continue
+			}
+			if winner == playerID {
+				standing.Points += 2
+			} else if winner == 0 {
+				standing.Points++
+			}
+			for otherID, won := range legs {
+				if otherID == playerID {
+					standing.LegsFor += won
+				} else {
+					standing.LegsAgainst += won
+				}
+			}
+		}
+	}
+
+	result := make(map[int][]*TournamentOverview)
+	for groupID, group := range input.Groups {
+		// Keep the current order, which is used when players can not be separated
+		current := make([]*TournamentOverview, 0)
+		for _, playerID := range group {
+			standing := standings[groupID][playerID]
+			standing.LegsDifference = standing.LegsFor - standing.LegsAgainst
+			current = append(current, standing)
+		}
+		result[groupID] = SortStandings(current, results, model.Elo, input.TiebreakRules)
+	}
+	return result
+}
+
+// simulateMatch will simulate the remaining legs of the given match, and return the winner (0 for a draw) and legs won by each player.
+// The model gives the probability of winning a match, which is converted to the probability of winning a leg in the format of the match.
+// Unplayed matches where a draw is possible are given an outcome based on the match and draw probabilities, and a score sampled
+// from the scores giving that outcome, while matches already in progress play out the remaining legs
+func simulateMatch(match *SimulationMatch, players []int, model *SimulationModel, rng *rand.Rand) (int, map[int]int) {
+	home, away := players[0], players[1]
+	pMatch := model.WinProbability(model.Elo[home], model.Elo[away])
+	legs := map[int]int{home: match.LegsWon[home], away: match.LegsWon[away]}
+	winnerOf := func(homeLegs int, awayLegs int) int {
+		if homeLegs > awayLegs {
+			return home
+		} else if awayLegs > homeLegs {
+			return away
+		}
+		return 0
+	}
+
+	if !match.IsDrawPossible || !match.LegsRequired.Valid {
+		pLeg := GetLegWinProbability(pMatch, match.WinsRequired)
+		for legs[home] < match.WinsRequired && legs[away] < match.WinsRequired {
+			if rng.Float64() < pLeg {
+				legs[home]++
+			} else {
+				legs[away]++
+			}
+		}
+		return winnerOf(legs[home], legs[away]), legs
+	}
+
+	total := int(match.LegsRequired.Int64)
+	pLeg := GetLegWinProbability(pMatch, total/2+1)
+	remaining := total - legs[home] - legs[away]
+	if remaining <= 0 {
+		return winnerOf(legs[home], legs[away]), legs
+	}
+	// Probability of the home player winning each possible number of the remaining legs
+	scores := make([]float64, remaining+1)
+	for won := 0; won <= remaining; won++ {
+		scores[won] = binomial(remaining, won) * math.Pow(pLeg, float64(won)) * math.Pow(1-pLeg, float64(remaining-won))
+	}
+	if legs[home]+legs[away] == 0 {
+		pDraw := 0.0
+		if total%2 == 0 {
+			pDraw = model.DrawProbability(model.Elo[home], model.Elo[away])
+		}
+		outcomes := map[int]float64{0: pDraw, home: pMatch * (1 - pDraw), away: (1 - pMatch) * (1 - pDraw)}
+		totals := make(map[int]float64)
+		for won, p := range scores {
+			totals[winnerOf(won, remaining-won)] += p
+		}
+		for won, p := range scores {
+			if outcome := winnerOf(won, remaining-won); totals[outcome] > 0 {
+				scores[won] = outcomes[outcome] * p / totals[outcome]
+			}
+		}
+	}
+
+	sum := 0.0
+	for _, p := range scores {
+		sum += p
+	}
+	r := rng.Float64() * sum
+	won := remaining
+	for i, p := range scores {
+		if r < p {
+			won = i
+			break
+		}
+		r -= p
+	}
+	legs[home] += won
+	legs[away] += remaining - won
+	return winnerOf(legs[home], legs[away]), legs
+}
+
+// GetLegWinProbability will return the probability of winning a leg which gives the given probability of winning a match
+// where the given number of legs must be won
+func GetLegWinProbability(pMatch float64, winsRequired int) float64 {
+	low, high := 0.0, 1.0
+	for i := 0; i < 50; i++ {
+		mid := (low + high) / 2
+		if GetMatchWinProbability(mid, winsRequired) < pMatch {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// GetMatchWinProbability will return the probability of winning a match where the given number of legs must be won,
+// with the given probability of winning each leg
+func GetMatchWinProbability(pLeg float64, winsRequired int) float64 {
+	if winsRequired < 1 {
+		winsRequired = 1
+	}
+	p := 0.0
+	for lost := 0; lost < winsRequired; lost++ {
+		p += binomial(winsRequired-1+lost, lost) * math.Pow(pLeg, float64(winsRequired)) * math.Pow(1-pLeg, float64(lost))
+	}
+	return p
+}
+
+// binomial will return the number of ways to choose k of n
+func binomial(n int, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// getSimulationPlayoffSeeds will return the players qualified for playoffs, seeded by group position
+func getSimulationPlayoffSeeds(standings map[int][]*TournamentOverview, size int) []int {
+	groups := make([]int, 0)
+	for groupID := range standings {
+		groups = append(groups, groupID)
+	}
+	sort.Ints(groups)
+
+	seeds := make([]int, 0)
+	for pos := 0; ; pos++ {
+		added := false
+		for _, groupID := range groups {
+			if pos < len(standings[groupID]) {
+				seeds = append(seeds, standings[groupID][pos].PlayerID)
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	if size > 0 && size < len(seeds) {
+		seeds = seeds[:size]
+	}
+	return seeds
+}
+
+// getSimulationBracketMatches will convert the given bracket to matches which can be simulated
+func getSimulationBracketMatches(bracket *Bracket, winsRequired map[int]int) []*SimulationMatch {
+	matches := make([]*SimulationMatch, 0)
+	for _, bm := range bracket.Matches {
+		match := &SimulationMatch{MatchID: bm.Idx, Players: []int{bm.Home.PlayerID, bm.Away.PlayerID}, WinsRequired: winsRequired[bm.RoundSize],
+			IsWinnerToHome: bm.IsWinnerToHome, IsLooserToHome: bm.IsLooserToHome, LegsWon: make(map[int]int)}
+		if match.WinsRequired == 0 {
+			match.WinsRequired = 1
+		}
+		if bm.WinnerTo != -1 {
+			match.WinnerTo = null.IntFrom(int64(bm.WinnerTo))
+		}
+		if bm.LooserTo != -1 {
+			match.LooserTo = null.IntFrom(int64(bm.LooserTo))
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// simulatePlayoffs will simulate all unfinished playoff matches, and return the players taking part and the final position of each player.
+// Players knocked out are given the position after all players still remaining in the round they were knocked out in
+func simulatePlayoffs(matches []*SimulationMatch, model *SimulationModel, rng *rand.Rand) ([]int, map[int]int) {
+	byID := make(map[int]*SimulationMatch)
+	players := make(map[int][]int)
+	fedByLooser := make(map[int]bool)
+	for _, match := range matches {
+		byID[match.MatchID] = match
+		players[match.MatchID] = []int{0, 0}
+		copy(players[match.MatchID], match.Players)
+		if match.LooserTo.Valid {
+			fedByLooser[int(match.LooserTo.Int64)] = true
+		}
+	}
+	var depth func(match *SimulationMatch) int
+	depth = func(match *SimulationMatch) int {
+		if !match.WinnerTo.Valid {
+			return 0
+		}
+		next, ok := byID[int(match.WinnerTo.Int64)]
+		if !ok {
+			return 0
+		}
+		return depth(next) + 1
+	}
+	forward := func(playerID int, to null.Int, isHome bool) {
+		if !to.Valid || playerID == 0 {
+			return
+		}
+		if _, ok := players[int(to.Int64)]; !ok {
+			return
+		}
+		idx := 1
+		if isHome {
+			idx = 0
+		}
+		players[int(to.Int64)][idx] = playerID
+	}
+
+	qualified := make([]int, 0)
+	positions := make(map[int]int)
+	for _, match := range matches {
+		p := players[match.MatchID]
+		for _, playerID := range p {
+			if playerID != 0 && !containsInt(qualified, playerID) {
+				qualified = append(qualified, playerID)
+			}
+		}
+		winner, looser := 0, 0
+		if match.IsFinished && match.WinnerID.Valid {
+			winner = int(match.WinnerID.Int64)
+		} else if p[0] == 0 || p[1] == 0 {
+			// Walkover, if only one player is known
+			winner = p[0] + p[1]
+		} else {
+			winner, _ = simulateMatch(match, p, model, rng)
+			if winner == 0 {
+				// Playoff matches can not end in a draw
+				winner = p[rng.Intn(2)]
+			}
+		}
+		if winner == p[0] {
+			looser = p[1]
+		} else {
+			looser = p[0]
+		}
+		forward(winner, match.WinnerTo, match.IsWinnerToHome)
+		forward(looser, match.LooserTo, match.IsLooserToHome)
+
+		if !match.WinnerTo.Valid {
+			if fedByLooser[match.MatchID] {
+				// Placement match, e.g. third place
+				positions[winner], positions[looser] = 3, 4
+			} else {
+				positions[winner], positions[looser] = 1, 2
+			}
+		} else if !match.LooserTo.Valid && looser != 0 {
+			positions[looser] = 1<<uint(depth(match)) + 1
+		}
+	}
+	delete(positions, 0)
+	return qualified, positions
+}
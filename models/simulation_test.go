@@ -0,0 +1,108 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestSimulationModel() *SimulationModel {
+	return &SimulationModel{
+		Elo:             map[int]int{1: 1800, 2: 1500, 3: 1500, 4: 1200},
+		WinProbability:  func(p1 int, p2 int) float64 { return 1 / (1 + float64(p2)/float64(p1)*float64(p2)/float64(p1)) },
+		DrawProbability: func(p1 int, p2 int) float64 { return 0.1 },
+	}
+}
+
+// TestSimulateTournamentFinishedGroup will check that a finished group always gives the same standings
+func TestSimulateTournamentFinishedGroup(t *testing.T) {
+	input := &TournamentSimulationInput{
+		Groups: map[int][]int{1: {2, 1}},
+		GroupMatches: []*SimulationMatch{
+			{MatchID: 1, GroupID: 1, Players: []int{1, 2}, IsFinished: true, WinnerID: null.IntFrom(2), LegsWon: map[int]int{1: 1, 2: 2}},
+		},
+	}
+	simulation := SimulateTournament(input, getTestSimulationModel(), 100, rand.New(rand.NewSource(1)))
+	assert.Equal(t, 100, simulation.Iterations)
+	for _, player := range simulation.Players {
+		if player.PlayerID == 2 {
+			assert.Equal(t, 1.0, player.TopGroup)
+			assert.Equal(t, 2.0, player.ExpectedPoints)
+		} else {
+			assert.Equal(t, 1.0, player.GroupPositions[2])
+		}
+		assert.Equal(t, 1.0, player.MakePlayoffs)
+	}
+}
+
+// TestSimulateTournamentDistribution will check that position distributions sum to one, and the favourite wins most often
+func TestSimulateTournamentDistribution(t *testing.T) {
+	input := &TournamentSimulationInput{
+		Groups:       map[int][]int{1: {1, 2, 3, 4}},
+		GroupMatches: make([]*SimulationMatch, 0),
+		PlayoffsSize: 2,
+	}
+	players := []int{1, 2, 3, 4}
+	for i := 0; i < len(players); i++ {
+		for j := i + 1; j < len(players); j++ {
+			input.GroupMatches = append(input.GroupMatches, &SimulationMatch{GroupID: 1, Players: []int{players[i], players[j]},
+				WinsRequired: 2, LegsWon: make(map[int]int)})
+		}
+	}
+	simulation := SimulateTournament(input, getTestSimulationModel(), 2000, rand.New(rand.NewSource(1)))
+	assert.Equal(t, 1, simulation.Players[0].PlayerID)
+
+	win := 0.0
+	for _, player := range simulation.Players {
+		sum := 0.0
+		for _, p := range player.GroupPositions {
+			sum += p
+		}
+		assert.InDelta(t, 1.0, sum, 0.0001)
+		win += player.WinTournament
+	}
+	assert.InDelta(t, 1.0, win, 0.0001)
+}
+
+// TestSimulateMatchDraw will check that a drawn outcome splits the legs evenly
+func TestSimulateMatchDraw(t *testing.T) {
+	model := getTestSimulationModel()
+	model.DrawProbability = func(p1 int, p2 int) float64 { return 1 }
+	match := &SimulationMatch{Players: []int{2, 3}, LegsRequired: null.IntFrom(4), IsDrawPossible: true, LegsWon: make(map[int]int)}
+	winner, legs := simulateMatch(match, match.Players, model, rand.New(rand.NewSource(1)))
+	assert.Equal(t, 0, winner)
+	assert.Equal(t, map[int]int{2: 2, 3: 2}, legs)
+}
+
+// TestGetLegWinProbability will check that the leg probability gives back the match probability, and is less extreme in longer matches
+func TestGetLegWinProbability(t *testing.T) {
+	for _, winsRequired := range []int{1, 2, 3, 6} {
+		pLeg := GetLegWinProbability(0.75, winsRequired)
+		assert.InDelta(t, 0.75, GetMatchWinProbability(pLeg, winsRequired), 0.0001)
+	}
+	assert.InDelta(t, 0.75, GetLegWinProbability(0.75, 1), 0.0001)
+	assert.InDelta(t, 0.5, GetLegWinProbability(0.5, 3), 0.0001)
+	assert.Less(t, GetLegWinProbability(0.75, 3), GetLegWinProbability(0.75, 2))
+}
+
+// TestSimulateMatchDrawOutcomes will check that outcomes of matches where a draw is possible follow the match and draw probabilities
+func TestSimulateMatchDrawOutcomes(t *testing.T) {
+	model := getTestSimulationModel()
+	model.WinProbability = func(p1 int, p2 int) float64 { return 0.8 }
+	model.DrawProbability = func(p1 int, p2 int) float64 { return 0.25 }
+	match := &SimulationMatch{Players: []int{1, 4}, LegsRequired: null.IntFrom(4), IsDrawPossible: true, LegsWon: make(map[int]int)}
+	rng := rand.New(rand.NewSource(1))
+
+	outcomes := make(map[int]float64)
+	n := 20000
+	for i := 0; i < n; i++ {
+		winner, legs := simulateMatch(match, match.Players, model, rng)
+		assert.Equal(t, 4, legs[1]+legs[4])
+		outcomes[winner] += 1 / float64(n)
+	}
+	assert.InDelta(t, 0.25, outcomes[0], 0.02)
+	assert.InDelta(t, 0.6, outcomes[1], 0.02)
+	assert.InDelta(t, 0.15, outcomes[4], 0.02)
+}
@@ -16,3 +16,16 @@ type Statistics420 struct {
 	TotalHitRate  float64         `json:"total_hit_rate"`
 	Hitrates      map[int]float64 `json:"hitrates,omitempty"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *Statistics420) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *Statistics420) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.score":    stats.Score,
+		"stats.hit_rate": getBadgeHitRate(stats.TotalHitRate),
+	}
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"github.com/guregu/null"
+)
+
+// MaxAngularOffset is the number of segments a dart can be away from the target number and still count as an angular miss
+const MaxAngularOffset = 3
+
+// ScoringTargets are the numbers players are assumed to aim for when scoring in X01
+var ScoringTargets = []int{20, 19}
+
+// AccuracyTarget struct used for storing the intended target of a dart. Multiplier 0 means any ring of the segment
+type AccuracyTarget struct {
+	Value      int   `json:"value"`
+	Multiplier int64 `json:"multiplier"`
+}
+
+// StatisticsAccuracy struct used for storing angular and radial accuracy statistics
+type StatisticsAccuracy struct {
+	LegID           int        `json:"leg_id,omitempty"`
+	PlayerID        int        `json:"player_id"`
+	MatchTypeID     int        `json:"match_type_id,omitempty"`
+	Darts           int        `json:"darts"`
+	Hits            int        `json:"hits"`
+	SegmentHits     int        `json:"segment_hits"`
+	Misses          int        `json:"misses"`
+	Clockwise       int        `json:"clockwise"`
+	Anticlockwise   int        `json:"anticlockwise"`
+	AngularOffset   int        `json:"-"`
+	RadialInside    int        `json:"radial_inside"`
+	RadialOutside   int        `json:"radial_outside"`
+	HitRate         null.Float `json:"hit_rate"`
+	SegmentHitRate  null.Float `json:"segment_hit_rate"`
+	AngularTendency null.Float `json:"angular_tendency"`
+	RadialTendency  null.Float `json:"radial_tendency"`
+}
+
+// IsHit will check if the given dart hit this target
+func (target *AccuracyTarget) IsHit(dart *Dart) bool {
+	return dart.ValueRaw() == target.Value && (target.Multiplier == 0 || dart.Multiplier == target.Multiplier)
+}
+
+// AddDart will add accuracy statistics for the given dart thrown at the given target
+func (stats *StatisticsAccuracy) AddDart(target *AccuracyTarget, dart *Dart) {
+	if !dart.Value.Valid {
+		return
+	}
+	stats.Darts++
+	if target.IsHit(dart) {
+		stats.Hits++
+	}
+	if dart.ValueRaw() == target.Value {
+		stats.SegmentHits++
+	} else if dart.IsMiss() {
+		stats.Misses++
+	} else if target.Value != BULLSEYE && !dart.IsBull() {
+		offset := GetSegmentOffset(target.Value, dart.ValueRaw())
+		if offset > MaxAngularOffset || offset < -MaxAngularOffset {
+			stats.Misses++
+		} else if offset > 0 {
+			stats.Clockwise++
+			stats.AngularOffset += offset
+		} else {
+			stats.Anticlockwise++
+			stats.AngularOffset += offset
+		}
+	}
+
+	direction := GetRadialDirection(target, dart)
+	if direction < 0 {
+		stats.RadialInside++
+	} else if direction > 0 {
+		stats.RadialOutside++
+	}
+}
+
+// Calculate will calculate rates and tendencies based on the added darts
+func (stats *StatisticsAccuracy) Calculate() {
+	if stats.Darts > 0 {
+		stats.HitRate = null.FloatFrom(float64(stats.Hits) / float64(stats.Darts))
+		stats.SegmentHitRate = null.FloatFrom(float64(stats.SegmentHits) / float64(stats.Darts))
+	}
+	angularMisses := stats.Clockwise + stats.Anticlockwise
+	if angularMisses > 0 {
+		stats.AngularTendency = null.FloatFrom(float64(stats.AngularOffset) / float64(angularMisses))
+	}
+	radialMisses := stats.RadialInside + stats.RadialOutside
+	if radialMisses > 0 {
+		stats.RadialTendency = null.FloatFrom(float64(stats.RadialOutside-stats.RadialInside) / float64(radialMisses))
+	}
+}
+
+// GetX01Target will infer the target of a dart thrown with the given remaining score and number of darts left in the visit.
+// When the score can be checked out with the darts left, the first dart of the preferred checkout route is the target,
+// otherwise the target is the setup shot leaving the preferred checkout for the next visit, or when remaining score is
+// above 170, the nearest scoring triple. Darts thrown with outshot type any are only inferred when scoring
+func GetX01Target(remaining int, dartsLeft int, dart *Dart, outshotType int) *AccuracyTarget {
+	if remaining > MaxCheckout {
+		target := ScoringTargets[0]
+		best := MaxAngularOffset + 1
+		for _, num := range ScoringTargets {
+			offset := GetSegmentOffset(num, dart.ValueRaw())
+			if offset < 0 {
+				offset = -offset
+			}
+			if GetBoardPosition(dart.ValueRaw()) != -1 && offset < best {
+				best = offset
+				target = num
+			}
+		}
+		return &AccuracyTarget{Value: target, Multiplier: TRIPLE}
+	}
+	if outshotType == OUTSHOTANY {
+		return nil
+	}
+	if route := GetCheckoutRoute(remaining, dartsLeft); route != nil {
+		return route[0]
+	}
+	return GetSetupTarget(remaining)
+}
+
+// getRoundTarget will return the target from the given round based target for the dart with the given index
+func getRoundTarget(target Target, dartIdx int) *AccuracyTarget {
+	if target.Values != nil {
+		return &AccuracyTarget{Value: target.Values[dartIdx], Multiplier: target.multipliers[0]}
+	}
+	if target.Value == -1 {
+		// Any number, so we cannot know the intended target
+		return nil
+	}
+	multiplier := int64(0)
+	if len(target.multipliers) == 1 {
+		multiplier = target.multipliers[0]
+	}
+	return &AccuracyTarget{Value: target.Value, Multiplier: multiplier}
+}
+
+// CalculateAccuracyStatistics will calculate accuracy statistics for each player in the given leg, based on the intended target of each dart.
+// Only X01, Around the Clock, Bermuda Triangle and JDC Practice legs are supported
+func CalculateAccuracyStatistics(leg *Leg) map[int]*StatisticsAccuracy {
+	statistics := make(map[int]*StatisticsAccuracy)
+	if leg.LegType == nil {
+		return statistics
+	}
+	matchType := leg.LegType.ID
+	if matchType != X01 && matchType != AROUNDTHECLOCK && matchType != BERMUDATRIANGLE && matchType != JDCPRACTICE {
+		return statistics
+	}
+	outshotType := OUTSHOTDOUBLE
+	if leg.Parameters != nil && leg.Parameters.OutshotType != nil {
+		outshotType = leg.Parameters.OutshotType.ID
+	}
+
+	scores := make(map[int]int)
+	rounds := make(map[int]int)
+	for _, playerID := range leg.Players {
+		statistics[playerID] = &StatisticsAccuracy{LegID: leg.ID, PlayerID: playerID, MatchTypeID: matchType}
+		if matchType == X01 {
+			scores[playerID] = leg.StartingScore
+		}
+	}
+	for _, visit := range leg.Visits {
+		stats, ok := statistics[visit.PlayerID]
+		if !ok {
+			continue
+		}
+		round := rounds[visit.PlayerID]
+		for i, d := range visit.GetDarts() {
+			dart := d
+			var target *AccuracyTarget
+			switch matchType {
+			case X01:
+				target = GetX01Target(scores[visit.PlayerID], 3-i, &dart, outshotType)
+				scores[visit.PlayerID] -= dart.GetScore()
+			case AROUNDTHECLOCK:
+				number := scores[visit.PlayerID] + 1
+				if number > 21 {
+					break
+				}
+				target = &AccuracyTarget{Value: number, Multiplier: SINGLE}
+				if number == 21 {
+					target = &AccuracyTarget{Value: BULLSEYE, Multiplier: 0}
+				}
+				if target.IsHit(&dart) {
+					scores[visit.PlayerID]++
+				}
+			case BERMUDATRIANGLE:
+				if round < len(TargetsBermudaTriangle) {
+					target = getRoundTarget(TargetsBermudaTriangle[round], i)
+				}
+			case JDCPRACTICE:
+				if round < len(TargetsJDCPractice) {
+					target = getRoundTarget(TargetsJDCPractice[round], i)
+				}
+			}
+			if target != nil {
+				stats.AddDart(target, &dart)
+			}
+		}
+		if matchType == X01 && visit.IsBust {
+			// Restore the score from before the visit
+			scores[visit.PlayerID] += visit.GetScore()
+		}
+		rounds[visit.PlayerID]++
+	}
+	for _, stats := range statistics {
+		stats.Calculate()
+	}
+	return statistics
+}
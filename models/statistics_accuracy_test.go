@@ -0,0 +1,74 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetSegmentOffset will check that offsets are signed and wrap around the board
+func TestGetSegmentOffset(t *testing.T) {
+	assert.Equal(t, 1, GetSegmentOffset(20, 1))
+	assert.Equal(t, -1, GetSegmentOffset(20, 5))
+	assert.Equal(t, -2, GetSegmentOffset(19, 17))
+	assert.Equal(t, 0, GetSegmentOffset(20, BULLSEYE))
+}
+
+// TestGetX01Target will check that targets are inferred from checkout routes, setup shots and high scores
+func TestGetX01Target(t *testing.T) {
+	dart := &Dart{Value: null.IntFrom(1), Multiplier: SINGLE}
+	tests := []struct {
+		name      string
+		remaining int
+		dartsLeft int
+		outshot   int
+		expected  *AccuracyTarget
+	}{
+		{"direct double", 32, 3, OUTSHOTDOUBLE, &AccuracyTarget{Value: 16, Multiplier: DOUBLE}},
+		{"bull finish", 50, 1, OUTSHOTDOUBLE, &AccuracyTarget{Value: BULLSEYE, Multiplier: DOUBLE}},
+		{"single setup leaving 32", 41, 2, OUTSHOTDOUBLE, &AccuracyTarget{Value: 9, Multiplier: SINGLE}},
+		{"single setup leaving 40", 60, 2, OUTSHOTDOUBLE, &AccuracyTarget{Value: 20, Multiplier: SINGLE}},
+		{"two dart route", 72, 2, OUTSHOTDOUBLE, &AccuracyTarget{Value: 16, Multiplier: TRIPLE}},
+		{"two dart route with three darts", 100, 3, OUTSHOTDOUBLE, &AccuracyTarget{Value: 20, Multiplier: TRIPLE}},
+		{"three dart route", 170, 3, OUTSHOTMASTER, &AccuracyTarget{Value: 20, Multiplier: TRIPLE}},
+		{"last dart setup leaving 32", 52, 1, OUTSHOTDOUBLE, &AccuracyTarget{Value: 20, Multiplier: SINGLE}},
+		{"last dart setup on odd score", 41, 1, OUTSHOTDOUBLE, &AccuracyTarget{Value: 9, Multiplier: SINGLE}},
+		{"setup on bogey number", 169, 3, OUTSHOTDOUBLE, &AccuracyTarget{Value: 20, Multiplier: TRIPLE}},
+		{"scoring", 301, 3, OUTSHOTDOUBLE, &AccuracyTarget{Value: 20, Multiplier: TRIPLE}},
+		{"scoring any out", 301, 3, OUTSHOTANY, &AccuracyTarget{Value: 20, Multiplier: TRIPLE}},
+		{"checkout any out", 32, 3, OUTSHOTANY, nil},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, GetX01Target(test.remaining, test.dartsLeft, dart, test.outshot), test.name)
+	}
+	assert.Equal(t, &AccuracyTarget{Value: 19, Multiplier: TRIPLE}, GetX01Target(301, 3, &Dart{Value: null.IntFrom(7), Multiplier: SINGLE}, OUTSHOTDOUBLE))
+}
+
+// TestGetCheckoutRoute will check the preferred checkout routes
+func TestGetCheckoutRoute(t *testing.T) {
+	assert.Equal(t, []*AccuracyTarget{{Value: 20, Multiplier: TRIPLE}, {Value: 20, Multiplier: TRIPLE}, {Value: BULLSEYE, Multiplier: DOUBLE}}, GetCheckoutRoute(170, 3))
+	assert.Equal(t, []*AccuracyTarget{{Value: 16, Multiplier: TRIPLE}, {Value: 12, Multiplier: DOUBLE}}, GetCheckoutRoute(72, 3))
+	assert.Nil(t, GetCheckoutRoute(169, 3))
+	assert.Nil(t, GetCheckoutRoute(101, 1))
+	assert.Nil(t, GetCheckoutRoute(1, 3))
+}
+
+// TestAccuracyAddDart will check that angular and radial tendencies are calculated
+func TestAccuracyAddDart(t *testing.T) {
+	stats := new(StatisticsAccuracy)
+	target := &AccuracyTarget{Value: 20, Multiplier: TRIPLE}
+	stats.AddDart(target, &Dart{Value: null.IntFrom(20), Multiplier: TRIPLE})
+	stats.AddDart(target, &Dart{Value: null.IntFrom(1), Multiplier: SINGLE})
+	stats.AddDart(target, &Dart{Value: null.IntFrom(1), Multiplier: DOUBLE})
+	stats.AddDart(target, &Dart{Value: null.IntFrom(0), Multiplier: SINGLE})
+	stats.Calculate()
+
+	assert.Equal(t, 4, stats.Darts)
+	assert.Equal(t, 1, stats.Hits)
+	assert.Equal(t, 1, stats.Misses)
+	assert.Equal(t, 2, stats.Clockwise)
+	assert.Equal(t, 0.25, stats.HitRate.Float64)
+	assert.Equal(t, 1.0, stats.AngularTendency.Float64)
+	assert.Equal(t, 1.0, stats.RadialTendency.Float64)
+}
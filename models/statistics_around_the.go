@@ -24,3 +24,19 @@ type StatisticsAroundThe struct {
 	Marks         int64 `json:"-"`
 	CurrentStreak int64 `json:"-"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsAroundThe) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsAroundThe) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts":          stats.DartsThrown,
+		"stats.score":          stats.Score,
+		"stats.shanghai":       int(stats.Shanghai.ValueOrZero()),
+		"stats.longest_streak": int(stats.LongestStreak.ValueOrZero()),
+		"stats.hit_rate":       getBadgeHitRate(stats.TotalHitRate),
+	}
+}
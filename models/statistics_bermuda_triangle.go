@@ -21,3 +21,19 @@ type StatisticsBermudaTriangle struct {
 	Hitrates            map[int]float64 `json:"hitrates,omitempty"`
 	HitCount            int             `json:"hit_count,omitempty"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsBermudaTriangle) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsBermudaTriangle) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts":         stats.DartsThrown,
+		"stats.score":         stats.Score,
+		"stats.highest_score": stats.HighestScoreReached,
+		"stats.marks":         stats.TotalMarks,
+		"stats.hit_rate":      getBadgeHitRate(stats.TotalHitRate),
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"github.com/guregu/null"
+)
+
+// StatisticsClutch struct used for storing statistics about how a player performs under pressure
+type StatisticsClutch struct {
+	PlayerID                          int        `json:"player_id"`
+	DecidingLegsPlayed                int        `json:"deciding_legs_played"`
+	DecidingLegsWon                   int        `json:"deciding_legs_won"`
+	DecidingLegsWinPercentage         null.Float `json:"deciding_legs_win_percentage"`
+	OpponentOnFinishLegsPlayed        int        `json:"opponent_on_finish_legs_played"`
+	OpponentOnFinishLegsWon           int        `json:"opponent_on_finish_legs_won"`
+	OpponentOnFinishWinPercentage     null.Float `json:"opponent_on_finish_win_percentage"`
+	VisitsAfterOpponentBust           int        `json:"visits_after_opponent_bust"`
+	PointsAfterOpponentBust           int        `json:"-"`
+	DartsAfterOpponentBust            int        `json:"-"`
+	ThreeDartAvgAfterOpponentBust     null.Float `json:"three_dart_avg_after_opponent_bust"`
+	CheckoutAttemptsOpponentBelow40   int        `json:"checkout_attempts_opponent_below_40"`
+	CheckoutsOpponentBelow40          int        `json:"checkouts_opponent_below_40"`
+	CheckoutPercentageOpponentBelow40 null.Float `json:"checkout_percentage_opponent_below_40"`
+}
+
+// BogeyNumbers are the scores below 170 which cannot be checked out in a single visit
+var BogeyNumbers = []int{169, 168, 166, 165, 163, 162, 159}
+
+// IsOnFinish will check if the given remaining score can be checked out in a single visit
+func IsOnFinish(score int) bool {
+	return score > 1 && score <= 170 && !containsInt(BogeyNumbers, score)
+}
+
+// AddLeg will add clutch statistics for the given player from the given leg.
+// The leg must contain ordered visits, and be a finished two player X01 leg
+func (stats *StatisticsClutch) AddLeg(leg *Leg, isDecidingLeg bool) {
+	if len(leg.Players) != 2 || len(leg.Visits) == 0 {
+		return
+	}
+	opponentID := leg.Players[0]
+	if opponentID == stats.PlayerID {
+		opponentID = leg.Players[1]
+	}
+	outshotType := OUTSHOTDOUBLE
+	if leg.Parameters != nil && leg.Parameters.OutshotType != nil {
+		outshotType = leg.Parameters.OutshotType.ID
+	}
+	isWinner := leg.WinnerPlayerID.Valid && int(leg.WinnerPlayerID.Int64) == stats.PlayerID
+
+	if isDecidingLeg {
+		stats.DecidingLegsPlayed++
+		if isWinner {
+			stats.DecidingLegsWon++
+		}
+	}
+
+	scores := map[int]int{stats.PlayerID: leg.StartingScore, opponentID: leg.StartingScore}
+	opponentOnFinish := false
+	opponentBusted := false
+	for _, visit := range leg.Visits {
+		currentScore := scores[visit.PlayerID]
+		if visit.PlayerID == stats.PlayerID {
+			opponentScore := scores[opponentID]
+			if IsOnFinish(opponentScore) {
+				opponentOnFinish = true
+			}
+			if opponentBusted {
+				stats.VisitsAfterOpponentBust++
+				stats.DartsAfterOpponentBust += visit.GetDartsThrown()
+				if !visit.IsBust {
+					stats.PointsAfterOpponentBust += visit.GetScore()
+				}
+			}
+			if opponentScore <= 40 {
+				score := currentScore
+				for i, dart := range visit.GetDarts() {
+					if dart.IsCheckoutAttempt(score, i+1, outshotType) {
+						stats.CheckoutAttemptsOpponentBelow40++
+					}
+					score -= dart.GetScore()
+				}
+				if !visit.IsBust && visit.IsCheckout(currentScore, outshotType) {
+					stats.CheckoutsOpponentBelow40++
+				}
+			}
+			opponentBusted = false
+		} else {
+			opponentBusted = visit.IsBust
+		}
+		if !visit.IsBust {
+			scores[visit.PlayerID] = currentScore - visit.GetScore()
+		}
+	}
+
+	if opponentOnFinish {
+		stats.OpponentOnFinishLegsPlayed++
+		if isWinner {
+			stats.OpponentOnFinishLegsWon++
+		}
+	}
+	stats.calculatePercentages()
+}
+
+func (stats *StatisticsClutch) calculatePercentages() {
+	if stats.DecidingLegsPlayed > 0 {
+		stats.DecidingLegsWinPercentage = null.FloatFrom(float64(stats.DecidingLegsWon) / float64(stats.DecidingLegsPlayed) * 100)
+	}
+	if stats.OpponentOnFinishLegsPlayed > 0 {
+		stats.OpponentOnFinishWinPercentage = null.FloatFrom(float64(stats.OpponentOnFinishLegsWon) / float64(stats.OpponentOnFinishLegsPlayed) * 100)
+	}
+	if stats.DartsAfterOpponentBust > 0 {
+		stats.ThreeDartAvgAfterOpponentBust = null.FloatFrom(float64(stats.PointsAfterOpponentBust) / float64(stats.DartsAfterOpponentBust) * 3)
+	}
+	if stats.CheckoutAttemptsOpponentBelow40 > 0 {
+		stats.CheckoutPercentageOpponentBelow40 = null.FloatFrom(float64(stats.CheckoutsOpponentBelow40) / float64(stats.CheckoutAttemptsOpponentBelow40) * 100)
+	}
+}
+
+// IsDecidingLeg will check if a leg is deciding for the given match, given the number of legs won by each player before the leg started
+func IsDecidingLeg(winsRequired int, legsWon map[int]int) bool {
+	if winsRequired <= 1 || len(legsWon) != 2 {
+		return false
+	}
+	for _, wins := range legsWon {
+		if wins != winsRequired-1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVisit(playerID int, isBust bool, darts ...int64) *Visit {
+	visit := &Visit{PlayerID: playerID, IsBust: isBust, FirstDart: &Dart{}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	for i, dart := range []*Dart{visit.FirstDart, visit.SecondDart, visit.ThirdDart} {
+		if i*2 < len(darts) {
+			dart.Value = null.IntFrom(darts[i*2])
+			dart.Multiplier = darts[i*2+1]
+		}
+	}
+	return visit
+}
+
+// TestIsDecidingLeg will check that deciding legs are detected
+func TestIsDecidingLeg(t *testing.T) {
+	assert.Equal(t, IsDecidingLeg(3, map[int]int{1: 2, 2: 2}), true, "should be deciding leg")
+	assert.Equal(t, IsDecidingLeg(3, map[int]int{1: 2, 2: 1}), false, "should not be deciding leg")
+	assert.Equal(t, IsDecidingLeg(1, map[int]int{1: 0, 2: 0}), false, "single leg match should not be deciding leg")
+}
+
+// TestClutchAddLeg will check that clutch statistics are calculated from the visits of a leg
+func TestClutchAddLeg(t *testing.T) {
+	leg := &Leg{
+		StartingScore:  101,
+		Players:        []int{1, 2},
+		WinnerPlayerID: null.IntFrom(1),
+		Visits: []*Visit{
+			newVisit(1, false, 20, 1, 20, 1, 1, 1), // 60 left
+			newVisit(2, false, 20, 3, 1, 1, 5, 1),  // 35 left
+			newVisit(1, false, 1, 1, 1, 1, 1, 1),   // 57 left
+			newVisit(2, true, 20, 2),               // Bust
+			newVisit(1, false, 17, 1, 20, 2),       // Checkout
+		},
+	}
+	stats := &StatisticsClutch{PlayerID: 1}
+	stats.AddLeg(leg, true)
+
+	assert.Equal(t, stats.DecidingLegsPlayed, 1)
+	assert.Equal(t, stats.DecidingLegsWon, 1)
+	assert.Equal(t, stats.OpponentOnFinishLegsWon, 1)
+	assert.Equal(t, stats.VisitsAfterOpponentBust, 1)
+	assert.Equal(t, stats.ThreeDartAvgAfterOpponentBust.Float64, 85.5)
+	assert.Equal(t, stats.CheckoutsOpponentBelow40, 1)
+	assert.Equal(t, stats.CheckoutAttemptsOpponentBelow40, 1)
+}
@@ -24,3 +24,18 @@ type StatisticsCricket struct {
 	Marks8         int      `json:"marks_8"`
 	Marks9         int      `json:"marks_9"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsCricket) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsCricket) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.rounds":          stats.Rounds,
+		"stats.score":           int(stats.Score.ValueOrZero()),
+		"stats.marks":           stats.TotalMarks,
+		"stats.max_round_marks": getBadgeMaxRoundMarks(5, stats.Marks5, stats.Marks6, stats.Marks7, stats.Marks8, stats.Marks9),
+	}
+}
@@ -25,3 +25,17 @@ type StatisticsDartsAtX struct {
 	Hits9         int             `json:"hits_9"`
 	Hitrates      map[int]float32 `json:"hitrates,omitempty"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsDartsAtX) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsDartsAtX) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.score":           int(stats.Score.ValueOrZero()),
+		"stats.max_round_marks": getBadgeMaxRoundMarks(5, stats.Hits5, stats.Hits6, stats.Hits7, stats.Hits8, stats.Hits9),
+		"stats.hit_rate":        getBadgeHitRate(float64(stats.HitRate)),
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import "github.com/guregu/null"
+
+// Time series buckets for global statistics
+const (
+	GlobalStatisticsBucketDay   = "day"
+	GlobalStatisticsBucketWeek  = "week"
+	GlobalStatisticsBucketMonth = "month"
+)
+
+// GlobalStatisticsPeriod struct used for storing global statistics for a given period, with an optional time series
+type GlobalStatisticsPeriod struct {
+	From        string                    `json:"from"`
+	To          string                    `json:"to"`
+	MatchTypeID int                       `json:"match_type_id,omitempty"`
+	OfficeID    int                       `json:"office_id,omitempty"`
+	Bucket      string                    `json:"bucket,omitempty"`
+	Total       map[int]*GlobalStatistics `json:"total"`
+	Buckets     []*GlobalStatisticsBucket `json:"buckets,omitempty"`
+}
+
+// GlobalStatisticsBucket struct used for storing global statistics per office for a single day, week or month
+type GlobalStatisticsBucket struct {
+	Start   string                    `json:"start"`
+	Offices map[int]*GlobalStatistics `json:"offices"`
+}
+
+// IsValidGlobalStatisticsBucket will check if the given bucket is supported. Empty means no time series
+func IsValidGlobalStatisticsBucket(bucket string) bool {
+	return bucket == "" || bucket == GlobalStatisticsBucketDay || bucket == GlobalStatisticsBucketWeek || bucket == GlobalStatisticsBucketMonth
+}
+
+// Add will add the counters of the given statistics to these statistics
+func (stats *GlobalStatistics) Add(other *GlobalStatistics) {
+	stats.FishNChips += other.FishNChips
+	stats.Matches += other.Matches
+	stats.Legs += other.Legs
+	stats.Visits += other.Visits
+	stats.Darts += other.Darts
+	stats.Points += other.Points
+	stats.PointsBusted += other.PointsBusted
+	stats.Score180s += other.Score180s
+	stats.ScoreBullseyes += other.ScoreBullseyes
+}
+
+// CalculateRates will calculate derived rates based on the counters
+func (stats *GlobalStatistics) CalculateRates() {
+	if stats.Darts > 0 {
+		stats.Score180sPer1000Darts = null.FloatFrom(float64(stats.Score180s) * 1000 / float64(stats.Darts))
+	}
+	if stats.Points+stats.PointsBusted > 0 {
+		stats.BustedPointsRatio = null.FloatFrom(float64(stats.PointsBusted) / float64(stats.Points+stats.PointsBusted))
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGlobalStatisticsCalculateRates will check that derived rates are calculated from the counters
+func TestGlobalStatisticsCalculateRates(t *testing.T) {
+	stats := &GlobalStatistics{Darts: 2000, Score180s: 3, Points: 900, PointsBusted: 100}
+	stats.CalculateRates()
+	assert.Equal(t, 1.5, stats.Score180sPer1000Darts.Float64)
+	assert.Equal(t, 0.1, stats.BustedPointsRatio.Float64)
+
+	empty := new(GlobalStatistics)
+	empty.CalculateRates()
+	assert.False(t, empty.Score180sPer1000Darts.Valid)
+	assert.False(t, empty.BustedPointsRatio.Valid)
+}
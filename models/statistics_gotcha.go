@@ -18,3 +18,17 @@ type StatisticsGotcha struct {
 	OthersReset   int      `json:"others_reset"`
 	Score         int      `json:"score,omitempty"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsGotcha) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsGotcha) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts":         stats.DartsThrown,
+		"stats.score":         stats.Score,
+		"stats.highest_score": stats.HighestScore,
+	}
+}
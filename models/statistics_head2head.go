@@ -10,4 +10,5 @@ type StatisticsHead2Head struct {
 	PlayerVisits        map[int][]*Visit              `json:"player_visits"`
 	PlayerCheckouts     map[int][]*CheckoutStatistics `json:"player_checkouts"`
 	PlayerElos          map[int]*PlayerElo            `json:"player_elo"`
+	PlayerClutch        map[int]*StatisticsClutch     `json:"player_clutch_statistics"`
 }
@@ -21,3 +21,17 @@ type StatisticsJDCPractice struct {
 	// Values used only to calculate statistics
 	Marks int64 `json:"-"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsJDCPractice) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsJDCPractice) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts":         stats.DartsThrown,
+		"stats.score":         stats.Score,
+		"stats.highest_score": stats.HighestScore,
+	}
+}
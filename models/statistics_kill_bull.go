@@ -24,3 +24,19 @@ type StatisticsKillBull struct {
 
 	CurrentStreak int `json:"-"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsKillBull) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsKillBull) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts":           stats.DartsThrown,
+		"stats.score":           stats.Score,
+		"stats.max_round_marks": getBadgeMaxRoundMarks(3, stats.Marks3, stats.Marks4, stats.Marks5, stats.Marks6),
+		"stats.longest_streak":  stats.LongestStreak,
+		"stats.hit_rate":        getBadgeHitRate(stats.TotalHitRate),
+	}
+}
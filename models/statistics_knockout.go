@@ -18,3 +18,16 @@ type StatisticsKnockout struct {
 	LivesTaken    int      `json:"lives_taken"`
 	FinalPosition int      `json:"final_position"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsKnockout) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsKnockout) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts":    stats.DartsThrown,
+		"stats.position": stats.FinalPosition,
+	}
+}
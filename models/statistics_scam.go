@@ -20,3 +20,16 @@ type StatisticsScam struct {
 	ThreeDartAvg       float32  `json:"three_dart_avg"`
 	Score              int      `json:"score"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsScam) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsScam) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts": stats.DartsThrownStopper + stats.DartsThrownScorer,
+		"stats.score": stats.Score,
+	}
+}
@@ -21,3 +21,16 @@ type StatisticsShootout struct {
 	Hits          map[int64]*Hits `json:"hits,omitempty"`
 	HighestScore  int             `json:"highest_score"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsShootout) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsShootout) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.score":         stats.Score,
+		"stats.highest_score": stats.HighestScore,
+	}
+}
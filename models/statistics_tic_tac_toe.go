@@ -36,3 +36,18 @@ type StatisticsTicTacToe struct {
 	NumbersClosed int      `json:"numbers_closed"`
 	HighestClosed int      `json:"highest_closed"`
 }
+
+// GetPlayerID will return the player the statistics are for
+func (stats *StatisticsTicTacToe) GetPlayerID() int {
+	return stats.PlayerID
+}
+
+// GetBadgeFacts will return the badge facts of the finished leg statistics
+func (stats *StatisticsTicTacToe) GetBadgeFacts() BadgeFacts {
+	return BadgeFacts{
+		"stats.darts":          stats.DartsThrown,
+		"stats.score":          stats.Score,
+		"stats.numbers_closed": stats.NumbersClosed,
+		"stats.highest_closed": stats.HighestClosed,
+	}
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// VisitPhaseOpening is the first three visits (nine darts) of a leg
+	VisitPhaseOpening = "opening"
+	// VisitPhaseMid is every visit after the opening, until the player is on a possible finish
+	VisitPhaseMid = "mid"
+	// VisitPhaseSetup is every visit started with 170 or less remaining
+	VisitPhaseSetup = "setup"
+
+	// VisitDistributionBucketSize is the size of each bucket in the visit score histogram
+	VisitDistributionBucketSize = 20
+)
+
+// VisitDistributionPercentiles are the percentiles calculated for each visit distribution
+var VisitDistributionPercentiles = []int{10, 25, 50, 75, 90}
+
+// VisitDistribution struct used for storing the distribution of visit scores
+type VisitDistribution struct {
+	Visits            int             `json:"visits"`
+	Mean              float64         `json:"mean"`
+	StandardDeviation float64         `json:"standard_deviation"`
+	ConsistencyIndex  float64         `json:"consistency_index"`
+	Percentiles       map[int]float64 `json:"percentiles"`
+	Histogram         map[int]int     `json:"histogram"`
+	scores            []int
+}
+
+// StatisticsVisitDistribution struct used for storing visit score distribution for a player, overall and split by phase of leg
+type StatisticsVisitDistribution struct {
+	PlayerID int                           `json:"player_id"`
+	Overall  *VisitDistribution            `json:"overall"`
+	Phases   map[string]*VisitDistribution `json:"phases"`
+}
+
+// NewStatisticsVisitDistribution will return a new, empty distribution for the given player
+func NewStatisticsVisitDistribution(playerID int) *StatisticsVisitDistribution {
+	return &StatisticsVisitDistribution{
+		PlayerID: playerID,
+		Overall:  new(VisitDistribution),
+		Phases: map[string]*VisitDistribution{
+			VisitPhaseOpening: new(VisitDistribution),
+			VisitPhaseMid:     new(VisitDistribution),
+			VisitPhaseSetup:   new(VisitDistribution),
+		},
+	}
+}
+
+// Add will add the given visit score to the distribution
+func (dist *VisitDistribution) Add(score int) {
+	dist.scores = append(dist.scores, score)
+}
+
+// Calculate will calculate histogram, percentiles, standard deviation and consistency index from all added scores
+func (dist *VisitDistribution) Calculate() {
+	dist.Visits = len(dist.scores)
+	dist.Histogram = make(map[int]int)
+	dist.Percentiles = make(map[int]float64)
+	if dist.Visits == 0 {
+		return
+	}
+
+	sorted := make([]int, len(dist.scores))
+	copy(sorted, dist.scores)
+	sort.Ints(sorted)
+
+	total := 0
+	for _, score := range sorted {
+		total += score
+		dist.Histogram[(score/VisitDistributionBucketSize)*VisitDistributionBucketSize]++
+	}
+	dist.Mean = float64(total) / float64(dist.Visits)
+
+	variance := 0.0
+	for _, score := range sorted {
+		variance += math.Pow(float64(score)-dist.Mean, 2)
+	}
+	dist.StandardDeviation = math.Sqrt(variance / float64(dist.Visits))
+
+	// Consistency index is 100 minus the coefficient of variation, so a player always scoring the same gets 100
+	if dist.Mean > 0 {
+		dist.ConsistencyIndex = math.Max(0, 100*(1-dist.StandardDeviation/dist.Mean))
+	}
+
+	for _, p := range VisitDistributionPercentiles {
+		dist.Percentiles[p] = percentile(sorted, p)
+	}
+}
+
+// Calculate will calculate the overall distribution and all phases
+func (stats *StatisticsVisitDistribution) Calculate() {
+	stats.Overall.Calculate()
+	for _, phase := range stats.Phases {
+		phase.Calculate()
+	}
+}
+
+// CalculateVisitDistribution will calculate visit score distribution for each player from the given legs.
+// Only X01 and Shootout legs are included, and the legs must contain ordered visits
+func CalculateVisitDistribution(legs []*Leg) map[int]*StatisticsVisitDistribution {
+	statistics := make(map[int]*StatisticsVisitDistribution)
+	for _, leg := range legs {
+		if leg.LegType == nil || (leg.LegType.ID != X01 && leg.LegType.ID != SHOOTOUT) {
+			continue
+		}
+		remaining := make(map[int]int)
+		visitCount := make(map[int]int)
+		for _, playerID := range leg.Players {
+			remaining[playerID] = leg.StartingScore
+			if _, ok := statistics[playerID]; !ok {
+				statistics[playerID] = NewStatisticsVisitDistribution(playerID)
+			}
+		}
+		for _, visit := range leg.Visits {
+			stats, ok := statistics[visit.PlayerID]
+			if !ok {
+				continue
+			}
+			visitCount[visit.PlayerID]++
+
+			phase := VisitPhaseMid
+			if leg.LegType.ID == X01 && remaining[visit.PlayerID] <= 170 {
+				phase = VisitPhaseSetup
+			} else if visitCount[visit.PlayerID] <= 3 {
+				phase = VisitPhaseOpening
+			}
+
+			score := 0
+			if !visit.IsBust {
+				score = visit.GetScore()
+				remaining[visit.PlayerID] -= score
+			}
+			stats.Overall.Add(score)
+			stats.Phases[phase].Add(score)
+		}
+	}
+	for _, stats := range statistics {
+		stats.Calculate()
+	}
+	return statistics
+}
+
+// percentile will return the given percentile from the sorted values, using linear interpolation between closest ranks
+func percentile(sorted []int, p int) float64 {
+	if len(sorted) == 1 {
+		return float64(sorted[0])
+	}
+	rank := float64(p) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return float64(sorted[lower]) + (rank-float64(lower))*float64(sorted[upper]-sorted[lower])
+}
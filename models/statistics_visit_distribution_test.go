@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVisitDistributionCalculate will check that distribution values are calculated correctly
+func TestVisitDistributionCalculate(t *testing.T) {
+	dist := new(VisitDistribution)
+	for _, score := range []int{20, 40, 60, 80} {
+		dist.Add(score)
+	}
+	dist.Calculate()
+
+	assert.Equal(t, dist.Visits, 4)
+	assert.Equal(t, dist.Mean, 50.0)
+	assert.InDelta(t, dist.StandardDeviation, 22.36, 0.01)
+	assert.InDelta(t, dist.ConsistencyIndex, 55.28, 0.01)
+	assert.Equal(t, dist.Percentiles[50], 50.0)
+	assert.Equal(t, dist.Histogram[20], 1)
+	assert.Equal(t, dist.Histogram[40], 1)
+}
+
+// TestCalculateVisitDistribution_Phases will check that visits are split into the correct phase of the leg
+func TestCalculateVisitDistribution_Phases(t *testing.T) {
+	leg := &Leg{
+		StartingScore: 301,
+		LegType:       &MatchType{ID: X01},
+		Players:       []int{1},
+		Visits: []*Visit{
+			newVisit(1, false, 20, 1, 20, 1, 20, 1),
+			newVisit(1, false, 20, 1, 20, 1, 20, 1),
+			newVisit(1, false, 20, 1, 20, 1, 20, 1), // 121 left
+			newVisit(1, true, 20, 3, 20, 3),
+			newVisit(1, false, 20, 3, 11, 3, 14, 2),
+		},
+	}
+	stats := CalculateVisitDistribution([]*Leg{leg})[1]
+
+	assert.Equal(t, stats.Overall.Visits, 5)
+	assert.Equal(t, stats.Phases[VisitPhaseOpening].Visits, 3)
+	assert.Equal(t, stats.Phases[VisitPhaseMid].Visits, 0)
+	assert.Equal(t, stats.Phases[VisitPhaseSetup].Visits, 2)
+	assert.Equal(t, stats.Phases[VisitPhaseSetup].Histogram[0], 1)
+}
@@ -70,6 +70,9 @@ type GlobalStatistics struct {
 	PointsBusted   int `json:"points_busted"`
 	Score180s      int `json:"score_180s"`
 	ScoreBullseyes int `json:"score_bullseyes"`
+
+	Score180sPer1000Darts null.Float `json:"score_180s_per_1000_darts"`
+	BustedPointsRatio     null.Float `json:"busted_points_ratio"`
 }
 
 // CheckoutStatistics stuct used for storing detailed checkout statistics
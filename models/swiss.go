@@ -0,0 +1,340 @@
+package models
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// SwissTournamentInput struct used for generating a new Swiss-system tournament
+type SwissTournamentInput struct {
+	Tournament
+	Rounds int `json:"swiss_rounds"`
+}
+
+// SwissTournament struct used for storing the state of a Swiss-system tournament
+type SwissTournament struct {
+	TournamentID int            `json:"tournament_id"`
+	Rounds       int            `json:"rounds"`
+	CurrentRound int            `json:"current_round"`
+	Standings    []*SwissPlayer `json:"standings"`
+	Matches      []*SwissMatch  `json:"matches"`
+	players      map[int]*SwissPlayer
+}
+
+// SwissMatch struct used for storing a single pairing in a Swiss-system tournament. A match without an away player is a bye
+type SwissMatch struct {
+	Round        int      `json:"round"`
+	MatchID      null.Int `json:"match_id"`
+	HomePlayerID int      `json:"home_player_id"`
+	AwayPlayerID null.Int `json:"away_player_id"`
+	IsFinished   bool     `json:"is_finished"`
+	WinnerID     null.Int `json:"winner_id"`
+}
+
+// SwissPlayer struct used for storing the standing of a player in a Swiss-system tournament
+type SwissPlayer struct {
+	PlayerID        int     `json:"player_id"`
+	Seed            int     `json:"seed"`
+	Rank            int     `json:"rank"`
+	Played          int     `json:"played"`
+	Points          float64 `json:"points"`
+	Buchholz        float64 `json:"buchholz"`
+	SonnebornBerger float64 `json:"sonneborn_berger"`
+	ThrowFirst      int     `json:"throw_first"`
+	ThrowSecond     int     `json:"throw_second"`
+	HadBye          bool    `json:"had_bye"`
+	Opponents       []int   `json:"opponents"`
+	lastThrewFirst  bool
+	results         map[int]float64
+}
+
+// IsBye will check if this match is a bye
+func (match *SwissMatch) IsBye() bool {
+	return !match.AwayPlayerID.Valid
+}
+
+// NewSwissTournament will create a new Swiss-system tournament for the given players, ordered by seed, and calculate standings from the given matches
+func NewSwissTournament(tournamentID int, rounds int, seeds []int, matches []*SwissMatch) *SwissTournament {
+	swiss := &SwissTournament{TournamentID: tournamentID, Rounds: rounds, Matches: matches, players: make(map[int]*SwissPlayer)}
+	for i, playerID := range seeds {
+		swiss.players[playerID] = &SwissPlayer{PlayerID: playerID, Seed: i + 1, Opponents: make([]int, 0), results: make(map[int]float64)}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Round < matches[j].Round })
+	for _, match := range matches {
+		if match.Round > swiss.CurrentRound {
+			swiss.CurrentRound = match.Round
+		}
+		home := swiss.getPlayer(match.HomePlayerID)
+		if match.IsBye() {
+			home.HadBye = true
+			home.Points++
+			continue
+		}
+		away := swiss.getPlayer(int(match.AwayPlayerID.Int64))
+		home.Opponents = append(home.Opponents, away.PlayerID)
+		away.Opponents = append(away.Opponents, home.PlayerID)
+		home.ThrowFirst++
+		home.lastThrewFirst = true
+		away.ThrowSecond++
+		away.lastThrewFirst = false
+		if !match.IsFinished {
+			continue
+		}
+		home.Played++
+		away.Played++
+		if !match.WinnerID.Valid {
+			home.results[away.PlayerID] += 0.5
+			away.results[home.PlayerID] += 0.5
+		} else if int(match.WinnerID.Int64) == home.PlayerID {
+			home.results[away.PlayerID]++
+		} else {
+			away.results[home.PlayerID]++
+		}
+	}
+	for _, player := range swiss.players {
+		for _, result := range player.results {
+			player.Points += result
+		}
+	}
+	for _, player := range swiss.players {
+		for _, opponentID := range player.Opponents {
+			opponent := swiss.players[opponentID]
+			player.Buchholz += opponent.Points
+			player.SonnebornBerger += player.results[opponentID] * opponent.Points
+		}
+	}
+
+	swiss.Standings = make([]*SwissPlayer, 0)
+	for _, player := range swiss.players {
+		swiss.Standings = append(swiss.Standings, player)
+	}
+	sort.Slice(swiss.Standings, func(i, j int) bool {
+		a, b := swiss.Standings[i], swiss.Standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+		if a.SonnebornBerger != b.SonnebornBerger {
+			return a.SonnebornBerger > b.SonnebornBerger
+		}
+		return a.Seed < b.Seed
+	})
+	for i, player := range swiss.Standings {
+		player.Rank = i + 1
+	}
+	return swiss
+}
+
+// getPlayer will return the player with the given id, adding it if it was not part of the seeds
+func (swiss *SwissTournament) getPlayer(playerID int) *SwissPlayer {
+	if _, ok := swiss.players[playerID]; !ok {
+		swiss.players[playerID] = &SwissPlayer{PlayerID: playerID, Seed: len(swiss.players) + 1, Opponents: make([]int, 0), results: make(map[int]float64)}
+	}
+	return swiss.players[playerID]
+}
+
+// IsRoundFinished will check if all matches in the current round are finished
+func (swiss *SwissTournament) IsRoundFinished() bool {
+	for _, match := range swiss.Matches {
+		if match.Round == swiss.CurrentRound && !match.IsBye() && !match.IsFinished {
+			return false
+		}
+	}
+	return true
+}
+
+// GetNextRound will return pairings for the next round. Players are paired within their score group where possible, while avoiding rematches.
+// If there is an odd number of players, a low ranked player without a bye is given a bye
+func (swiss *SwissTournament) GetNextRound() ([]*SwissMatch, error) {
+	if swiss.CurrentRound >= swiss.Rounds {
+		return nil, errors.New("all rounds have already been generated")
+	}
+	if !swiss.IsRoundFinished() {
+		return nil, errors.New("all matches in the current round must be finished before generating the next round")
+	}
+	round := swiss.CurrentRound + 1
+
+	matches := make([]*SwissMatch, 0)
+	bye, pairs := pairSwissPlayers(swiss.Standings)
+	if bye != nil {
+		matches = append(matches, &SwissMatch{Round: round, HomePlayerID: bye.PlayerID})
+	}
+	for _, pair := range pairs {
+		home, away := pair[0], pair[1]
+		if shouldThrowFirst(away, home) {
+			home, away = away, home
+		}
+		matches = append(matches, &SwissMatch{Round: round, HomePlayerID: home.PlayerID, AwayPlayerID: null.IntFrom(int64(away.PlayerID))})
+	}
+	return matches, nil
+}
+
+// SwissPairingBudget is the number of pairings tried when searching for a round without rematches, before falling back to greedy pairing
+const SwissPairingBudget = 10000
+
+// swissPairing is a bounded search for pairings of a round
+type swissPairing struct {
+	budget   int
+	maxFloat int
+	groups   map[int]int
+}
+
+// pairSwissPlayers will pair the given players, ordered by standing, and choose a bye if there is an odd number of players.
+// Players are paired Dutch-style within their score group, first only floating players to the adjacent score group and
+// then to any score group. If no pairing without rematches is found within the budget, players are paired greedily
+func pairSwissPlayers(players []*SwissPlayer) (*SwissPlayer, [][2]*SwissPlayer) {
+	groups := make(map[int]int)
+	for i, player := range players {
+		groups[player.PlayerID] = 0
+		if i > 0 {
+			groups[player.PlayerID] = groups[players[i-1].PlayerID]
+			if player.Points != players[i-1].Points {
+				groups[player.PlayerID]++
+			}
+		}
+	}
+	for _, maxFloat := range []int{1, len(players)} {
+		search := &swissPairing{budget: SwissPairingBudget, maxFloat: maxFloat, groups: groups}
+		if bye, pairs, ok := search.pairWithBye(players); ok {
+			return bye, pairs
+		}
+	}
+	return pairSwissPlayersGreedy(players)
+}
+
+// pairWithBye will try to give the bye to the lowest ranked player without a bye for which the remaining players can be paired
+func (search *swissPairing) pairWithBye(players []*SwissPlayer) (*SwissPlayer, [][2]*SwissPlayer, bool) {
+	if len(players)%2 == 0 {
+		pairs, ok := search.pair(players)
+		return nil, pairs, ok
+	}
+	for _, i := range getSwissByeCandidates(players) {
+		pairs, ok := search.pair(removeSwissPlayer(players, i))
+		if ok {
+			return players[i], pairs, true
+		}
+		if search.budget <= 0 {
+			break
+		}
+	}
+	return nil, nil, false
+}
+
+// pair will pair the highest ranked player with the preferred opponent not played before, backtracking
+// if the remaining players can not be paired, until the budget runs out
+func (search *swissPairing) pair(players []*SwissPlayer) ([][2]*SwissPlayer, bool) {
+	if len(players) == 0 {
+		return make([][2]*SwissPlayer, 0), true
+	}
+	player := players[0]
+	for _, i := range search.getCandidates(players) {
+		if search.budget <= 0 {
+			return nil, false
+		}
+		search.budget--
+		pairs, ok := search.pair(removeSwissPlayer(players[1:], i-1))
+		if ok {
+			return append([][2]*SwissPlayer{{player, players[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// getCandidates will return the indexes of the possible opponents of the first player, in order of preference.
+// Within the score group the top half is paired against the bottom half, followed by lower score groups by rank
+func (search *swissPairing) getCandidates(players []*SwissPlayer) []int {
+	group := search.groups[players[0].PlayerID]
+	size := 1
+	for size < len(players) && search.groups[players[size].PlayerID] == group {
+		size++
+	}
+	order := make([]int, 0)
+	for i := size / 2; i < size; i++ {
+		order = append(order, i)
+	}
+	for i := size/2 - 1; i > 0; i-- {
+		order = append(order, i)
+	}
+	for i := size; i < len(players); i++ {
+		order = append(order, i)
+	}
+
+	candidates := make([]int, 0)
+	for _, i := range order {
+		if i == 0 || containsInt(players[0].Opponents, players[i].PlayerID) {
+			continue
+		}
+		if search.groups[players[i].PlayerID]-group > search.maxFloat {
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+	return candidates
+}
+
+// pairSwissPlayersGreedy will give the bye to the lowest ranked player without a bye, and pair the highest ranked player
+// with the closest ranked opponent not played before, allowing rematches if there is no such opponent
+func pairSwissPlayersGreedy(players []*SwissPlayer) (*SwissPlayer, [][2]*SwissPlayer) {
+	var bye *SwissPlayer
+	if len(players)%2 == 1 {
+		idx := getSwissByeCandidates(players)[0]
+		bye = players[idx]
+		players = removeSwissPlayer(players, idx)
+	}
+	pairs := make([][2]*SwissPlayer, 0)
+	for len(players) > 0 {
+		idx := 1
+		for i := 1; i < len(players); i++ {
+			if !containsInt(players[0].Opponents, players[i].PlayerID) {
+				idx = i
+				break
+			}
+		}
+		pairs = append(pairs, [2]*SwissPlayer{players[0], players[idx]})
+		players = removeSwissPlayer(players[1:], idx-1)
+	}
+	return bye, pairs
+}
+
+// getSwissByeCandidates will return the indexes of the players who can be given a bye, lowest ranked first.
+// Players who already had a bye are only candidates if everyone has had a bye
+func getSwissByeCandidates(players []*SwissPlayer) []int {
+	candidates := make([]int, 0)
+	for i := len(players) - 1; i >= 0; i-- {
+		if !players[i].HadBye {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		for i := len(players) - 1; i >= 0; i-- {
+			candidates = append(candidates, i)
+		}
+	}
+	return candidates
+}
+
+// removeSwissPlayer will return a copy of the given players without the player at the given index
+func removeSwissPlayer(players []*SwissPlayer, idx int) []*SwissPlayer {
+	remaining := make([]*SwissPlayer, 0, len(players)-1)
+	remaining = append(remaining, players[:idx]...)
+	return append(remaining, players[idx+1:]...)
+}
+
+// shouldThrowFirst will check if the given player should throw first against the given opponent, balancing
+// the number of times each player has thrown first
+func shouldThrowFirst(player *SwissPlayer, opponent *SwissPlayer) bool {
+	balance := player.ThrowFirst - player.ThrowSecond
+	opponentBalance := opponent.ThrowFirst - opponent.ThrowSecond
+	if balance != opponentBalance {
+		return balance < opponentBalance
+	}
+	if player.lastThrewFirst != opponent.lastThrewFirst {
+		return !player.lastThrewFirst
+	}
+	return player.Rank < opponent.Rank
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSwissMatch(round int, home int, away int, winner int) *SwissMatch {
+	match := &SwissMatch{Round: round, HomePlayerID: home, AwayPlayerID: null.IntFrom(int64(away)), IsFinished: true}
+	if winner != 0 {
+		match.WinnerID = null.IntFrom(int64(winner))
+	}
+	return match
+}
+
+// TestSwissStandings will check that points and tiebreaks are calculated
+func TestSwissStandings(t *testing.T) {
+	swiss := NewSwissTournament(1, 3, []int{1, 2, 3, 4}, []*SwissMatch{
+		newSwissMatch(1, 1, 4, 1),
+		newSwissMatch(1, 2, 3, 0),
+	})
+	assert.Equal(t, 1, swiss.CurrentRound)
+	assert.Equal(t, 1, swiss.Standings[0].PlayerID)
+	assert.Equal(t, 1.0, swiss.Standings[0].Points)
+	assert.Equal(t, 0.5, swiss.Standings[1].Points)
+	assert.Equal(t, 0.5, swiss.Standings[1].Buchholz)
+	assert.Equal(t, 0.25, swiss.Standings[1].SonnebornBerger)
+	assert.Equal(t, 4, swiss.Standings[3].PlayerID)
+}
+
+// TestSwissNextRound will check that players are paired by score without rematches, and throw first is balanced
+func TestSwissNextRound(t *testing.T) {
+	swiss := NewSwissTournament(1, 3, []int{1, 2, 3, 4, 5}, []*SwissMatch{
+		newSwissMatch(1, 1, 2, 1),
+		newSwissMatch(1, 3, 4, 3),
+		{Round: 1, HomePlayerID: 5},
+	})
+	matches, err := swiss.GetNextRound()
+	assert.Nil(t, err)
+	assert.Len(t, matches, 3)
+	for _, match := range matches {
+		assert.Equal(t, 2, match.Round)
+		if match.IsBye() {
+			assert.NotEqual(t, 5, match.HomePlayerID, "player 5 already had a bye")
+			continue
+		}
+		assert.False(t, match.HomePlayerID == 1 && match.AwayPlayerID.Int64 == 2)
+		assert.False(t, match.HomePlayerID == 3 && match.AwayPlayerID.Int64 == 4)
+	}
+	// Player 1 and 3 threw first in round 1, so they should throw second when meeting someone who did not
+	for _, match := range matches {
+		if !match.IsBye() && (match.AwayPlayerID.Int64 == 2 || match.AwayPlayerID.Int64 == 4) {
+			assert.Fail(t, "player who threw second should now throw first")
+		}
+	}
+
+	swiss.Matches = append(swiss.Matches, &SwissMatch{Round: 2, HomePlayerID: 1, AwayPlayerID: null.IntFrom(3)})
+	swiss.CurrentRound = 2
+	_, err = swiss.GetNextRound()
+	assert.NotNil(t, err, "round is not finished")
+}
+
+// TestSwissNextRoundBudget will check that pairing falls back to greedy pairing when no round without rematches exists
+func TestSwissNextRoundBudget(t *testing.T) {
+	// Players 1 and 2 have played everyone, so one of them must have a rematch
+	seeds := []int{1, 2}
+	matches := []*SwissMatch{newSwissMatch(1, 1, 2, 1)}
+	for i := 3; i <= 41; i++ {
+		seeds = append(seeds, i)
+		matches = append(matches, newSwissMatch(i-1, 1, i, 1), newSwissMatch(i-1, 2, i, 2))
+	}
+	swiss := NewSwissTournament(1, 50, seeds, matches)
+	round, err := swiss.GetNextRound()
+	assert.Nil(t, err)
+	assert.Len(t, round, 21)
+
+	paired := make(map[int]bool)
+	byes := 0
+	for _, match := range round {
+		assert.False(t, paired[match.HomePlayerID])
+		paired[match.HomePlayerID] = true
+		if match.IsBye() {
+			byes++
+			continue
+		}
+		assert.False(t, paired[int(match.AwayPlayerID.Int64)])
+		paired[int(match.AwayPlayerID.Int64)] = true
+	}
+	assert.Len(t, paired, 41)
+	assert.Equal(t, 1, byes)
+}
+
+// TestSwissNextRoundBye will check that the bye is chosen so that the remaining players can be paired without rematches
+func TestSwissNextRoundBye(t *testing.T) {
+	swiss := NewSwissTournament(1, 3, []int{1, 2, 3}, []*SwissMatch{
+		newSwissMatch(1, 1, 3, 3),
+	})
+	matches, err := swiss.GetNextRound()
+	assert.Nil(t, err)
+	for _, match := range matches {
+		if match.IsBye() {
+			assert.NotEqual(t, 2, match.HomePlayerID, "players 1 and 3 have already played")
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Types of rubbers which can be played in a team fixture
+const (
+	RubberSingles = "singles"
+)
+
+// RubberPlayers contains the number of players each office fields for the given rubber type
+var RubberPlayers = map[string]int{
+	RubberSingles: 1,
+}
+
+// Points awarded to an office for the result of a fixture
+const (
+	TeamPointsWin  = 2
+	TeamPointsDraw = 1
+)
+
+// TeamLeague struct used for storing a league where offices play fixtures against each other
+type TeamLeague struct {
+	ID           int                 `json:"id"`
+	Name         string              `json:"name"`
+	ShortName    string              `json:"short_name"`
+	TournamentID int                 `json:"tournament_id"`
+	PresetID     int                 `json:"preset_id"`
+	Format       []string            `json:"format"`
+	Offices      []int               `json:"offices"`
+	Roster       []*TeamRosterPlayer `json:"roster"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+// TeamRosterPlayer struct used for storing a player who can represent an office in a team league
+type TeamRosterPlayer struct {
+	OfficeID  int  `json:"office_id"`
+	PlayerID  int  `json:"player_id"`
+	IsCaptain bool `json:"is_captain"`
+}
+
+// TeamFixture struct used for storing a fixture between two offices, consisting of a number of rubbers
+type TeamFixture struct {
+	ID                  int           `json:"id"`
+	LeagueID            int           `json:"league_id"`
+	Round               int           `json:"round"`
+	HomeOfficeID        int           `json:"home_office_id"`
+	AwayOfficeID        int           `json:"away_office_id"`
+	HomeLineupSubmitted bool          `json:"home_lineup_submitted"`
+	AwayLineupSubmitted bool          `json:"away_lineup_submitted"`
+	IsFinished          bool          `json:"is_finished"`
+	HomeScore           int           `json:"home_score"`
+	AwayScore           int           `json:"away_score"`
+	Rubbers             []*TeamRubber `json:"rubbers"`
+}
+
+// TeamRubber struct used for storing a single match within a team fixture
+type TeamRubber struct {
+	Rubber      int      `json:"rubber"`
+	Type        string   `json:"type"`
+	MatchID     null.Int `json:"match_id"`
+	HomePlayers []int    `json:"home_players"`
+	AwayPlayers []int    `json:"away_players"`
+	IsFinished  bool     `json:"is_finished"`
+	HomeLegs    int      `json:"home_legs"`
+	AwayLegs    int      `json:"away_legs"`
+}
+
+// TeamLineup struct used for storing the players a captain has picked for each rubber of a fixture
+type TeamLineup struct {
+	FixtureID   int       `json:"fixture_id"`
+	OfficeID    int       `json:"office_id"`
+	SubmittedBy int       `json:"submitted_by"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Rubbers     [][]int   `json:"rubbers"`
+}
+
+// TeamLeagueStanding struct used for storing the league table entry of an office
+type TeamLeagueStanding struct {
+	OfficeID         int `json:"office_id"`
+	Played           int `json:"played"`
+	Won              int `json:"won"`
+	Draw             int `json:"draw"`
+	Lost             int `json:"lost"`
+	RubbersFor       int `json:"rubbers_for"`
+	RubbersAgainst   int `json:"rubbers_against"`
+	RubberDifference int `json:"rubber_difference"`
+	Points           int `json:"points"`
+}
+
+// TeamAppearance struct used for storing the appearance record of a player in a team league
+type TeamAppearance struct {
+	PlayerID      int `json:"player_id"`
+	OfficeID      int `json:"office_id"`
+	Appearances   int `json:"appearances"`
+	SinglesPlayed int `json:"singles_played"`
+	SinglesWon    int `json:"singles_won"`
+	LegsFor       int `json:"legs_for"`
+	LegsAgainst   int `json:"legs_against"`
+}
+
+// Validate will check that the given league can be created
+func (league *TeamLeague) Validate() error {
+	if league.Name == "" {
+		return errors.New("name is required")
+	}
+	if league.PresetID == 0 {
+		return errors.New("preset_id is required")
+	}
+	if len(league.Format) == 0 {
+		return errors.New("format must contain at least one rubber")
+	}
+	for _, rubber := range league.Format {
+		if _, ok := RubberPlayers[rubber]; !ok {
+			return fmt.Errorf("unknown rubber type '%s'", rubber)
+		}
+	}
+	offices := make(map[int]bool)
+	captains := make(map[int]bool)
+	players := make(map[int]bool)
+	for _, player := range league.Roster {
+		if players[player.PlayerID] {
+			return fmt.Errorf("player %d is rostered more than once", player.PlayerID)
+		}
+		players[player.PlayerID] = true
+		offices[player.OfficeID] = true
+		if player.IsCaptain {
+			captains[player.OfficeID] = true
+		}
+	}
+	if len(offices) < 2 {
+		return errors.New("at least two offices must have rostered players")
+	}
+	for officeID := range offices {
+		if !captains[officeID] {
+			return fmt.Errorf("office %d does not have a captain", officeID)
+		}
+	}
+	return nil
+}
+
+// GetOffices will return all offices with rostered players, ordered by ID
+func (league *TeamLeague) GetOffices() []int {
+	seen := make(map[int]bool)
+	offices := make([]int, 0)
+	for _, player := range league.Roster {
+		if !seen[player.OfficeID] {
+			seen[player.OfficeID] = true
+			offices = append(offices, player.OfficeID)
+		}
+	}
+	sort.Ints(offices)
+	return offices
+}
+
+// GetRosterPlayer will return the roster entry for the given player in the given office, or nil if not rostered
+func (league *TeamLeague) GetRosterPlayer(officeID int, playerID int) *TeamRosterPlayer {
+	for _, player := range league.Roster {
+		if player.OfficeID == officeID && player.PlayerID == playerID {
+			return player
+		}
+	}
+	return nil
+}
+
+// ValidateLineup will check that the given lineup was submitted by the captain of one of the offices in the fixture,
+// and that it fields the correct number of rostered players for each rubber. A player can play at most one rubber of each type
+func (league *TeamLeague) ValidateLineup(fixture *TeamFixture, lineup *TeamLineup) error {
+	if lineup.OfficeID != fixture.HomeOfficeID && lineup.OfficeID != fixture.AwayOfficeID {
+		return fmt.Errorf("office %d is not playing fixture %d", lineup.OfficeID, fixture.ID)
+	}
+	captain := league.GetRosterPlayer(lineup.OfficeID, lineup.SubmittedBy)
+	if captain == nil || !captain.IsCaptain {
+		return fmt.Errorf("player %d is not captain of office %d", lineup.SubmittedBy, lineup.OfficeID)
+	}
+	if len(lineup.Rubbers) != len(league.Format) {
+		return fmt.Errorf("lineup must contain %d rubbers", len(league.Format))
+	}
+	played := make(map[string]map[int]bool)
+	for i, players := range lineup.Rubbers {
+		rubberType := league.Format[i]
+		if len(players) != RubberPlayers[rubberType] {
+			return fmt.Errorf("rubber %d (%s) requires %d player(s)", i+1, rubberType, RubberPlayers[rubberType])
+		}
+		if played[rubberType] == nil {
+			played[rubberType] = make(map[int]bool)
+		}
+		for _, playerID := range players {
+			if league.GetRosterPlayer(lineup.OfficeID, playerID) == nil {
+				return fmt.Errorf("player %d is not rostered for office %d", playerID, lineup.OfficeID)
+			}
+			if played[rubberType][playerID] {
+				return fmt.Errorf("player %d can only play one %s rubber", playerID, rubberType)
+			}
+			played[rubberType][playerID] = true
+		}
+	}
+	return nil
+}
+
+// GetMatchPlayers will return the home and away player of the match for the rubber
+func (rubber *TeamRubber) GetMatchPlayers() []int {
+	return []int{rubber.HomePlayers[0], rubber.AwayPlayers[0]}
+}
+
+// SetLegs will count the legs won by each side of the rubber from the given leg winners
+func (rubber *TeamRubber) SetLegs(winners []int) {
+	rubber.HomeLegs = 0
+	rubber.AwayLegs = 0
+	for _, winnerID := range winners {
+		if containsInt(rubber.HomePlayers, winnerID) {
+			rubber.HomeLegs++
+		} else if containsInt(rubber.AwayPlayers, winnerID) {
+			rubber.AwayLegs++
+		}
+	}
+}
+
+// SetResult will calculate the score of the fixture from the finished rubbers. A drawn rubber gives no score to either office
+func (fixture *TeamFixture) SetResult() {
+	fixture.HomeScore = 0
+	fixture.AwayScore = 0
+	fixture.IsFinished = len(fixture.Rubbers) > 0
+	for _, rubber := range fixture.Rubbers {
+		if !rubber.IsFinished {
+			fixture.IsFinished = false
+			continue
+		}
+		if rubber.HomeLegs > rubber.AwayLegs {
+			fixture.HomeScore++
+		} else if rubber.AwayLegs > rubber.HomeLegs {
+			fixture.AwayScore++
+		}
+	}
+}
+
+// NewTeamFixtures will create a double round robin between the given offices, where each office hosts every other office once
+func NewTeamFixtures(leagueID int, offices []int) []*TeamFixture {
+	teams := make([]int, len(offices))
+	copy(teams, offices)
+	if len(teams)%2 == 1 {
+		// Add a bye, offices drawn against it sit out the round
+		teams = append(teams, 0)
+	}
+	rounds := len(teams) - 1
+	fixtures := make([]*TeamFixture, 0)
+	for round := 0; round < rounds; round++ {
+		for i := 0; i < len(teams)/2; i++ {
+			home, away := teams[i], teams[len(teams)-1-i]
+			if (round+i)%2 == 1 {
+				home, away = away, home
+			}
+			if home == 0 || away == 0 {
+				continue
+			}
+			fixtures = append(fixtures, &TeamFixture{LeagueID: leagueID, Round: round + 1, HomeOfficeID: home, AwayOfficeID: away})
+		}
+		// Rotate all teams except the first one
+		teams = append([]int{teams[0], teams[len(teams)-1]}, teams[1:len(teams)-1]...)
+	}
+	// Second half of the season has the home and away offices swapped
+	firstHalf := fixtures
+	for _, fixture := range firstHalf {
+		fixtures = append(fixtures, &TeamFixture{LeagueID: leagueID, Round: fixture.Round + rounds, HomeOfficeID: fixture.AwayOfficeID,
+			AwayOfficeID: fixture.HomeOfficeID})
+	}
+	return fixtures
+}
+
+// GetTeamLeagueStandings will return the league table for the given offices, ordered by points, rubber difference and rubbers won
+func GetTeamLeagueStandings(offices []int, fixtures []*TeamFixture) []*TeamLeagueStanding {
+	standings := make(map[int]*TeamLeagueStanding)
+	for _, officeID := range offices {
+		standings[officeID] = &TeamLeagueStanding{OfficeID: officeID}
+	}
+	for _, fixture := range fixtures {
+		if !fixture.IsFinished {
+			continue
+		}
+		home := standings[fixture.HomeOfficeID]
+		away := standings[fixture.AwayOfficeID]
+		if home == nil || away == nil {
+			continue
+		}
+		home.Played++
+		away.Played++
+		home.RubbersFor += fixture.HomeScore
+		home.RubbersAgainst += fixture.AwayScore
+		away.RubbersFor += fixture.AwayScore
+		away.RubbersAgainst += fixture.HomeScore
+		if fixture.HomeScore > fixture.AwayScore {
+			home.Won++
+			away.Lost++
+		} else if fixture.AwayScore > fixture.HomeScore {
+			away.Won++
+			home.Lost++
+		} else {
+			home.Draw++
+			away.Draw++
+		}
+	}
+
+	table := make([]*TeamLeagueStanding, 0)
+	for _, officeID := range offices {
+		standing := standings[officeID]
+		standing.Points = standing.Won*TeamPointsWin + standing.Draw*TeamPointsDraw
+		standing.RubberDifference = standing.RubbersFor - standing.RubbersAgainst
+		table = append(table, standing)
+	}
+	sort.SliceStable(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.RubberDifference != b.RubberDifference {
+			return a.RubberDifference > b.RubberDifference
+		}
+		return a.RubbersFor > b.RubbersFor
+	})
+	return table
+}
+
+// GetTeamAppearances will return the appearance record of every rostered player, including players who have not played
+func GetTeamAppearances(league *TeamLeague, fixtures []*TeamFixture) []*TeamAppearance {
+	appearances := make(map[int]*TeamAppearance)
+	records := make([]*TeamAppearance, 0)
+	for _, player := range league.Roster {
+		appearance := &TeamAppearance{PlayerID: player.PlayerID, OfficeID: player.OfficeID}
+		appearances[player.PlayerID] = appearance
+		records = append(records, appearance)
+	}
+	for _, fixture := range fixtures {
+		appeared := make(map[int]bool)
+		for _, rubber := range fixture.Rubbers {
+			if !rubber.IsFinished {
+				continue
+			}
+			sides := []struct {
+				players     []int
+				legsFor     int
+				legsAgainst int
+			}{
+				{rubber.HomePlayers, rubber.HomeLegs, rubber.AwayLegs},
+				{rubber.AwayPlayers, rubber.AwayLegs, rubber.HomeLegs},
+			}
+			for _, side := range sides {
+				for _, playerID := range side.players {
+					appearance := appearances[playerID]
+					if appearance == nil {
+						continue
+					}
+					appeared[playerID] = true
+					won := side.legsFor > side.legsAgainst
+					appearance.SinglesPlayed++
+					if won {
+						appearance.SinglesWon++
+					}
+					appearance.LegsFor += side.legsFor
+					appearance.LegsAgainst += side.legsAgainst
+				}
+			}
+		}
+		for playerID := range appeared {
+			appearances[playerID].Appearances++
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Appearances > records[j].Appearances })
+	return records
+}
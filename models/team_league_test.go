@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewTeamFixtures will check that every office hosts every other office exactly once
+func TestNewTeamFixtures(t *testing.T) {
+	fixtures := NewTeamFixtures(1, []int{1, 2, 3})
+	assert.Len(t, fixtures, 6)
+
+	hosted := make(map[[2]int]int)
+	for _, fixture := range fixtures {
+		hosted[[2]int{fixture.HomeOfficeID, fixture.AwayOfficeID}]++
+	}
+	for _, home := range []int{1, 2, 3} {
+		for _, away := range []int{1, 2, 3} {
+			if home != away {
+				assert.Equal(t, 1, hosted[[2]int{home, away}])
+			}
+		}
+	}
+}
+
+// TestValidateTeamLeague will check that only known rubber types are accepted
+func TestValidateTeamLeague(t *testing.T) {
+	league := &TeamLeague{Name: "League", PresetID: 1, Format: []string{RubberSingles, RubberSingles}, Roster: []*TeamRosterPlayer{
+		{OfficeID: 1, PlayerID: 1, IsCaptain: true}, {OfficeID: 2, PlayerID: 2, IsCaptain: true},
+	}}
+	assert.NoError(t, league.Validate())
+
+	league.Format = []string{RubberSingles, "pairs"}
+	assert.Error(t, league.Validate())
+}
+
+// TestValidateLineup will check that lineups must be submitted by a captain and field rostered players
+func TestValidateLineup(t *testing.T) {
+	league := &TeamLeague{Format: []string{RubberSingles, RubberSingles}, Roster: []*TeamRosterPlayer{
+		{OfficeID: 1, PlayerID: 1, IsCaptain: true}, {OfficeID: 1, PlayerID: 2}, {OfficeID: 2, PlayerID: 3, IsCaptain: true},
+	}}
+	fixture := &TeamFixture{ID: 1, HomeOfficeID: 1, AwayOfficeID: 2}
+
+	assert.NoError(t, league.ValidateLineup(fixture, &TeamLineup{OfficeID: 1, SubmittedBy: 1, Rubbers: [][]int{{1}, {2}}}))
+	assert.Error(t, league.ValidateLineup(fixture, &TeamLineup{OfficeID: 1, SubmittedBy: 2, Rubbers: [][]int{{1}, {2}}}))
+	assert.Error(t, league.ValidateLineup(fixture, &TeamLineup{OfficeID: 1, SubmittedBy: 1, Rubbers: [][]int{{1}, {1}}}))
+	assert.Error(t, league.ValidateLineup(fixture, &TeamLineup{OfficeID: 1, SubmittedBy: 1, Rubbers: [][]int{{1}, {3}}}))
+	assert.Error(t, league.ValidateLineup(fixture, &TeamLineup{OfficeID: 1, SubmittedBy: 1, Rubbers: [][]int{{1}, {2, 1}}}))
+}
+
+// TestGetTeamLeagueStandings will check fixture scores, the league table and player appearances
+func TestGetTeamLeagueStandings(t *testing.T) {
+	league := &TeamLeague{Roster: []*TeamRosterPlayer{{OfficeID: 1, PlayerID: 1}, {OfficeID: 1, PlayerID: 2}, {OfficeID: 2, PlayerID: 3},
+		{OfficeID: 2, PlayerID: 4}}}
+	singles := &TeamRubber{Type: RubberSingles, IsFinished: true, HomePlayers: []int{1}, AwayPlayers: []int{3}}
+	singles.SetLegs([]int{1, 3, 1})
+	second := &TeamRubber{Type: RubberSingles, IsFinished: true, HomePlayers: []int{2}, AwayPlayers: []int{4}}
+	second.SetLegs([]int{4, 2, 4})
+	fixture := &TeamFixture{HomeOfficeID: 1, AwayOfficeID: 2, Rubbers: []*TeamRubber{singles, second}}
+	fixture.SetResult()
+	assert.True(t, fixture.IsFinished)
+	assert.Equal(t, 1, fixture.HomeScore)
+	assert.Equal(t, 1, fixture.AwayScore)
+
+	table := GetTeamLeagueStandings([]int{1, 2}, []*TeamFixture{fixture})
+	assert.Equal(t, TeamPointsDraw, table[0].Points)
+	assert.Equal(t, 1, table[1].Draw)
+
+	appearances := GetTeamAppearances(league, []*TeamFixture{fixture})
+	assert.Equal(t, 1, appearances[0].PlayerID)
+	assert.Equal(t, 1, appearances[0].Appearances)
+	assert.Equal(t, 1, appearances[0].SinglesWon)
+	assert.Equal(t, 1, appearances[0].SinglesPlayed)
+	assert.Equal(t, 2, appearances[0].LegsFor)
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// Tiebreak rules which can be used to order group standings
+const (
+	TiebreakPoints        = "points"
+	TiebreakHeadToHead    = "head_to_head"
+	TiebreakMiniLeague    = "mini_league"
+	TiebreakLegDifference = "leg_difference"
+	TiebreakLegsWon       = "legs_won"
+	TiebreakThreeDartAvg  = "three_dart_avg"
+	TiebreakElo           = "elo"
+	TiebreakPlayoffLeg    = "playoff_leg"
+	// TiebreakNone is used when players could not be separated by any of the configured rules
+	TiebreakNone = "none"
+)
+
+// TiebreakRules contains all valid tiebreak rules, with a description of each
+var TiebreakRules = map[string]string{
+	TiebreakPoints:        "points",
+	TiebreakHeadToHead:    "head-to-head result",
+	TiebreakMiniLeague:    "mini-league among tied players",
+	TiebreakLegDifference: "leg difference",
+	TiebreakLegsWon:       "legs won",
+	TiebreakThreeDartAvg:  "three-dart average",
+	TiebreakElo:           "Elo",
+	TiebreakPlayoffLeg:    "playoff leg",
+}
+
+// DefaultTiebreakRules is the tiebreak chain used when a tournament preset does not define one
+var DefaultTiebreakRules = []string{TiebreakPoints, TiebreakLegDifference}
+
+// TiebreakMatch struct used for storing the result of a group match, used for head-to-head tiebreakers
+type TiebreakMatch struct {
+	HomePlayerID int      `json:"home_player_id"`
+	AwayPlayerID int      `json:"away_player_id"`
+	IsFinished   bool     `json:"is_finished"`
+	WinnerID     null.Int `json:"winner_id"`
+}
+
+// ValidateTiebreakRules will check that the given rules start with points, since the rules are only used to separate players
+// on the same points, and that all rules are known and not repeated
+func ValidateTiebreakRules(rules []string) error {
+	if len(rules) > 0 && rules[0] != TiebreakPoints {
+		return fmt.Errorf("tiebreak rules must start with '%s'", TiebreakPoints)
+	}
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if _, ok := TiebreakRules[rule]; !ok {
+			return fmt.Errorf("unknown tiebreak rule '%s'", rule)
+		}
+		if seen[rule] {
+			return fmt.Errorf("tiebreak rule '%s' is used more than once", rule)
+		}
+		seen[rule] = true
+	}
+	return nil
+}
+
+// SortStandings will order the given group standings by applying each tiebreak rule in turn to players who are still tied.
+// Players still tied after all rules are ordered by relegation and manual order, keeping the given order otherwise.
+// Each player gets the tiebreaker which separated it from the player directly above, with an explanation
+func SortStandings(standings []*TournamentOverview, matches []*TiebreakMatch, elos map[int]int, rules []string) []*TournamentOverview {
+	if len(rules) == 0 {
+		rules = DefaultTiebreakRules
+	}
+	for _, standing := range standings {
+		standing.Tiebreaker = null.String{}
+		standing.TiebreakExplanation = null.String{}
+	}
+	return rankStandings(standings, matches, elos, rules)
+}
+
+func rankStandings(standings []*TournamentOverview, matches []*TiebreakMatch, elos map[int]int, rules []string) []*TournamentOverview {
+	ranked := make([]*TournamentOverview, len(standings))
+	copy(ranked, standings)
+	if len(ranked) <= 1 {
+		return ranked
+	}
+	if len(rules) == 0 {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			a, b := ranked[i], ranked[j]
+			if a.IsRelegated != b.IsRelegated {
+				return !a.IsRelegated
+			}
+			return a.ManualOrder.Valid && (!b.ManualOrder.Valid || a.ManualOrder.Int64 < b.ManualOrder.Int64)
+		})
+		for i := 1; i < len(ranked); i++ {
+			ranked[i].Tiebreaker = null.StringFrom(TiebreakNone)
+			ranked[i].TiebreakExplanation = null.StringFrom(fmt.Sprintf("Tied with player %d on all tiebreakers", ranked[i-1].PlayerID))
+		}
+		return ranked
+	}
+
+	rule := rules[0]
+	keys := getTiebreakKeys(rule, ranked, matches, elos)
+	sort.SliceStable(ranked, func(i, j int) bool { return keys[ranked[i].PlayerID] > keys[ranked[j].PlayerID] })
+
+	result := make([]*TournamentOverview, 0)
+	for start := 0; start < len(ranked); {
+		end := start + 1
+		for end < len(ranked) && keys[ranked[end].PlayerID] == keys[ranked[start].PlayerID] {
+			end++
+		}
+		bucket := rankStandings(ranked[start:end], matches, elos, rules[1:])
+		if len(result) > 0 {
+			above := result[len(result)-1]
+			below := bucket[0]
+			below.Tiebreaker = null.StringFrom(rule)
+			below.TiebreakExplanation = null.StringFrom(fmt.Sprintf("Behind player %d on %s (%s vs %s)", above.PlayerID, TiebreakRules[rule],
+				formatTiebreakKey(rule, keys[above.PlayerID]), formatTiebreakKey(rule, keys[below.PlayerID])))
+		}
+		result = append(result, bucket...)
+		start = end
+	}
+	return result
+}
+
+// getTiebreakKeys will return the value used to compare each of the given players for the given rule, where higher is better
+func getTiebreakKeys(rule string, standings []*TournamentOverview, matches []*TiebreakMatch, elos map[int]int) map[int]float64 {
+	keys := make(map[int]float64)
+	players := make([]int, 0)
+	for _, standing := range standings {
+		players = append(players, standing.PlayerID)
+	}
+	for _, standing := range standings {
+		switch rule {
+		case TiebreakPoints:
+			keys[standing.PlayerID] = float64(standing.Points)
+		case TiebreakLegDifference:
+			keys[standing.PlayerID] = float64(standing.LegsDifference)
+		case TiebreakLegsWon:
+			keys[standing.PlayerID] = float64(standing.LegsFor)
+		case TiebreakThreeDartAvg:
+			keys[standing.PlayerID] = float64(standing.ThreeDartAvg)
+		case TiebreakElo:
+			keys[standing.PlayerID] = float64(elos[standing.PlayerID])
+		case TiebreakPlayoffLeg:
+			// The result of a playoff leg is stored as the manual order, with the winner first
+			keys[standing.PlayerID] = math.Inf(-1)
+			if standing.ManualOrder.Valid {
+				keys[standing.PlayerID] = -float64(standing.ManualOrder.Int64)
+			}
+		case TiebreakHeadToHead:
+			// Head-to-head can only separate two players, otherwise a mini-league should be used
+			if len(players) == 2 {
+				keys[standing.PlayerID] = getMiniLeaguePoints(standing.PlayerID, players, matches)
+			}
+		case TiebreakMiniLeague:
+			keys[standing.PlayerID] = getMiniLeaguePoints(standing.PlayerID, players, matches)
+		}
+	}
+	return keys
+}
+
+// getMiniLeaguePoints will return the points the given player got in finished matches against the other given players
+func getMiniLeaguePoints(playerID int, players []int, matches []*TiebreakMatch) float64 {
+	points := 0.0
+	for _, match := range matches {
+		if !match.IsFinished || !containsInt(players, match.HomePlayerID) || !containsInt(players, match.AwayPlayerID) {
+			continue
+		}
+		if match.HomePlayerID != playerID && match.AwayPlayerID != playerID {
+			continue
+		}
+		if !match.WinnerID.Valid {
+			points++
+		} else if int(match.WinnerID.Int64) == playerID {
+			points += 2
+		}
+	}
+	return points
+}
+
+func formatTiebreakKey(rule string, key float64) string {
+	switch rule {
+	case TiebreakThreeDartAvg:
+		return fmt.Sprintf("%.2f", key)
+	case TiebreakPlayoffLeg:
+		if math.IsInf(key, -1) {
+			return "not played"
+		}
+		return fmt.Sprintf("position %g", -key)
+	}
+	return fmt.Sprintf("%g", key)
+}
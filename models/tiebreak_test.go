@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func getPlayerOrder(standings []*TournamentOverview) []int {
+	order := make([]int, 0)
+	for _, standing := range standings {
+		order = append(order, standing.PlayerID)
+	}
+	return order
+}
+
+// TestSortStandingsHeadToHead will check that head-to-head is applied before leg difference, and explained
+func TestSortStandingsHeadToHead(t *testing.T) {
+	standings := []*TournamentOverview{
+		{PlayerID: 1, Points: 4, LegsDifference: 3},
+		{PlayerID: 2, Points: 4, LegsDifference: 1},
+		{PlayerID: 3, Points: 2, LegsDifference: -4},
+	}
+	matches := []*TiebreakMatch{{HomePlayerID: 1, AwayPlayerID: 2, IsFinished: true, WinnerID: null.IntFrom(2)}}
+
+	sorted := SortStandings(standings, matches, nil, []string{TiebreakPoints, TiebreakHeadToHead, TiebreakLegDifference})
+	assert.Equal(t, []int{2, 1, 3}, getPlayerOrder(sorted))
+	assert.False(t, sorted[0].Tiebreaker.Valid)
+	assert.Equal(t, TiebreakHeadToHead, sorted[1].Tiebreaker.String)
+	assert.Equal(t, "Behind player 2 on head-to-head result (2 vs 0)", sorted[1].TiebreakExplanation.String)
+	assert.Equal(t, TiebreakPoints, sorted[2].Tiebreaker.String)
+
+	sorted = SortStandings(standings, matches, nil, nil)
+	assert.Equal(t, []int{1, 2, 3}, getPlayerOrder(sorted))
+	assert.Equal(t, TiebreakLegDifference, sorted[1].Tiebreaker.String)
+}
+
+// TestSortStandingsMiniLeague will check that a mini-league separates three tied players, falling through to Elo
+func TestSortStandingsMiniLeague(t *testing.T) {
+	standings := []*TournamentOverview{{PlayerID: 1, Points: 4}, {PlayerID: 2, Points: 4}, {PlayerID: 3, Points: 4}, {PlayerID: 4, Points: 4}}
+	matches := []*TiebreakMatch{
+		{HomePlayerID: 1, AwayPlayerID: 2, IsFinished: true, WinnerID: null.IntFrom(2)},
+		{HomePlayerID: 2, AwayPlayerID: 3, IsFinished: true, WinnerID: null.IntFrom(3)},
+		{HomePlayerID: 3, AwayPlayerID: 1, IsFinished: true, WinnerID: null.IntFrom(1)},
+		{HomePlayerID: 4, AwayPlayerID: 1, IsFinished: true, WinnerID: null.IntFrom(4)},
+		{HomePlayerID: 4, AwayPlayerID: 2, IsFinished: true, WinnerID: null.IntFrom(4)},
+		{HomePlayerID: 3, AwayPlayerID: 4, IsFinished: true, WinnerID: null.IntFrom(3)},
+	}
+	elos := map[int]int{1: 1500, 2: 1600, 3: 1400, 4: 1500}
+	sorted := SortStandings(standings, matches, elos, []string{TiebreakPoints, TiebreakHeadToHead, TiebreakMiniLeague, TiebreakElo})
+	assert.Equal(t, []int{4, 3, 2, 1}, getPlayerOrder(sorted))
+	assert.Equal(t, TiebreakElo, sorted[1].Tiebreaker.String)
+	assert.Equal(t, TiebreakMiniLeague, sorted[2].Tiebreaker.String)
+	assert.Equal(t, TiebreakElo, sorted[3].Tiebreaker.String)
+}
+
+// TestValidateTiebreakRules will check that unknown and repeated rules, and rules not starting with points, are rejected
+func TestValidateTiebreakRules(t *testing.T) {
+	assert.Nil(t, ValidateTiebreakRules([]string{TiebreakPoints, TiebreakMiniLeague, TiebreakPlayoffLeg}))
+	assert.NotNil(t, ValidateTiebreakRules([]string{TiebreakPoints, "coin_toss"}))
+	assert.NotNil(t, ValidateTiebreakRules([]string{TiebreakPoints, TiebreakPoints}))
+	assert.NotNil(t, ValidateTiebreakRules([]string{TiebreakLegDifference}))
+	assert.NotNil(t, ValidateTiebreakRules([]string{TiebreakHeadToHead, TiebreakPoints}))
+	assert.Nil(t, ValidateTiebreakRules(nil))
+}
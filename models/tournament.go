@@ -73,6 +73,7 @@ type Player2Tournament struct {
 	IsPromoted        bool `json:"is_promoted"`
 	IsRelegated       bool `json:"is_relegated"`
 	IsWinner          bool `json:"is_winner"`
+	IsWithdrawn       bool `json:"is_withdrawn"`
 }
 
 // TournamentStanding struct for stroring final tournament standings
@@ -117,5 +118,10 @@ type TournamentPreset struct {
 	PlayerIDWalkover        int              `json:"player_id_walkover"`
 	PlayerIDPlaceholderHome int              `json:"player_id_placeholder_home"`
 	PlayerIDPlaceholderAway int              `json:"player_id_placeholder_away"`
+	GroupCount              int              `json:"group_count"`
+	PlayoffsSize            int              `json:"playoffs_size"`
+	OutshotType             *OutshotType     `json:"outshot_type"`
+	TiebreakRules           []string         `json:"tiebreak_rules"`
+	WithdrawalRule          string           `json:"withdrawal_rule"`
 	Description             null.String      `json:"description"`
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxPresetGroups is the number of groups a tournament preset can define
+const MaxPresetGroups = 2
+
+// Validate will check that the given preset is complete and consistent. References to other entities are not checked
+func (preset *TournamentPreset) Validate() error {
+	if preset.Name == "" {
+		return errors.New("name is required")
+	}
+	if preset.StartingScore < 0 {
+		return errors.New("starting_score can not be negative")
+	}
+	if preset.MatchType == nil {
+		return errors.New("match_type_id is required")
+	}
+	if preset.MatchMode == nil || preset.MatchModeLast16 == nil || preset.MatchModeQuarterFinal == nil ||
+		preset.MatchModeSemiFinal == nil || preset.MatchModeGrandFinal == nil {
+		return errors.New("match modes for group stage and all playoff rounds are required")
+	}
+	if preset.PlayoffsTournamentGroup == nil || preset.Group1TournamentGroup == nil || preset.Group2TournamentGroup == nil {
+		return errors.New("playoffs and group tournament groups are required")
+	}
+	if preset.PlayerIDWalkover == 0 || preset.PlayerIDPlaceholderHome == 0 || preset.PlayerIDPlaceholderAway == 0 {
+		return errors.New("walkover and placeholder players are required")
+	}
+	if preset.PlayerIDWalkover == preset.PlayerIDPlaceholderHome || preset.PlayerIDWalkover == preset.PlayerIDPlaceholderAway ||
+		preset.PlayerIDPlaceholderHome == preset.PlayerIDPlaceholderAway {
+		return errors.New("walkover and placeholder players must be different players")
+	}
+	if preset.GroupCount < 1 || preset.GroupCount > MaxPresetGroups {
+		return fmt.Errorf("group_count must be between 1 and %d", MaxPresetGroups)
+	}
+	if preset.PlayoffsSize != 0 && (preset.PlayoffsSize < 2 || preset.PlayoffsSize&(preset.PlayoffsSize-1) != 0) {
+		return errors.New("playoffs_size must be a power of two")
+	}
+	if preset.OutshotType == nil {
+		return errors.New("outshot_type is required")
+	}
+	switch preset.WithdrawalRule {
+	case WithdrawalWalkover, WithdrawalAnnul, WithdrawalAuto, "":
+	default:
+		return fmt.Errorf("unknown withdrawal rule '%s'", preset.WithdrawalRule)
+	}
+	return ValidateTiebreakRules(preset.TiebreakRules)
+}
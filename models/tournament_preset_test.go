@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestPreset() *TournamentPreset {
+	mode := &MatchMode{ID: 1}
+	group := &TournamentGroup{ID: 1}
+	return &TournamentPreset{Name: "Preset", StartingScore: 501, MatchType: &MatchType{ID: X01}, MatchMode: mode, MatchModeLast16: mode,
+		MatchModeQuarterFinal: mode, MatchModeSemiFinal: mode, MatchModeGrandFinal: mode, PlayoffsTournamentGroup: group,
+		Group1TournamentGroup: group, Group2TournamentGroup: group, PlayerIDWalkover: 1, PlayerIDPlaceholderHome: 2,
+		PlayerIDPlaceholderAway: 3, GroupCount: 2, PlayoffsSize: 8, OutshotType: &OutshotType{ID: OUTSHOTDOUBLE},
+		TiebreakRules: DefaultTiebreakRules, WithdrawalRule: WithdrawalAuto}
+}
+
+// TestTournamentPresetValidate will check that invalid presets are rejected
+func TestTournamentPresetValidate(t *testing.T) {
+	assert.NoError(t, getTestPreset().Validate())
+
+	preset := getTestPreset()
+	preset.PlayerIDPlaceholderAway = preset.PlayerIDPlaceholderHome
+	assert.Error(t, preset.Validate())
+
+	preset = getTestPreset()
+	preset.PlayoffsSize = 6
+	assert.Error(t, preset.Validate())
+
+	preset = getTestPreset()
+	preset.GroupCount = 3
+	assert.Error(t, preset.Validate())
+
+	preset = getTestPreset()
+	preset.MatchModeSemiFinal = nil
+	assert.Error(t, preset.Validate())
+
+	preset = getTestPreset()
+	preset.TiebreakRules = []string{TiebreakPoints, "coin_toss"}
+	assert.Error(t, preset.Validate())
+}
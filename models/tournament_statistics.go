@@ -54,5 +54,8 @@ type TournamentOverview struct {
 	IsPromoted            bool             `json:"is_promoted"`
 	IsRelegated           bool             `json:"is_relegated"`
 	IsWinner              bool             `json:"is_winner"`
+	IsWithdrawn           bool             `json:"is_withdrawn"`
 	ManualOrder           null.Int         `json:"manual_order"`
+	Tiebreaker            null.String      `json:"tiebreaker"`
+	TiebreakExplanation   null.String      `json:"tiebreak_explanation"`
 }